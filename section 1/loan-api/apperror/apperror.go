@@ -0,0 +1,113 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"loan-api/model"
+)
+
+// Error is an API error with a stable machine-readable Code. Values declared
+// in this package are templates: the With* methods return modified copies.
+type Error struct {
+	Code   string
+	Status int
+	Title  string
+	Detail string
+	Fields []model.FieldError
+	// Duplicates references the applications a rejected submission matched.
+	Duplicates []model.DuplicateMatch
+	Err        error
+}
+
+func New(status int, code, title string) *Error {
+	return &Error{Code: code, Status: status, Title: title}
+}
+
+func (e *Error) Error() string {
+	msg := e.Code + ": " + e.Title
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors by code so that copies made by the With* methods still
+// match the template they were derived from.
+func (e *Error) Is(target error) bool {
+	var t *Error
+	return errors.As(target, &t) && t.Code == e.Code
+}
+
+func (e *Error) WithDetail(format string, args ...interface{}) *Error {
+	c := *e
+	c.Detail = fmt.Sprintf(format, args...)
+	return &c
+}
+
+func (e *Error) WithFields(fields []model.FieldError) *Error {
+	c := *e
+	c.Fields = fields
+	return &c
+}
+
+func (e *Error) WithDuplicates(matches []model.DuplicateMatch) *Error {
+	c := *e
+	c.Duplicates = matches
+	return &c
+}
+
+// Wrap records the underlying cause. The cause is logged but never sent to clients.
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+var (
+	ErrMalformedRequest = New(http.StatusBadRequest, "malformed_request", "Invalid input")
+	ErrValidation       = New(http.StatusBadRequest, "validation_failed", "Invalid input")
+	ErrInvalidID        = New(http.StatusBadRequest, "invalid_id", "Invalid ID")
+	ErrInvalidParameter = New(http.StatusBadRequest, "invalid_parameter", "Invalid parameter")
+	ErrInvalidStatus    = New(http.StatusBadRequest, "invalid_status", "Invalid status")
+	ErrInvalidEventType = New(http.StatusBadRequest, "invalid_event_type", "Invalid event type")
+	ErrDocumentMissing  = New(http.StatusBadRequest, "document_missing", "Document upload failed")
+
+	ErrUnauthorized = New(http.StatusUnauthorized, "unauthorized", "Unauthorized")
+	ErrForbidden    = New(http.StatusForbidden, "forbidden", "Forbidden")
+
+	ErrRouteNotFound       = New(http.StatusNotFound, "route_not_found", "Route not found")
+	ErrApplicationNotFound = New(http.StatusNotFound, "application_not_found", "Loan application not found")
+	ErrWebhookNotFound     = New(http.StatusNotFound, "webhook_not_found", "Webhook not found")
+	ErrDeliveryNotFound    = New(http.StatusNotFound, "delivery_not_found", "Webhook delivery not found")
+	ErrImportNotFound      = New(http.StatusNotFound, "import_not_found", "Import job not found")
+	ErrQueueEmpty          = New(http.StatusNotFound, "queue_empty", "No applications waiting to be claimed")
+	ErrNoteNotFound        = New(http.StatusNotFound, "note_not_found", "Note not found")
+
+	ErrMethodNotAllowed = New(http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+
+	ErrDeliveryInProgress    = New(http.StatusConflict, "delivery_in_progress", "Webhook delivery is still in progress")
+	ErrCapacityReached       = New(http.StatusConflict, "capacity_reached", "Officer capacity reached")
+	ErrInvalidTransition     = New(http.StatusConflict, "invalid_status_transition", "Invalid status transition")
+	ErrConditionsOutstanding = New(http.StatusConflict, "conditions_outstanding", "Conditions outstanding")
+	ErrChecklistIncomplete   = New(http.StatusConflict, "checklist_incomplete", "Required documents missing")
+	ErrDuplicateApplication  = New(http.StatusConflict, "duplicate_application", "Duplicate application")
+	ErrApplicationDecided    = New(http.StatusConflict, "application_decided", "Application already decided")
+
+	ErrImportTooLarge   = New(http.StatusRequestEntityTooLarge, "import_too_large", "Import too large")
+	ErrDocumentTooLarge = New(http.StatusRequestEntityTooLarge, "document_too_large", "Document too large")
+	ErrUnsupportedMedia = New(http.StatusUnsupportedMediaType, "unsupported_media_type", "Unsupported media type")
+
+	ErrStorageFailed = New(http.StatusInternalServerError, "storage_failed", "Unable to save file")
+	ErrInternal      = New(http.StatusInternalServerError, "internal_error", "Internal Server Error")
+
+	ErrImportsBusy = New(http.StatusServiceUnavailable, "imports_busy", "Too many imports running")
+)
@@ -0,0 +1,59 @@
+package apperror
+
+import (
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Domain names the API in the ErrorInfo detail of gRPC errors.
+const Domain = "loan-api"
+
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.FailedPrecondition,
+	http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+	http.StatusUnsupportedMediaType:  codes.InvalidArgument,
+	http.StatusInternalServerError:   codes.Internal,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+}
+
+// GRPCStatus lets gRPC methods return an *Error as is. The code follows
+// Status, Code is sent as the reason of an ErrorInfo detail and field errors
+// as a BadRequest detail, so gRPC clients can branch on the same values as
+// REST clients.
+func (e *Error) GRPCStatus() *status.Status {
+	code, found := grpcCodes[e.Status]
+	if !found {
+		code = codes.Unknown
+	}
+	message := e.Title
+	if e.Detail != "" {
+		message += ": " + e.Detail
+	}
+	st := status.New(code, message)
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: e.Code, Domain: Domain}}
+	if len(e.Fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range e.Fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Message,
+				Reason:      field.Code,
+			})
+		}
+		details = append(details, badRequest)
+	}
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+	return detailed
+}
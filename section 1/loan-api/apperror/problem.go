@@ -0,0 +1,63 @@
+package apperror
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/model"
+)
+
+const (
+	ContentType     = "application/problem+json"
+	RequestIDHeader = "X-Request-ID"
+
+	// TypeBase prefixes the problem code to form the RFC 7807 "type" URI.
+	TypeBase = "/problems/"
+)
+
+// Problem is an RFC 7807 problem details document. Code, RequestID, Errors
+// and Duplicates are extension members.
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Code       string                 `json:"code"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	Errors     []model.FieldError     `json:"errors,omitempty"`
+	Duplicates []model.DuplicateMatch `json:"duplicates,omitempty"`
+}
+
+func (e *Error) Problem(instance, requestID string) Problem {
+	return Problem{
+		Type:       TypeBase + e.Code,
+		Title:      e.Title,
+		Status:     e.Status,
+		Detail:     e.Detail,
+		Instance:   instance,
+		Code:       e.Code,
+		RequestID:  requestID,
+		Errors:     e.Fields,
+		Duplicates: e.Duplicates,
+	}
+}
+
+// Respond writes err as application/problem+json and aborts the request.
+// Errors that are not an *Error are reported as ErrInternal.
+func Respond(c *gin.Context, err error) {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		appErr = ErrInternal.Wrap(err)
+	}
+
+	requestID := c.Writer.Header().Get(RequestIDHeader)
+	if appErr.Status >= 500 {
+		log.Printf("Request Error - RequestID: %s, Path: %s, Method: %s, Error: %v",
+			requestID, c.Request.URL.Path, c.Request.Method, appErr)
+	}
+
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(appErr.Status, appErr.Problem(c.Request.URL.RequestURI(), requestID))
+}
@@ -0,0 +1,285 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"loan-api/apperror"
+	"loan-api/model"
+	"loan-api/serializer"
+)
+
+// Client calls the v2 REST API of loan-api with a bearer token.
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+func New(baseURL, token string) *Client {
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Error is a problem document returned by the API.
+type Error struct {
+	Problem apperror.Problem
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("%d %s", e.Problem.Status, e.Problem.Title)
+	if e.Problem.Detail != "" {
+		message += ": " + e.Problem.Detail
+	}
+	for _, field := range e.Problem.Errors {
+		message += fmt.Sprintf("\n  %s: %s", field.Field, field.Message)
+	}
+	return message
+}
+
+// ApplicationPage is one page of GET /loan-applications.
+type ApplicationPage struct {
+	Data       []serializer.LoanApplication `json:"data"`
+	Pagination serializer.Pagination        `json:"pagination"`
+}
+
+// ListOptions filters and pages the applications. Zero values are left to
+// the server's defaults.
+type ListOptions struct {
+	Status string
+	SLA    string
+	Page   int
+	Limit  int
+}
+
+func (c *Client) ListApplications(ctx context.Context, opts ListOptions) (ApplicationPage, error) {
+	query := url.Values{}
+	setQuery(query, "status", opts.Status)
+	setQuery(query, "sla", opts.SLA)
+	if opts.Page > 0 {
+		query.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var page ApplicationPage
+	err := c.doJSON(ctx, http.MethodGet, "/loan-applications?"+query.Encode(), nil, &page)
+	return page, err
+}
+
+func (c *Client) SubmitApplication(ctx context.Context, app model.LoanApplication) (serializer.LoanApplication, error) {
+	var created serializer.LoanApplication
+	err := c.doJSON(ctx, http.MethodPost, "/loan-applications", app, &created)
+	return created, err
+}
+
+func (c *Client) GetApplication(ctx context.Context, id int) (serializer.LoanApplication, error) {
+	var app serializer.LoanApplication
+	err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("/loan-applications/%d", id), nil, &app)
+	return app, err
+}
+
+// GetApplicationAsOf returns the application as it was at the given time.
+func (c *Client) GetApplicationAsOf(ctx context.Context, id int, at time.Time) (serializer.LoanApplication, error) {
+	var app serializer.LoanApplication
+	query := url.Values{"as_of": {at.Format(time.RFC3339Nano)}}
+	err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("/loan-applications/%d?%s", id, query.Encode()), nil, &app)
+	return app, err
+}
+
+// History returns the lifecycle events of an application, oldest first.
+func (c *Client) History(ctx context.Context, id int) ([]model.HistoryEntry, error) {
+	var envelope struct {
+		Data []model.HistoryEntry `json:"data"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("/loan-applications/%d/history", id), nil, &envelope)
+	return envelope.Data, err
+}
+
+func (c *Client) UpdateStatus(ctx context.Context, id int, status, reason string) (serializer.LoanApplication, error) {
+	body := map[string]string{"status": status}
+	if reason != "" {
+		body["reason"] = reason
+	}
+	var app serializer.LoanApplication
+	err := c.doJSON(ctx, http.MethodPut, fmt.Sprintf("/loan-applications/%d/status", id), body, &app)
+	return app, err
+}
+
+// UploadDocument sends the contents of r as a document named filename.
+// documentType may be empty.
+func (c *Client) UploadDocument(ctx context.Context, id int, filename string, r io.Reader, documentType string) (serializer.LoanApplication, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("document", filename)
+	if err != nil {
+		return serializer.LoanApplication{}, err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return serializer.LoanApplication{}, err
+	}
+	if documentType != "" {
+		if err := form.WriteField("document_type", documentType); err != nil {
+			return serializer.LoanApplication{}, err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return serializer.LoanApplication{}, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, fmt.Sprintf("/loan-applications/%d/documents", id), &body)
+	if err != nil {
+		return serializer.LoanApplication{}, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	var app serializer.LoanApplication
+	err = c.do(req, &app)
+	return app, err
+}
+
+// Export streams the applications matching query, such as format=ndjson or
+// status=approved, to w.
+func (c *Client) Export(ctx context.Context, query url.Values, w io.Writer) error {
+	req, err := c.newRequest(ctx, http.MethodGet, "/loan-applications/export?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// WebhookRequest creates or replaces a webhook subscription. A nil Active
+// keeps the server's default of an active subscription.
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+func (c *Client) ListWebhooks(ctx context.Context) ([]model.WebhookSubscription, error) {
+	var envelope struct {
+		Data []model.WebhookSubscription `json:"data"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, "/webhooks", nil, &envelope)
+	return envelope.Data, err
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id int) (model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("/webhooks/%d", id), nil, &sub)
+	return sub, err
+}
+
+// CreateWebhook returns the new subscription with its secret, which the API
+// never shows again.
+func (c *Client) CreateWebhook(ctx context.Context, req WebhookRequest) (model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	err := c.doJSON(ctx, http.MethodPost, "/webhooks", req, &sub)
+	return sub, err
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, id int, req WebhookRequest) (model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	err := c.doJSON(ctx, http.MethodPut, fmt.Sprintf("/webhooks/%d", id), req, &sub)
+	return sub, err
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id int) error {
+	return c.doJSON(ctx, http.MethodDelete, fmt.Sprintf("/webhooks/%d", id), nil, nil)
+}
+
+func (c *Client) ListDeliveries(ctx context.Context, id int) ([]model.WebhookDelivery, error) {
+	var envelope struct {
+		Data []model.WebhookDelivery `json:"data"`
+	}
+	err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("/webhooks/%d/deliveries", id), nil, &envelope)
+	return envelope.Data, err
+}
+
+func (c *Client) Redeliver(ctx context.Context, id, deliveryID int) (model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	err := c.doJSON(ctx, http.MethodPost, fmt.Sprintf("/webhooks/%d/deliveries/%d/redeliver", id, deliveryID), nil, &delivery)
+	return delivery, err
+}
+
+// doJSON sends body, if any, as JSON and decodes the response into out, if
+// not nil.
+func (c *Client) doJSON(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := c.newRequest(ctx, method, path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.do(req, out)
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+"/"+serializer.V2+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request, out any) error {
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// decodeError reads a problem document, falling back to the status line for
+// responses that are not one, such as those of a proxy.
+func decodeError(resp *http.Response) error {
+	var problem apperror.Problem
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil || problem.Status == 0 {
+		problem = apperror.Problem{Status: resp.StatusCode, Title: http.StatusText(resp.StatusCode)}
+	}
+	return &Error{Problem: problem}
+}
+
+func setQuery(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const DefaultURL = "http://localhost:8080"
+
+// Config holds the connection settings shared by loanctl and loadgen. The
+// file is JSON, such as {"url": "https://loans.internal", "token": "..."};
+// keep it readable by its owner only.
+type Config struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// LoadConfig reads path, or the default config file if path is empty, and
+// applies LOANCTL_URL and LOANCTL_TOKEN. Only an explicitly named file has
+// to exist.
+func LoadConfig(path string) (Config, error) {
+	cfg := Config{URL: DefaultURL}
+
+	explicit := path != ""
+	if !explicit {
+		path = os.Getenv("LOANCTL_CONFIG")
+		explicit = path != ""
+	}
+	if !explicit {
+		if dir, err := os.UserConfigDir(); err == nil {
+			path = filepath.Join(dir, "loanctl", "config.json")
+		}
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && !explicit:
+		case err != nil:
+			return cfg, fmt.Errorf("reading config: %w", err)
+		default:
+			if err := json.Unmarshal(data, &cfg); err != nil {
+				return cfg, fmt.Errorf("parsing config %s: %w", path, err)
+			}
+		}
+	}
+
+	if url := os.Getenv("LOANCTL_URL"); url != "" {
+		cfg.URL = url
+	}
+	if token := os.Getenv("LOANCTL_TOKEN"); token != "" {
+		cfg.Token = token
+	}
+	if cfg.Token == "" {
+		return cfg, errors.New("no token: set LOANCTL_TOKEN or \"token\" in the config file")
+	}
+	return cfg, nil
+}
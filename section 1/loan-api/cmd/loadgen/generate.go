@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"loan-api/model"
+	"loan-api/synthetic"
+)
+
+// submission holds the fields an applicant submits, which is what the import
+// endpoint reads from each NDJSON line.
+type submission struct {
+	ApplicantName string  `json:"applicant_name"`
+	ApplicantSSN  string  `json:"applicant_ssn"`
+	Country       string  `json:"country"`
+	TaxID         string  `json:"tax_id,omitempty"`
+	LoanAmount    float64 `json:"loan_amount"`
+	LoanPurpose   string  `json:"loan_purpose"`
+	AnnualIncome  float64 `json:"annual_income"`
+	CreditScore   int     `json:"credit_score"`
+}
+
+func newSubmission(app model.LoanApplication) submission {
+	return submission{
+		ApplicantName: app.ApplicantName,
+		ApplicantSSN:  app.ApplicantSSN,
+		Country:       app.Country,
+		TaxID:         app.TaxID,
+		LoanAmount:    app.LoanAmount,
+		LoanPurpose:   app.LoanPurpose,
+		AnnualIncome:  app.AnnualIncome,
+		CreditScore:   app.CreditScore,
+	}
+}
+
+func runGenerate(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("generate", stderr)
+	n := fs.Int("n", 100, "number of applications")
+	out := fs.String("out", "synthetic", "directory to write applications.ndjson and the documents to")
+	seed := fs.Uint64("seed", defaultSeed(), "random seed; the same seed generates the same data")
+	idShare := fs.Float64("id-share", 0.3, "share of Indonesian applicants")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	documentsDir := filepath.Join(*out, "documents")
+	if err := os.MkdirAll(documentsDir, 0o755); err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(*out, "applications.ndjson"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	encoder := json.NewEncoder(w)
+
+	gen := synthetic.NewGenerator(*seed)
+	gen.IDShare = *idShare
+	for i := 1; i <= *n; i++ {
+		app := gen.Application()
+		if err := encoder.Encode(newSubmission(app)); err != nil {
+			return err
+		}
+		// Documents are numbered after the line of their application.
+		doc := gen.Document(app)
+		path := filepath.Join(documentsDir, fmt.Sprintf("%06d_%s", i, doc.Filename))
+		if err := os.WriteFile(path, doc.Content, 0o644); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Wrote %d applications to %s and their documents to %s (seed %d)\n",
+		*n, file.Name(), documentsDir, *seed)
+	return nil
+}
@@ -0,0 +1,81 @@
+// Command loadgen generates synthetic loan applications and replays them
+// against loan-api, the underwriting service and the document processor.
+//
+//	loadgen run [flags]       send applications at fixed rates and report latencies
+//	loadgen generate [flags]  write applications and their documents to a directory
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "run":
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		err = runLoad(ctx, args[1:], stdout, stderr)
+	case "generate":
+		err = runGenerate(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "loadgen: unknown command %q\n", args[0])
+		printUsage(stderr)
+		return 2
+	}
+	switch {
+	case errors.Is(err, flag.ErrHelp), errors.Is(err, errUsage):
+		return 2
+	case err != nil:
+		fmt.Fprintf(stderr, "loadgen: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// errUsage reports invalid flags, which the flag set has already printed.
+var errUsage = errors.New("invalid arguments")
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: loadgen run [flags]       replay synthetic applications and report latencies")
+	fmt.Fprintln(w, "       loadgen generate [flags]  write synthetic applications and documents to a directory")
+	fmt.Fprintln(w, "\nRun \"loadgen <command> -h\" for the flags of a command.")
+}
+
+// newFlagSet returns a flag set whose parse errors are printed to stderr.
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("loadgen "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected argument %q\n", fs.Arg(0))
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+func defaultSeed() uint64 {
+	return uint64(time.Now().UnixNano())
+}
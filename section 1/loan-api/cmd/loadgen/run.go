@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"loan-api/client"
+	"loan-api/loadtest"
+	"loan-api/model"
+	"loan-api/synthetic"
+)
+
+// The architecture target is 5,000 applications a day.
+const defaultRate = "5000/d"
+
+func runLoad(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("run", stderr)
+	apiURL := fs.String("api-url", "http://localhost:8080", "loan-api base URL, empty to skip it")
+	configPath := fs.String("config", "", "loanctl config file holding the loan-api token (default $LOANCTL_CONFIG or <user config dir>/loanctl/config.json)")
+	token := fs.String("token", "", "loan-api bearer token the applications are submitted with, overriding the config file and LOANCTL_TOKEN")
+	underwritingURL := fs.String("underwriting-url", "", "underwriting service base URL (its server.port), empty to skip it")
+	docsURL := fs.String("docs-url", "", "document processor base URL, empty to skip it")
+	var apiRate, underwritingRate, docsRate loadtest.Rate
+	for _, r := range []*loadtest.Rate{&apiRate, &underwritingRate, &docsRate} {
+		r.Set(defaultRate)
+	}
+	fs.Var(&apiRate, "api-rate", "loan-api submissions per period, such as 5000/d or 2/s")
+	fs.Var(&underwritingRate, "underwriting-rate", "underwriting evaluations per period")
+	fs.Var(&docsRate, "docs-rate", "document processor uploads per period")
+	uploadShare := fs.Float64("upload-share", 0.5, "share of loan-api submissions followed by a document upload")
+	duration := fs.Duration("duration", time.Minute, "how long to send requests")
+	concurrency := fs.Int("concurrency", 50, "requests in flight per target; requests due beyond it are dropped")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout of each request")
+	seed := fs.Uint64("seed", defaultSeed(), "random seed; the same seed sends the same applications")
+	output := fs.String("o", "table", "report format: table or json")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(stderr, "invalid -o %q, want table or json\n", *output)
+		return errUsage
+	}
+	if *apiURL != "" && *token == "" {
+		cfg, err := client.LoadConfig(*configPath)
+		if err != nil {
+			return err
+		}
+		*token = cfg.Token
+	}
+
+	gen := &generator{Generator: synthetic.NewGenerator(*seed)}
+	httpClient := &http.Client{Timeout: *timeout}
+	var scenarios []loadtest.Scenario
+	if *apiURL != "" {
+		api := client.New(*apiURL, *token)
+		api.HTTP = httpClient
+		scenarios = append(scenarios, loadtest.Scenario{Name: "loan-api", Rate: apiRate, Do: func(ctx context.Context, i int) error {
+			app, doc, upload := gen.next(*uploadShare)
+			created, err := api.SubmitApplication(ctx, app)
+			if err != nil || !upload {
+				return statusError(err)
+			}
+			_, err = api.UploadDocument(ctx, created.ID, doc.Filename, bytes.NewReader(doc.Content), doc.Type)
+			return statusError(err)
+		}})
+	}
+	if *underwritingURL != "" {
+		url := strings.TrimSuffix(*underwritingURL, "/") + "/evaluations"
+		scenarios = append(scenarios, loadtest.Scenario{Name: "underwriting", Rate: underwritingRate, Do: func(ctx context.Context, i int) error {
+			app, _, _ := gen.next(0)
+			body, _ := json.Marshal(map[string]any{"id": strconv.Itoa(i + 1), "applicant_ssn": app.ApplicantSSN, "amount": app.LoanAmount})
+			return post(ctx, httpClient, url, "application/json", bytes.NewReader(body))
+		}})
+	}
+	if *docsURL != "" {
+		base := strings.TrimSuffix(*docsURL, "/")
+		scenarios = append(scenarios, loadtest.Scenario{Name: "doc-processor", Rate: docsRate, Do: func(ctx context.Context, i int) error {
+			_, doc, _ := gen.next(1)
+			body, contentType, err := documentForm(doc)
+			if err != nil {
+				return err
+			}
+			return post(ctx, httpClient, fmt.Sprintf("%s/loan-applications/%d/documents", base, i+1), contentType, body)
+		}})
+	}
+	if len(scenarios) == 0 {
+		fmt.Fprintln(stderr, "no targets: set -api-url, -underwriting-url or -docs-url")
+		return errUsage
+	}
+
+	runner := loadtest.Runner{Duration: *duration, MaxInFlight: *concurrency, Timeout: *timeout}
+	report := runner.Run(ctx, scenarios)
+	if *output == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	printReport(stdout, report, *seed)
+	return nil
+}
+
+// generator makes the synthetic generator safe to share between the requests
+// in flight.
+type generator struct {
+	mu sync.Mutex
+	*synthetic.Generator
+}
+
+// next returns an application with its document, and whether the document
+// should be uploaded, which is the case for uploadShare of them.
+func (g *generator) next(uploadShare float64) (model.LoanApplication, synthetic.Document, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	app := g.Application()
+	doc := g.Document(app)
+	return app, doc, g.Chance(uploadShare)
+}
+
+// statusError turns API problems into load test status errors so that they
+// are grouped by status in the report.
+func statusError(err error) error {
+	var apiErr *client.Error
+	if errors.As(err, &apiErr) {
+		return &loadtest.StatusError{Code: apiErr.Problem.Status}
+	}
+	return err
+}
+
+func post(ctx context.Context, httpClient *http.Client, url, contentType string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		return &loadtest.StatusError{Code: resp.StatusCode}
+	}
+	return nil
+}
+
+// documentForm encodes doc as the multipart form the document processor
+// reads: the file and its document_type.
+func documentForm(doc synthetic.Document) (io.Reader, string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", doc.Filename)
+	if err != nil {
+		return nil, "", err
+	}
+	part.Write(doc.Content)
+	form.WriteField("document_type", doc.Type)
+	if err := form.Close(); err != nil {
+		return nil, "", err
+	}
+	return &body, form.FormDataContentType(), nil
+}
+
+func printReport(w io.Writer, report loadtest.Report, seed uint64) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tRATE\tSENT\tOK\tFAILED\tDROPPED\tERROR %\tREQ/S\tP50 ms\tP90 ms\tP95 ms\tP99 ms\tMAX ms\t")
+	for _, r := range report.Scenarios {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%.2f\t%.2f\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t\n",
+			r.Name, r.Rate, r.Sent, r.Succeeded, r.Failed, r.Dropped, 100*r.ErrorRate, r.Throughput,
+			r.P50, r.P90, r.P95, r.P99, r.Max)
+	}
+	tw.Flush()
+
+	for _, r := range report.Scenarios {
+		classes := make([]string, 0, len(r.Errors))
+		for class := range r.Errors {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		for _, class := range classes {
+			fmt.Fprintf(w, "%s: %d failed with %s\n", r.Name, r.Errors[class], class)
+		}
+	}
+	fmt.Fprintf(w, "\nran for %s with seed %d\n", report.Duration.Round(time.Millisecond), seed)
+}
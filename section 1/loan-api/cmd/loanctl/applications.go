@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"loan-api/client"
+	"loan-api/model"
+	"loan-api/serializer"
+)
+
+// allPageSize is the page size used to fetch every application with -all.
+const allPageSize = 100
+
+func runList(ctx context.Context, app *cli, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	var opts client.ListOptions
+	fs.StringVar(&opts.Status, "status", "", "only applications in this status")
+	fs.StringVar(&opts.SLA, "sla", "", "only applications in this SLA state (on_track, at_risk, breached)")
+	fs.IntVar(&opts.Page, "page", 1, "page to show")
+	fs.IntVar(&opts.Limit, "limit", 20, "applications per page")
+	all := fs.Bool("all", false, "fetch every page")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	var apps []serializer.LoanApplication
+	var total int
+	if *all {
+		opts.Page, opts.Limit = 1, allPageSize
+	}
+	for {
+		page, err := app.client.ListApplications(ctx, opts)
+		if err != nil {
+			return err
+		}
+		apps = append(apps, page.Data...)
+		total = page.Pagination.Total
+		if !*all || len(page.Data) < opts.Limit || len(apps) >= total {
+			break
+		}
+		opts.Page++
+	}
+
+	return app.print(apps, func(w io.Writer) {
+		row(w, "ID", "APPLICANT", "AMOUNT", "PURPOSE", "STATUS", "CREDIT", "SUBMITTED", "ASSIGNED")
+		for _, a := range apps {
+			row(w, strconv.Itoa(a.ID), a.ApplicantName, money(a.LoanAmount), a.LoanPurpose, a.Status,
+				strconv.Itoa(a.CreditScore), timestamp(a.SubmittedAt), orDash(a.AssignedTo))
+		}
+		if !*all {
+			fmt.Fprintf(w, "\npage %d, %d of %d applications\n", opts.Page, len(apps), total)
+		}
+	})
+}
+
+func runGet(ctx context.Context, app *cli, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	asOf := fs.String("as-of", "", "show the application as it was at this RFC 3339 time")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	var loan serializer.LoanApplication
+	var at time.Time
+	if *asOf != "" {
+		if at, err = time.Parse(time.RFC3339Nano, *asOf); err != nil {
+			return fmt.Errorf("%w: -as-of must be an RFC 3339 time such as 2026-03-01T12:00:00Z", errUsage)
+		}
+		loan, err = app.client.GetApplicationAsOf(ctx, id, at)
+	} else {
+		loan, err = app.client.GetApplication(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+	history, err := app.client.History(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !at.IsZero() {
+		// Only the events that led to the state shown.
+		history = slices.DeleteFunc(history, func(entry model.HistoryEntry) bool { return entry.OccurredAt.After(at) })
+	}
+
+	result := struct {
+		Application serializer.LoanApplication `json:"application"`
+		History     []model.HistoryEntry       `json:"history"`
+	}{loan, history}
+	return app.print(result, func(w io.Writer) {
+		row(w, "ID:", strconv.Itoa(loan.ID))
+		row(w, "Applicant:", loan.ApplicantName)
+		row(w, "SSN:", loan.ApplicantSSN)
+		row(w, "Country:", loan.Country)
+		row(w, "Amount:", money(loan.LoanAmount))
+		row(w, "Income:", money(loan.AnnualIncome))
+		row(w, "Purpose:", loan.LoanPurpose)
+		row(w, "Credit score:", strconv.Itoa(loan.CreditScore))
+		row(w, "Status:", loan.Status)
+		row(w, "Submitted:", timestamp(loan.SubmittedAt)+" by "+orDash(loan.SubmittedBy))
+		row(w, "Assigned to:", orDash(loan.AssignedTo))
+		row(w, "Documents:", orDash(strings.Join(loan.DocumentsUploaded, ", ")))
+		if loan.SLA != nil {
+			row(w, "SLA:", loan.SLA.State+", due "+timestamp(loan.SLA.DueAt))
+		}
+		if loan.LegalHold != nil {
+			row(w, "Legal hold:", loan.LegalHold.Reason)
+		}
+
+		fmt.Fprintln(w, "\nHistory:")
+		row(w, "TIME", "EVENT", "DETAILS")
+		for _, entry := range history {
+			row(w, timestamp(entry.OccurredAt), entry.Type, details(entry.Data))
+		}
+	})
+}
+
+// details formats event data as sorted key=value pairs.
+func details(data map[string]string) string {
+	pairs := make([]string, 0, len(data))
+	for key, value := range data {
+		pairs = append(pairs, key+"="+strconv.Quote(value))
+	}
+	sort.Strings(pairs)
+	return orDash(strings.Join(pairs, " "))
+}
+
+func runSetStatus(ctx context.Context, app *cli, args []string) error {
+	fs := flag.NewFlagSet("set-status", flag.ContinueOnError)
+	reason := fs.String("reason", "", "why the status changes, kept in the application's history")
+	positional, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	loan, err := app.client.UpdateStatus(ctx, id, positional[1], *reason)
+	if err != nil {
+		return err
+	}
+	return app.print(loan, func(w io.Writer) {
+		fmt.Fprintf(w, "Application %d is now %s\n", loan.ID, loan.Status)
+	})
+}
+
+func runUpload(ctx context.Context, app *cli, args []string) error {
+	fs := flag.NewFlagSet("upload", flag.ContinueOnError)
+	documentType := fs.String("type", "", "document type, such as pay_stub, for the application's checklist")
+	positional, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(positional[1])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	loan, err := app.client.UploadDocument(ctx, id, filepath.Base(file.Name()), file, *documentType)
+	if err != nil {
+		return err
+	}
+	return app.print(loan, func(w io.Writer) {
+		fmt.Fprintf(w, "Uploaded %s to application %d, which now has %d documents\n",
+			filepath.Base(file.Name()), loan.ID, len(loan.DocumentsUploaded))
+	})
+}
+
+func runExport(ctx context.Context, app *cli, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "csv", "csv, ndjson or parquet")
+	status := fs.String("status", "", "only applications in this status")
+	purpose := fs.String("purpose", "", "only applications for this loan purpose")
+	from := fs.String("from", "", "only applications submitted on or after this date (YYYY-MM-DD)")
+	to := fs.String("to", "", "only applications submitted on or before this date (YYYY-MM-DD)")
+	out := fs.String("out", "", "file to write, instead of standard output")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	query := url.Values{"format": {*format}}
+	for key, value := range map[string]string{"status": *status, "loan_purpose": *purpose, "from": *from, "to": *to} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	if *out == "" {
+		return app.client.Export(ctx, query, app.stdout)
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := app.client.Export(ctx, query, file); err != nil {
+		file.Close()
+		os.Remove(*out)
+		return err
+	}
+	return file.Close()
+}
+
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("%w: %q is not an ID", errUsage, s)
+	}
+	return id, nil
+}
@@ -0,0 +1,132 @@
+// Command loanctl administers a loan-api server from the command line.
+//
+//	loanctl [-config file] [-url url] [-o table|json] <command> [flags] [args]
+//
+// Credentials are read from the config file, then from LOANCTL_URL and
+// LOANCTL_TOKEN, each overriding the previous one.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+
+	"loan-api/client"
+)
+
+// command runs a subcommand with the arguments that follow its name.
+type command struct {
+	usage string
+	run   func(ctx context.Context, app *cli, args []string) error
+}
+
+var commands = map[string]command{
+	"list":       {"list [-status s] [-sla s] [-page n] [-limit n] [-all]", runList},
+	"get":        {"get [-as-of time] <id>", runGet},
+	"set-status": {"set-status [-reason text] <id> <status>", runSetStatus},
+	"upload":     {"upload [-type document_type] <id> <file>", runUpload},
+	"export":     {"export [-format csv|ndjson|parquet] [-status s] [-purpose p] [-from date] [-to date] [-out file]", runExport},
+	"webhooks":   {"webhooks list|get|create|update|delete|deliveries|redeliver ...", runWebhooks},
+}
+
+// errUsage reports invalid arguments; the command's usage is printed with it.
+var errUsage = errors.New("invalid arguments")
+
+// cli holds what every command needs.
+type cli struct {
+	client *client.Client
+	output string
+	stdout io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("loanctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "", "config file (default $LOANCTL_CONFIG or <user config dir>/loanctl/config.json)")
+	baseURL := fs.String("url", "", "loan-api base URL, overriding the config file and LOANCTL_URL")
+	output := fs.String("o", outputTable, "output format: table or json")
+	fs.Usage = func() { printUsage(fs, stderr) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 || (*output != outputTable && *output != outputJSON) {
+		fs.Usage()
+		return 2
+	}
+	name := fs.Arg(0)
+	cmd, found := commands[name]
+	if !found {
+		fmt.Fprintf(stderr, "loanctl: unknown command %q\n", name)
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := client.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "loanctl: %v\n", err)
+		return 1
+	}
+	if *baseURL != "" {
+		cfg.URL = *baseURL
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	app := &cli{client: client.New(cfg.URL, cfg.Token), output: *output, stdout: stdout}
+	err = cmd.run(ctx, app, fs.Args()[1:])
+	switch {
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "loanctl: %v\nusage: loanctl %s\n", err, cmd.usage)
+		return 2
+	case err != nil:
+		fmt.Fprintf(stderr, "loanctl: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func printUsage(fs *flag.FlagSet, w io.Writer) {
+	fmt.Fprintln(w, "usage: loanctl [flags] <command> [flags] [args]")
+	fmt.Fprintln(w, "\nflags:")
+	fs.PrintDefaults()
+	fmt.Fprintln(w, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", commands[name].usage)
+	}
+}
+
+// parseArgs parses fs from args, allowing flags after the positional
+// arguments, and returns the positional arguments. It fails unless there are
+// exactly want of them.
+func parseArgs(fs *flag.FlagSet, args []string, want int) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != want {
+		return nil, errUsage
+	}
+	return positional, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"loan-api/serializer"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// print writes v as indented JSON with -o json, or calls table otherwise.
+func (app *cli) print(v any, table func(w io.Writer)) error {
+	if app.output == outputJSON {
+		encoder := json.NewEncoder(app.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+	w := tabwriter.NewWriter(app.stdout, 0, 4, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+func row(w io.Writer, columns ...string) {
+	io.WriteString(w, strings.Join(columns, "\t")+"\n")
+}
+
+func money(m serializer.Money) string {
+	return m.Amount + " " + m.Currency
+}
+
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"loan-api/client"
+	"loan-api/model"
+)
+
+func runWebhooks(ctx context.Context, app *cli, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	action, args := args[0], args[1:]
+	fs := flag.NewFlagSet("webhooks "+action, flag.ContinueOnError)
+
+	switch action {
+	case "list":
+		if _, err := parseArgs(fs, args, 0); err != nil {
+			return err
+		}
+		subs, err := app.client.ListWebhooks(ctx)
+		if err != nil {
+			return err
+		}
+		return app.print(subs, func(w io.Writer) {
+			row(w, "ID", "URL", "EVENTS", "ACTIVE", "UPDATED")
+			for _, sub := range subs {
+				printSubscription(w, sub)
+			}
+		})
+
+	case "get", "delete", "deliveries":
+		positional, err := parseArgs(fs, args, 1)
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional[0])
+		if err != nil {
+			return err
+		}
+		switch action {
+		case "get":
+			sub, err := app.client.GetWebhook(ctx, id)
+			if err != nil {
+				return err
+			}
+			return app.printSubscription(sub)
+		case "delete":
+			if err := app.client.DeleteWebhook(ctx, id); err != nil {
+				return err
+			}
+			return app.print(map[string]int{"deleted": id}, func(w io.Writer) {
+				fmt.Fprintf(w, "Deleted webhook %d\n", id)
+			})
+		}
+		deliveries, err := app.client.ListDeliveries(ctx, id)
+		if err != nil {
+			return err
+		}
+		return app.print(deliveries, func(w io.Writer) {
+			row(w, "ID", "EVENT", "APPLICATION", "STATUS", "ATTEMPTS", "CODE", "LAST ERROR")
+			for _, d := range deliveries {
+				row(w, strconv.Itoa(d.ID), d.EventType, strconv.Itoa(d.ApplicationID), d.Status,
+					strconv.Itoa(d.Attempts), strconv.Itoa(d.ResponseCode), orDash(d.LastError))
+			}
+		})
+
+	case "create", "update":
+		url := fs.String("url", "", "URL the events are posted to")
+		events := fs.String("events", "", "comma separated event types, empty for every event")
+		secret := fs.String("secret", "", "signing secret, generated by the server when empty on create")
+		inactive := fs.Bool("inactive", false, "pause deliveries")
+		want := 0
+		if action == "update" {
+			want = 1
+		}
+		positional, err := parseArgs(fs, args, want)
+		if err != nil {
+			return err
+		}
+		if *url == "" {
+			return fmt.Errorf("%w: -url is required", errUsage)
+		}
+		active := !*inactive
+		req := client.WebhookRequest{URL: *url, Secret: *secret, Active: &active}
+		if *events != "" {
+			req.Events = strings.Split(*events, ",")
+		}
+
+		var sub model.WebhookSubscription
+		if action == "create" {
+			sub, err = app.client.CreateWebhook(ctx, req)
+		} else {
+			var id int
+			if id, err = parseID(positional[0]); err != nil {
+				return err
+			}
+			sub, err = app.client.UpdateWebhook(ctx, id, req)
+		}
+		if err != nil {
+			return err
+		}
+		return app.printSubscription(sub)
+
+	case "redeliver":
+		positional, err := parseArgs(fs, args, 2)
+		if err != nil {
+			return err
+		}
+		id, err := parseID(positional[0])
+		if err != nil {
+			return err
+		}
+		deliveryID, err := parseID(positional[1])
+		if err != nil {
+			return err
+		}
+		delivery, err := app.client.Redeliver(ctx, id, deliveryID)
+		if err != nil {
+			return err
+		}
+		return app.print(delivery, func(w io.Writer) {
+			fmt.Fprintf(w, "Queued delivery %d of event %d for redelivery\n", delivery.ID, delivery.EventID)
+		})
+	}
+	return fmt.Errorf("%w: unknown webhooks command %q", errUsage, action)
+}
+
+// printSubscription shows one subscription, including its secret when the
+// server returned it on creation.
+func (app *cli) printSubscription(sub model.WebhookSubscription) error {
+	return app.print(sub, func(w io.Writer) {
+		row(w, "ID", "URL", "EVENTS", "ACTIVE", "UPDATED")
+		printSubscription(w, sub)
+		if sub.Secret != "" {
+			fmt.Fprintf(w, "\nSecret: %s (shown only once)\n", sub.Secret)
+		}
+	})
+}
+
+func printSubscription(w io.Writer, sub model.WebhookSubscription) {
+	events := "all"
+	if len(sub.Events) > 0 {
+		events = strings.Join(sub.Events, ",")
+	}
+	row(w, strconv.Itoa(sub.ID), sub.URL, events, strconv.FormatBool(sub.Active), timestamp(sub.UpdatedAt))
+}
@@ -0,0 +1,443 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"loan-api/model"
+	"loan-api/sla"
+	"loan-api/tracing"
+)
+
+// Config is the complete runtime configuration of the loan API. Values are
+// resolved in order of increasing precedence: defaults, the JSON file given by
+// -config (or LOAN_API_CONFIG), LOAN_API_* environment variables and flags.
+type Config struct {
+	Server     ServerConfig                    `json:"server"`
+	Metrics    MetricsConfig                   `json:"metrics"`
+	GRPC       GRPCConfig                      `json:"grpc"`
+	Tracing    TracingConfig                   `json:"tracing"`
+	Uploads    UploadsConfig                   `json:"uploads"`
+	Events     EventsConfig                    `json:"events"`
+	Webhooks   WebhooksConfig                  `json:"webhooks"`
+	Imports    ImportsConfig                   `json:"imports"`
+	SLA        SLAConfig                       `json:"sla"`
+	Assignment AssignmentConfig                `json:"assignment"`
+	Retention  RetentionConfig                 `json:"retention"`
+	Duplicates DuplicatesConfig                `json:"duplicates"`
+	Checklists map[string][]string             `json:"checklists"`
+	Tenants    map[string]model.TenantSettings `json:"tenants"`
+	Auth       AuthConfig                      `json:"auth"`
+}
+
+type ServerConfig struct {
+	Port            int      `json:"port"`
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+}
+
+// MetricsConfig sets the port of the separate, unauthenticated listener that
+// serves /metrics. Port 0 disables it.
+type MetricsConfig struct {
+	Port int `json:"port"`
+}
+
+// GRPCConfig sets the port of the gRPC listener serving loan.v1.LoanService.
+// Port 0 disables it.
+type GRPCConfig struct {
+	Port int `json:"port"`
+}
+
+// TracingConfig selects where spans go: "otlp" posts them to Endpoint (or the
+// OTEL_EXPORTER_OTLP_* location), "stdout" prints them for local runs and
+// "none" drops them.
+type TracingConfig struct {
+	Exporter string `json:"exporter"`
+	Endpoint string `json:"endpoint"`
+}
+
+type UploadsConfig struct {
+	Dir string `json:"dir"`
+	// MaxBytes caps the size of one uploaded document, over REST and gRPC.
+	MaxBytes int `json:"max_bytes"`
+}
+
+type EventsConfig struct {
+	LogSize int `json:"log_size"`
+}
+
+// WebhooksConfig tunes deliveries. DeliveryLogSize caps the deliveries kept
+// per tenant, and AllowPrivateTargets lets subscriptions point at loopback and
+// private addresses, for local development only.
+type WebhooksConfig struct {
+	MaxAttempts         int      `json:"max_attempts"`
+	BaseBackoff         Duration `json:"base_backoff"`
+	RequestTimeout      Duration `json:"request_timeout"`
+	DeliveryLogSize     int      `json:"delivery_log_size"`
+	AllowPrivateTargets bool     `json:"allow_private_targets"`
+}
+
+type ImportsConfig struct {
+	MaxRows        int `json:"max_rows"`
+	MaxBytes       int `json:"max_bytes"`
+	AsyncThreshold int `json:"async_threshold"`
+	MaxRunningJobs int `json:"max_running_jobs"`
+}
+
+// Limits returns the bounds the import handler applies.
+func (c ImportsConfig) Limits() model.ImportLimits {
+	return model.ImportLimits{MaxRows: c.MaxRows, MaxBytes: int64(c.MaxBytes), AsyncThreshold: c.AsyncThreshold, MaxRunningJobs: c.MaxRunningJobs}
+}
+
+// SLAConfig sets how many business days an application may spend in each
+// status. Holidays are YYYY-MM-DD dates in Timezone.
+type SLAConfig struct {
+	SweepInterval Duration       `json:"sweep_interval"`
+	Warning       Duration       `json:"warning"`
+	Deadlines     map[string]int `json:"deadlines"`
+	Holidays      []string       `json:"holidays"`
+	Timezone      string         `json:"timezone"`
+}
+
+// AssignmentConfig lists the officers that can be assigned applications.
+type AssignmentConfig struct {
+	Officers   []model.Officer `json:"officers"`
+	AutoAssign bool            `json:"auto_assign"`
+}
+
+// RetentionConfig sets how long applications are kept once they reach a
+// status. Without rules nothing is ever removed automatically.
+type RetentionConfig struct {
+	SweepInterval Duration              `json:"sweep_interval"`
+	Rules         []model.RetentionRule `json:"rules"`
+}
+
+// DuplicatesConfig sets how submissions are checked against the applications
+// submitted within Window: policy off, reject, link or flag. Amounts within
+// AmountTolerance (a fraction of the larger amount) count as near-identical.
+// SSNSecret keys the HMAC under which SSNs are indexed; without it a random
+// secret is used, which is enough while the index lives in memory.
+type DuplicatesConfig struct {
+	Policy          string   `json:"policy"`
+	Window          Duration `json:"window"`
+	AmountTolerance float64  `json:"amount_tolerance"`
+	SSNSecret       string   `json:"ssn_secret"`
+}
+
+// Rule returns the duplicate check the handlers apply.
+func (c DuplicatesConfig) Rule() model.DuplicateRule {
+	return model.DuplicateRule{Policy: c.Policy, Window: c.Window.Duration, AmountTolerance: c.AmountTolerance}
+}
+
+type AuthConfig struct {
+	Tokens []TokenConfig `json:"tokens"`
+}
+
+type TokenConfig struct {
+	Token       string   `json:"token"`
+	Subject     string   `json:"subject"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	Tenant      string   `json:"tenant"`
+}
+
+// Duration is a time.Duration written as a string such as "5s" in JSON.
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"5s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:            8080,
+			ShutdownTimeout: Duration{15 * time.Second},
+		},
+		Metrics: MetricsConfig{Port: 9090},
+		GRPC:    GRPCConfig{Port: 9091},
+		Tracing: TracingConfig{Exporter: tracing.ExporterNone},
+		Uploads: UploadsConfig{Dir: "./uploads", MaxBytes: 10 << 20},
+		Events:  EventsConfig{LogSize: 1000},
+		Webhooks: WebhooksConfig{
+			MaxAttempts:     5,
+			BaseBackoff:     Duration{time.Second},
+			RequestTimeout:  Duration{10 * time.Second},
+			DeliveryLogSize: 1000,
+		},
+		Imports: ImportsConfig{MaxRows: 10000, MaxBytes: 32 << 20, AsyncThreshold: 100, MaxRunningJobs: 4},
+		SLA: SLAConfig{
+			SweepInterval: Duration{time.Minute},
+			Warning:       Duration{4 * time.Hour},
+			Deadlines:     map[string]int{model.StatusPending: 2, model.StatusUnderReview: 5},
+			Timezone:      "UTC",
+		},
+		Retention: RetentionConfig{SweepInterval: Duration{time.Hour}},
+		Duplicates: DuplicatesConfig{
+			Policy:          model.DuplicatePolicyOff,
+			Window:          Duration{30 * 24 * time.Hour},
+			AmountTolerance: 0.05,
+		},
+		Checklists: map[string][]string{
+			model.PurposeHomeRenovation:    {"contractor_quote", "pay_stub"},
+			model.PurposeBusinessExpansion: {"tax_return", "bank_statement"},
+		},
+	}
+}
+
+// Load builds the configuration from the command line arguments (without the
+// program name) and the process environment, and validates the result.
+func Load(args []string) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("loan-api", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("LOAN_API_CONFIG"), "path to a JSON configuration file")
+	port := fs.Int("port", 0, "HTTP listen port")
+	metricsPort := fs.Int("metrics-port", 0, "metrics listen port, 0 to disable")
+	grpcPort := fs.Int("grpc-port", 0, "gRPC listen port, 0 to disable")
+	traceExporter := fs.String("trace-exporter", "", "trace exporter: "+strings.Join(tracing.Exporters, ", "))
+	uploadDir := fs.String("upload-dir", "", "directory for uploaded documents")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 0, "time allowed to drain requests on shutdown")
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if *path != "" {
+		if err := cfg.loadFile(*path); err != nil {
+			return cfg, err
+		}
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return cfg, err
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "metrics-port":
+			cfg.Metrics.Port = *metricsPort
+		case "grpc-port":
+			cfg.GRPC.Port = *grpcPort
+		case "trace-exporter":
+			cfg.Tracing.Exporter = *traceExporter
+		case "upload-dir":
+			cfg.Uploads.Dir = *uploadDir
+		case "shutdown-timeout":
+			cfg.Server.ShutdownTimeout = Duration{*shutdownTimeout}
+		}
+	})
+
+	return cfg, cfg.Validate()
+}
+
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	ints := map[string]*int{
+		"LOAN_API_PORT":                    &c.Server.Port,
+		"LOAN_API_METRICS_PORT":            &c.Metrics.Port,
+		"LOAN_API_GRPC_PORT":               &c.GRPC.Port,
+		"LOAN_API_UPLOAD_MAX_BYTES":        &c.Uploads.MaxBytes,
+		"LOAN_API_EVENT_LOG_SIZE":          &c.Events.LogSize,
+		"LOAN_API_WEBHOOK_MAX_ATTEMPTS":    &c.Webhooks.MaxAttempts,
+		"LOAN_API_WEBHOOK_LOG_SIZE":        &c.Webhooks.DeliveryLogSize,
+		"LOAN_API_IMPORT_MAX_ROWS":         &c.Imports.MaxRows,
+		"LOAN_API_IMPORT_MAX_BYTES":        &c.Imports.MaxBytes,
+		"LOAN_API_IMPORT_ASYNC_THRESHOLD":  &c.Imports.AsyncThreshold,
+		"LOAN_API_IMPORT_MAX_RUNNING_JOBS": &c.Imports.MaxRunningJobs,
+	}
+	for name, target := range ints {
+		if v, ok := lookup(name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			*target = n
+		}
+	}
+	if v, ok := lookup("LOAN_API_SHUTDOWN_TIMEOUT"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("LOAN_API_SHUTDOWN_TIMEOUT: %w", err)
+		}
+		c.Server.ShutdownTimeout = Duration{d}
+	}
+	if v, ok := lookup("LOAN_API_UPLOAD_DIR"); ok {
+		c.Uploads.Dir = v
+	}
+	if v, ok := lookup("LOAN_API_TRACE_EXPORTER"); ok {
+		c.Tracing.Exporter = v
+	}
+	if v, ok := lookup("LOAN_API_TRACE_ENDPOINT"); ok {
+		c.Tracing.Endpoint = v
+	}
+	if v, ok := lookup("LOAN_API_DUPLICATE_POLICY"); ok {
+		c.Duplicates.Policy = v
+	}
+	if v, ok := lookup("LOAN_API_SSN_SECRET"); ok {
+		c.Duplicates.SSNSecret = v
+	}
+	return nil
+}
+
+func (c Config) Validate() error {
+	var errs []error
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port must be between 1 and 65535, got %d", c.Server.Port))
+	}
+	if c.Metrics.Port < 0 || c.Metrics.Port > 65535 || c.Metrics.Port == c.Server.Port {
+		errs = append(errs, fmt.Errorf("metrics.port must be 0 or a port between 1 and 65535 other than server.port, got %d", c.Metrics.Port))
+	}
+	if c.GRPC.Port < 0 || c.GRPC.Port > 65535 || (c.GRPC.Port != 0 && (c.GRPC.Port == c.Server.Port || c.GRPC.Port == c.Metrics.Port)) {
+		errs = append(errs, fmt.Errorf("grpc.port must be 0 or a port between 1 and 65535 other than server.port and metrics.port, got %d", c.GRPC.Port))
+	}
+	if !slices.Contains(tracing.Exporters, c.Tracing.Exporter) {
+		errs = append(errs, fmt.Errorf("tracing.exporter must be one of %s, got %q", strings.Join(tracing.Exporters, ", "), c.Tracing.Exporter))
+	}
+	if c.Server.ShutdownTimeout.Duration <= 0 {
+		errs = append(errs, errors.New("server.shutdown_timeout must be positive"))
+	}
+	if c.Uploads.Dir == "" {
+		errs = append(errs, errors.New("uploads.dir is required"))
+	}
+	if c.Uploads.MaxBytes < 1 {
+		errs = append(errs, errors.New("uploads.max_bytes must be at least 1"))
+	}
+	if c.Events.LogSize < 1 {
+		errs = append(errs, errors.New("events.log_size must be at least 1"))
+	}
+	if c.Webhooks.MaxAttempts < 1 || c.Webhooks.DeliveryLogSize < 1 {
+		errs = append(errs, errors.New("webhooks.max_attempts and webhooks.delivery_log_size must be at least 1"))
+	}
+	if c.Webhooks.BaseBackoff.Duration <= 0 || c.Webhooks.RequestTimeout.Duration <= 0 {
+		errs = append(errs, errors.New("webhooks.base_backoff and webhooks.request_timeout must be positive"))
+	}
+	if c.Imports.MaxRows < 1 || c.Imports.MaxBytes < 1 || c.Imports.MaxRunningJobs < 1 || c.Imports.AsyncThreshold < 0 {
+		errs = append(errs, errors.New("imports.max_rows, imports.max_bytes and imports.max_running_jobs must be at least 1 and imports.async_threshold must not be negative"))
+	}
+	if c.SLA.SweepInterval.Duration <= 0 || c.SLA.Warning.Duration < 0 {
+		errs = append(errs, errors.New("sla.sweep_interval must be positive and sla.warning must not be negative"))
+	}
+	for status, days := range c.SLA.Deadlines {
+		if !model.IsValidStatus(status) || model.IsDecided(status) || days < 1 {
+			errs = append(errs, fmt.Errorf("sla.deadlines.%s must be an open status with at least 1 business day", status))
+		}
+	}
+	if _, err := c.SLA.Calendar(); err != nil {
+		errs = append(errs, fmt.Errorf("sla: %w", err))
+	}
+	for i, o := range c.Assignment.Officers {
+		if o.Subject == "" || o.Capacity < 0 {
+			errs = append(errs, fmt.Errorf("assignment.officers[%d] requires a subject and a non-negative capacity", i))
+		}
+	}
+	if c.Assignment.AutoAssign && len(c.Assignment.Officers) == 0 {
+		errs = append(errs, errors.New("assignment.auto_assign requires assignment.officers"))
+	}
+	if c.Retention.SweepInterval.Duration <= 0 {
+		errs = append(errs, errors.New("retention.sweep_interval must be positive"))
+	}
+	for i, rule := range c.Retention.Rules {
+		if !model.IsValidStatus(rule.Status) || rule.AfterDays < 0 || !model.IsValidRetentionAction(rule.Action) {
+			errs = append(errs, fmt.Errorf("retention.rules[%d] requires a valid status, non-negative after_days and an action of delete or anonymize", i))
+		}
+	}
+	if !slices.Contains(model.DuplicatePolicies, c.Duplicates.Policy) {
+		errs = append(errs, fmt.Errorf("duplicates.policy must be one of %s, got %q", strings.Join(model.DuplicatePolicies, ", "), c.Duplicates.Policy))
+	}
+	if c.Duplicates.Policy != model.DuplicatePolicyOff && c.Duplicates.Window.Duration <= 0 {
+		errs = append(errs, errors.New("duplicates.window must be positive unless duplicates.policy is off"))
+	}
+	if c.Duplicates.AmountTolerance < 0 || c.Duplicates.AmountTolerance >= 1 {
+		errs = append(errs, errors.New("duplicates.amount_tolerance must be at least 0 and below 1"))
+	}
+	if c.Duplicates.SSNSecret != "" && len(c.Duplicates.SSNSecret) < 32 {
+		errs = append(errs, errors.New("duplicates.ssn_secret must be at least 32 bytes"))
+	}
+	for purpose, documentTypes := range c.Checklists {
+		if !model.IsValidLoanPurpose(purpose) || purpose != model.LoanPurposeKey(purpose) {
+			errs = append(errs, fmt.Errorf("checklists.%s must be a loan purpose key such as %s", purpose, model.PurposeHomeRenovation))
+		}
+		if slices.Contains(documentTypes, "") {
+			errs = append(errs, fmt.Errorf("checklists.%s has an empty document type", purpose))
+		}
+	}
+	for tenant, settings := range c.Tenants {
+		if settings.MinLoanAmount < 0 || settings.MaxLoanAmount < 0 ||
+			(settings.MaxLoanAmount > 0 && settings.MinLoanAmount > settings.MaxLoanAmount) {
+			errs = append(errs, fmt.Errorf("tenants.%s loan amount limits must be non-negative with min_loan_amount <= max_loan_amount", tenant))
+		}
+		if settings.Statuses != nil && !slices.Contains(settings.Statuses, model.StatusPending) {
+			errs = append(errs, fmt.Errorf("tenants.%s.statuses must include %s", tenant, model.StatusPending))
+		}
+		for _, status := range settings.Statuses {
+			if !model.IsValidStatus(status) {
+				errs = append(errs, fmt.Errorf("tenants.%s.statuses has unknown status %q", tenant, status))
+			}
+		}
+		for purpose := range settings.Checklists {
+			if !model.IsValidLoanPurpose(purpose) || purpose != model.LoanPurposeKey(purpose) {
+				errs = append(errs, fmt.Errorf("tenants.%s.checklists.%s must be a loan purpose key", tenant, purpose))
+			}
+		}
+	}
+	for i, t := range c.Auth.Tokens {
+		if t.Token == "" || t.Subject == "" {
+			errs = append(errs, fmt.Errorf("auth.tokens[%d] requires token and subject", i))
+		}
+		switch t.Role {
+		case model.RoleAdmin, model.RoleOfficer, model.RoleApplicant:
+		default:
+			errs = append(errs, fmt.Errorf("auth.tokens[%d].role must be one of: admin, officer, applicant", i))
+		}
+		for _, permission := range t.Permissions {
+			if !slices.Contains(model.Permissions, permission) {
+				errs = append(errs, fmt.Errorf("auth.tokens[%d] has unknown permission %q", i, permission))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Calendar builds the business day calendar for SLA deadlines.
+func (c SLAConfig) Calendar() (*sla.Calendar, error) {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	return sla.NewCalendar(loc, c.Holidays)
+}
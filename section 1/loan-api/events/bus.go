@@ -0,0 +1,72 @@
+package events
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"loan-api/model"
+)
+
+type Handler func(event model.Event)
+
+// Bus fans application events out to every subscriber in ID order. Handlers
+// are called synchronously, so long running work should be moved to a
+// goroutine. Events published while others are being delivered, including by
+// a handler, are queued and delivered after them by the publisher that is
+// already delivering.
+type Bus struct {
+	subscribers []Handler
+	nextID      int64
+	queue       []model.Event
+	delivering  bool
+	lock        sync.Mutex
+}
+
+func NewBus() *Bus {
+	return &Bus{nextID: 1}
+}
+
+func (b *Bus) Subscribe(handler Handler) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.subscribers = append(b.subscribers, handler)
+}
+
+func (b *Bus) Publish(eventType string, app model.LoanApplication, data map[string]string) model.Event {
+	b.lock.Lock()
+	event := model.Event{
+		ID:            b.nextID,
+		Type:          eventType,
+		Tenant:        model.TenantID(app.Tenant),
+		ApplicationID: app.ID,
+		OccurredAt:    time.Now().UTC(),
+		Application:   model.MaskIdentity(app),
+		Data:          data,
+	}
+	b.nextID++
+	b.queue = append(b.queue, event)
+	if b.delivering {
+		b.lock.Unlock()
+		return event
+	}
+	b.delivering = true
+	b.lock.Unlock()
+
+	for {
+		b.lock.Lock()
+		if len(b.queue) == 0 {
+			b.delivering = false
+			b.lock.Unlock()
+			return event
+		}
+		next := b.queue[0]
+		b.queue = b.queue[1:]
+		subscribers := slices.Clone(b.subscribers)
+		b.lock.Unlock()
+
+		for _, handler := range subscribers {
+			handler(next)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package events
+
+import (
+	"sync"
+
+	"loan-api/model"
+)
+
+const listenerBuffer = 64
+
+// GapEventType is sent to a listener resuming from an event that has already
+// been dropped from the log, in place of the events it missed.
+const GapEventType = "stream.gap"
+
+// Gap tells a resuming listener that the events after LastEventID and before
+// OldestEventID are no longer in the log.
+type Gap struct {
+	Type          string `json:"type"`
+	LastEventID   int64  `json:"last_event_id"`
+	OldestEventID int64  `json:"oldest_event_id"`
+}
+
+// Stream keeps the most recent events in a bounded log so clients can resume
+// after a reconnect, and fans new events out to live listeners. A listener
+// that falls more than listenerBuffer events behind is disconnected and is
+// expected to reconnect with its last seen event ID.
+type Stream struct {
+	log       []model.Event
+	capacity  int
+	listeners map[chan model.Event]struct{}
+	lock      sync.Mutex
+}
+
+func NewStream(capacity int) *Stream {
+	return &Stream{
+		capacity:  capacity,
+		listeners: make(map[chan model.Event]struct{}),
+	}
+}
+
+// HandleEvent appends the event to the log and notifies listeners. Erasure
+// events also redact the logged events of their application. It is meant to
+// be registered with Bus.Subscribe.
+func (s *Stream) HandleEvent(event model.Event) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if model.IsErasureEvent(event.Type) {
+		for i, logged := range s.log {
+			if logged.Tenant == event.Tenant && logged.ApplicationID == event.ApplicationID {
+				s.log[i] = logged.Redacted(event.Application)
+			}
+		}
+	}
+	s.log = append(s.log, event)
+	if len(s.log) > s.capacity {
+		s.log = s.log[len(s.log)-s.capacity:]
+	}
+
+	for ch := range s.listeners {
+		select {
+		case ch <- event:
+		default:
+			delete(s.listeners, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns the logged events newer than lastEventID together with a
+// channel of subsequent events. The backlog and the channel never overlap or
+// leave a gap. When events newer than lastEventID have already been dropped
+// from the log, the returned Gap says so. The channel is closed by cancel or
+// when the listener is too slow.
+func (s *Stream) Subscribe(lastEventID int64) ([]model.Event, *Gap, <-chan model.Event, func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var gap *Gap
+	// Event IDs have no holes, so a log starting after the next ID has lost
+	// events the listener has not seen.
+	if lastEventID > 0 && len(s.log) > 0 && s.log[0].ID > lastEventID+1 {
+		gap = &Gap{Type: GapEventType, LastEventID: lastEventID, OldestEventID: s.log[0].ID}
+	}
+	backlog := []model.Event{}
+	for _, event := range s.log {
+		if event.ID > lastEventID {
+			backlog = append(backlog, event)
+		}
+	}
+
+	ch := make(chan model.Event, listenerBuffer)
+	s.listeners[ch] = struct{}{}
+
+	cancel := func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if _, ok := s.listeners[ch]; ok {
+			delete(s.listeners, ch)
+			close(ch)
+		}
+	}
+	return backlog, gap, ch, cancel
+}
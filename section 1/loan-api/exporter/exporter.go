@@ -0,0 +1,170 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go/writer"
+	"loan-api/model"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+var Formats = []string{FormatCSV, FormatNDJSON, FormatParquet}
+
+// parquetRowGroupSize bounds how much of an export is buffered before a
+// Parquet row group is written out.
+const parquetRowGroupSize = 8 * 1024 * 1024
+
+// Writer encodes applications one at a time. Close must be called to
+// complete the output.
+type Writer interface {
+	Write(app model.LoanApplication) error
+	Close() error
+}
+
+// NewWriter returns a Writer for format that writes to w.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	case FormatParquet:
+		return newParquetWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/vnd.apache.parquet"
+	}
+}
+
+// columns are the tabular export columns, named after the JSON fields.
+var columns = []string{
+	"id", "applicant_name", "applicant_ssn", "country", "tax_id", "loan_amount", "loan_purpose",
+	"annual_income", "credit_score", "status", "submitted_at", "processed_at", "documents_uploaded", "submitted_by",
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	return cw, cw.w.Write(columns)
+}
+
+func (cw *csvWriter) Write(app model.LoanApplication) error {
+	processedAt := ""
+	if app.ProcessedAt != nil {
+		processedAt = app.ProcessedAt.UTC().Format(time.RFC3339)
+	}
+	return cw.w.Write([]string{
+		strconv.Itoa(app.ID),
+		app.ApplicantName,
+		app.ApplicantSSN,
+		app.Country,
+		app.TaxID,
+		strconv.FormatFloat(app.LoanAmount, 'f', -1, 64),
+		app.LoanPurpose,
+		strconv.FormatFloat(app.AnnualIncome, 'f', -1, 64),
+		strconv.Itoa(app.CreditScore),
+		app.Status,
+		app.SubmittedAt.UTC().Format(time.RFC3339),
+		processedAt,
+		strings.Join(app.DocumentsUploaded, ";"),
+		app.SubmittedBy,
+	})
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (nw *ndjsonWriter) Write(app model.LoanApplication) error {
+	return nw.encoder.Encode(app)
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
+
+type parquetRow struct {
+	ID                int64   `parquet:"name=id, type=INT64"`
+	ApplicantName     string  `parquet:"name=applicant_name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ApplicantSSN      string  `parquet:"name=applicant_ssn, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Country           string  `parquet:"name=country, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TaxID             string  `parquet:"name=tax_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	LoanAmount        float64 `parquet:"name=loan_amount, type=DOUBLE"`
+	LoanPurpose       string  `parquet:"name=loan_purpose, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AnnualIncome      float64 `parquet:"name=annual_income, type=DOUBLE"`
+	CreditScore       int32   `parquet:"name=credit_score, type=INT32"`
+	Status            string  `parquet:"name=status, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SubmittedAt       int64   `parquet:"name=submitted_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	ProcessedAt       *int64  `parquet:"name=processed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS, repetitiontype=OPTIONAL"`
+	DocumentsUploaded string  `parquet:"name=documents_uploaded, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SubmittedBy       string  `parquet:"name=submitted_by, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+type parquetWriter struct {
+	pw *writer.ParquetWriter
+}
+
+func newParquetWriter(w io.Writer) (*parquetWriter, error) {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(parquetRow), 1)
+	if err != nil {
+		return nil, err
+	}
+	pw.RowGroupSize = parquetRowGroupSize
+	return &parquetWriter{pw: pw}, nil
+}
+
+func (p *parquetWriter) Write(app model.LoanApplication) error {
+	row := parquetRow{
+		ID:                int64(app.ID),
+		ApplicantName:     app.ApplicantName,
+		ApplicantSSN:      app.ApplicantSSN,
+		Country:           app.Country,
+		TaxID:             app.TaxID,
+		LoanAmount:        app.LoanAmount,
+		LoanPurpose:       app.LoanPurpose,
+		AnnualIncome:      app.AnnualIncome,
+		CreditScore:       int32(app.CreditScore),
+		Status:            app.Status,
+		SubmittedAt:       app.SubmittedAt.UnixMilli(),
+		DocumentsUploaded: strings.Join(app.DocumentsUploaded, ";"),
+		SubmittedBy:       app.SubmittedBy,
+	}
+	if app.ProcessedAt != nil {
+		processedAt := app.ProcessedAt.UnixMilli()
+		row.ProcessedAt = &processedAt
+	}
+	return p.pw.Write(row)
+}
+
+func (p *parquetWriter) Close() error {
+	return p.pw.WriteStop()
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/apperror"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/store"
+)
+
+type AssignmentHandler struct {
+	Loans *LoanHandler
+	// Officers limits who can be assigned and sets their capacity. Officers
+	// only work applications of their own tenant. When empty, any subject can
+	// be assigned and auto-assignment is unavailable.
+	Officers []model.Officer
+	// AutoAssign assigns every new application when it is submitted.
+	AutoAssign bool
+}
+
+func NewAssignmentHandler(loans *LoanHandler, officers []model.Officer) *AssignmentHandler {
+	return &AssignmentHandler{Loans: loans, Officers: officers}
+}
+
+// AssignLoanApplication assigns an application to the officer in the body, or
+// to the least loaded officer when no officer is given.
+func (h *AssignmentHandler) AssignLoanApplication(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "ID")
+	if !ok {
+		return
+	}
+	principal, _ := middleware.CurrentPrincipal(c)
+	var req struct {
+		Officer string `json:"officer"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindingError(c, err)
+			return
+		}
+	}
+
+	if req.Officer == "" {
+		app, err := h.autoAssign(principal.TenantID(), id)
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+		render(c, http.StatusOK, model.GetMaskedApplication(app, principal))
+		return
+	}
+
+	officer, known := h.officer(principal.TenantID(), req.Officer)
+	if !known && len(h.Officers) > 0 {
+		apperror.Respond(c, apperror.ErrInvalidParameter.WithDetail("Unknown officer %q", req.Officer))
+		return
+	}
+	app, err := h.Loans.tenantStore(c).AssignLoanApplication(id, officer)
+	switch {
+	case errors.Is(err, store.ErrApplicationNotFound):
+		apperror.Respond(c, apperror.ErrApplicationNotFound)
+		return
+	case errors.Is(err, store.ErrAlreadyDecided):
+		apperror.Respond(c, apperror.ErrApplicationDecided.WithDetail("Application %d is already %s", id, app.Status))
+		return
+	case errors.Is(err, store.ErrCapacityReached):
+		apperror.Respond(c, apperror.ErrCapacityReached.WithDetail("%s already works %d open applications", officer.Subject, officer.Capacity))
+		return
+	case err != nil:
+		apperror.Respond(c, err)
+		return
+	}
+	h.publishAssigned(app, "manual")
+	render(c, http.StatusOK, model.GetMaskedApplication(app, principal))
+}
+
+// ClaimNextLoanApplication hands the oldest unassigned pending application to
+// the caller.
+func (h *AssignmentHandler) ClaimNextLoanApplication(c *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(c)
+	officer, _ := h.officer(principal.TenantID(), principal.Subject)
+
+	app, err := h.Loans.tenantStore(c).ClaimNextLoanApplication(principal.Subject, officer.Capacity)
+	switch {
+	case errors.Is(err, store.ErrQueueEmpty):
+		apperror.Respond(c, apperror.ErrQueueEmpty)
+		return
+	case errors.Is(err, store.ErrCapacityReached):
+		apperror.Respond(c, apperror.ErrCapacityReached.WithDetail("%s already works %d open applications", principal.Subject, officer.Capacity))
+		return
+	case err != nil:
+		apperror.Respond(c, err)
+		return
+	}
+	h.publishAssigned(app, "claim")
+	render(c, http.StatusOK, model.GetMaskedApplication(app, principal))
+}
+
+// MyQueue lists the caller's open assignments, oldest first.
+func (h *AssignmentHandler) MyQueue(c *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(c)
+
+	result := []model.LoanApplication{}
+	for _, app := range h.Loans.tenantStore(c).AssignedTo(principal.Subject) {
+		result = append(result, model.GetMaskedApplication(app, principal))
+	}
+	render(c, http.StatusOK, result)
+}
+
+// ListWorkloads reports every configured officer's open assignments.
+func (h *AssignmentHandler) ListWorkloads(c *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(c)
+	render(c, http.StatusOK, h.Loans.tenantStore(c).Workloads(h.officers(principal.TenantID())))
+}
+
+// HandleEvent auto-assigns newly submitted applications when AutoAssign is set.
+func (h *AssignmentHandler) HandleEvent(event model.Event) {
+	if !h.AutoAssign || event.Type != model.EventApplicationSubmitted {
+		return
+	}
+	// An application that cannot be assigned stays in the queue to be claimed.
+	h.autoAssign(event.Tenant, event.ApplicationID)
+}
+
+func (h *AssignmentHandler) autoAssign(tenant string, id int) (model.LoanApplication, error) {
+	officers := h.officers(tenant)
+	if len(officers) == 0 {
+		return model.LoanApplication{}, apperror.ErrCapacityReached.WithDetail("No officers are configured for auto-assignment")
+	}
+	app, err := h.Loans.Store.ForTenant(tenant).AutoAssignLoanApplication(id, officers)
+	switch {
+	case errors.Is(err, store.ErrApplicationNotFound):
+		return app, apperror.ErrApplicationNotFound
+	case errors.Is(err, store.ErrAlreadyDecided):
+		return app, apperror.ErrApplicationDecided.WithDetail("Application %d is already %s", id, app.Status)
+	case errors.Is(err, store.ErrCapacityReached):
+		return app, apperror.ErrCapacityReached.WithDetail("Every officer is at capacity")
+	case err != nil:
+		return app, err
+	}
+	h.publishAssigned(app, "auto")
+	return app, nil
+}
+
+// officers returns the configured officers of tenant.
+func (h *AssignmentHandler) officers(tenant string) []model.Officer {
+	var result []model.Officer
+	for _, officer := range h.Officers {
+		if model.TenantID(officer.Tenant) == tenant {
+			result = append(result, officer)
+		}
+	}
+	return result
+}
+
+func (h *AssignmentHandler) officer(tenant, subject string) (model.Officer, bool) {
+	for _, officer := range h.officers(tenant) {
+		if officer.Subject == subject {
+			return officer, true
+		}
+	}
+	return model.Officer{Subject: subject, Tenant: tenant}, false
+}
+
+func (h *AssignmentHandler) publishAssigned(app model.LoanApplication, method string) {
+	h.Loans.publish(model.EventApplicationAssigned, app, map[string]string{
+		"assigned_to": app.AssignedTo,
+		"method":      method,
+	})
+}
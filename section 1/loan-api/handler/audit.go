@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/middleware"
+	"loan-api/store"
+)
+
+type AuditHandler struct {
+	Store *store.AuditStore
+}
+
+func NewAuditHandler(s *store.AuditStore) *AuditHandler {
+	return &AuditHandler{Store: s}
+}
+
+func (h *AuditHandler) ListAuditLog(c *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(c)
+	render(c, http.StatusOK, h.Store.ForTenant(principal.TenantID()).List(c.Query("action")))
+}
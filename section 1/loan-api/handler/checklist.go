@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/apperror"
+	"loan-api/middleware"
+	"loan-api/model"
+)
+
+// GetChecklist shows which documents required for the application's loan
+// purpose have been uploaded.
+func (h *LoanHandler) GetChecklist(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apperror.Respond(c, errInvalidApplicationID)
+		return
+	}
+
+	app, found := h.tenantStore(c).GetLoanApplication(id)
+	principal, _ := middleware.CurrentPrincipal(c)
+	if !found || !principal.CanViewApplication(app) {
+		apperror.Respond(c, apperror.ErrApplicationNotFound)
+		return
+	}
+	render(c, http.StatusOK, h.checklist(app))
+}
+
+func (h *LoanHandler) checklist(app model.LoanApplication) model.Checklist {
+	return model.BuildChecklist(app, h.settings(app.Tenant).Checklists[model.LoanPurposeKey(app.LoanPurpose)])
+}
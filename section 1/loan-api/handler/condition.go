@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/apperror"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/store"
+)
+
+type conditionRequest struct {
+	Description  string `json:"description" binding:"required,max=500"`
+	DocumentType string `json:"document_type" binding:"required,max=100"`
+	DueDate      string `json:"due_date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// AddCondition attaches an approval condition to an application. Uploading a
+// document with the condition's document_type satisfies it.
+func (h *LoanHandler) AddCondition(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apperror.Respond(c, errInvalidApplicationID)
+		return
+	}
+	var req conditionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+
+	principal, _ := middleware.CurrentPrincipal(c)
+	app, condition, err := h.tenantStore(c).AddCondition(id, model.Condition{
+		Description:  req.Description,
+		DocumentType: req.DocumentType,
+		DueDate:      req.DueDate,
+		CreatedBy:    principal.Subject,
+	})
+	switch {
+	case errors.Is(err, store.ErrApplicationNotFound):
+		apperror.Respond(c, apperror.ErrApplicationNotFound)
+		return
+	case errors.Is(err, store.ErrAlreadyDecided):
+		apperror.Respond(c, apperror.ErrApplicationDecided.WithDetail("Application %d is already %s", id, app.Status))
+		return
+	}
+	render(c, http.StatusCreated, condition)
+}
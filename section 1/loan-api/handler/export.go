@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/apperror"
+	"loan-api/exporter"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/store"
+)
+
+// exportFlushInterval is how many rows are written between flushes to the client.
+const exportFlushInterval = 100
+
+type ExportHandler struct {
+	Store *store.MemoryStore
+	Audit *store.AuditStore
+}
+
+func NewExportHandler(s *store.MemoryStore, audit *store.AuditStore) *ExportHandler {
+	return &ExportHandler{Store: s, Audit: audit}
+}
+
+// ExportLoanApplications streams the filtered applications. Identity numbers
+// are masked unless the caller holds the PII permission, and every export is
+// written to the audit log.
+func (h *ExportHandler) ExportLoanApplications(c *gin.Context) {
+	format := c.DefaultQuery("format", exporter.FormatCSV)
+	if !slices.Contains(exporter.Formats, format) {
+		apperror.Respond(c, apperror.ErrInvalidParameter.WithDetail("format must be one of: %s", strings.Join(exporter.Formats, ", ")))
+		return
+	}
+	filter, ok := newApplicationFilter(c)
+	if !ok {
+		return
+	}
+
+	principal, _ := middleware.CurrentPrincipal(c)
+	includePII := principal.HasPermission(model.PermissionViewPII)
+
+	c.Header("Content-Type", exporter.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="loan-applications-%s.%s"`, time.Now().UTC().Format("20060102"), format))
+	c.Status(http.StatusOK)
+
+	out, err := exporter.NewWriter(format, c.Writer)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rows := 0
+	h.Store.ForTenant(principal.TenantID()).EachLoanApplication(func(app model.LoanApplication) bool {
+		if !filter.allows(app) || !principal.CanViewApplication(app) {
+			return true
+		}
+		if !includePII {
+			app = model.GetMaskedApplication(app, principal)
+		}
+		if err = out.Write(app); err != nil {
+			return false
+		}
+		rows++
+		if rows%exportFlushInterval == 0 {
+			c.Writer.Flush()
+		}
+		return true
+	})
+	if err == nil {
+		err = out.Close()
+	}
+
+	h.Audit.ForTenant(principal.TenantID()).Record(model.AuditEntry{
+		Actor:  principal.Subject,
+		Role:   principal.Role,
+		Action: model.AuditApplicationsExported,
+		Details: map[string]string{
+			"format":      format,
+			"filters":     c.Request.URL.RawQuery,
+			"rows":        strconv.Itoa(rows),
+			"include_pii": strconv.FormatBool(includePII),
+			"completed":   strconv.FormatBool(err == nil),
+		},
+	})
+	if err != nil {
+		// The status line has been sent, so the failure can only be logged.
+		c.Error(err)
+	}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/apperror"
+	"loan-api/model"
+)
+
+// applicationFilter holds the query filters shared by exports and reports.
+type applicationFilter struct {
+	status  string
+	purpose string
+	from    time.Time
+	to      time.Time
+}
+
+func (f applicationFilter) allows(app model.LoanApplication) bool {
+	if f.status != "" && !strings.EqualFold(app.Status, f.status) {
+		return false
+	}
+	if f.purpose != "" && model.LoanPurposeKey(app.LoanPurpose) != f.purpose {
+		return false
+	}
+	if !f.from.IsZero() && app.SubmittedAt.Before(f.from) {
+		return false
+	}
+	if !f.to.IsZero() && !app.SubmittedAt.Before(f.to) {
+		return false
+	}
+	return true
+}
+
+func newApplicationFilter(c *gin.Context) (applicationFilter, bool) {
+	filter := applicationFilter{status: c.Query("status")}
+	if purpose := c.Query("loan_purpose"); purpose != "" {
+		if !model.IsValidLoanPurpose(purpose) {
+			apperror.Respond(c, apperror.ErrInvalidParameter.WithDetail("loan_purpose must be one of: %s", strings.Join(model.LoanPurposes, ", ")))
+			return filter, false
+		}
+		filter.purpose = model.LoanPurposeKey(purpose)
+	}
+
+	var ok bool
+	if filter.from, ok = dateQuery(c, "from"); !ok {
+		return filter, false
+	}
+	if filter.to, ok = dateQuery(c, "to"); !ok {
+		return filter, false
+	}
+	if !filter.to.IsZero() {
+		// to is inclusive, so compare against the start of the next day.
+		filter.to = filter.to.AddDate(0, 0, 1)
+	}
+	return filter, true
+}
+
+// dateQuery parses a YYYY-MM-DD query parameter as the start of that day in UTC.
+func dateQuery(c *gin.Context, name string) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		apperror.Respond(c, apperror.ErrInvalidParameter.WithDetail("%s must be a date in YYYY-MM-DD format", name))
+		return time.Time{}, false
+	}
+	return t, true
+}
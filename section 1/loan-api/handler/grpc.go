@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"loan-api/apperror"
+	"loan-api/metrics"
+	"loan-api/middleware"
+	"loan-api/model"
+	loanv1 "loan-api/proto/loan/v1"
+	"loan-api/validator"
+)
+
+// LoanGRPCServer serves loan.v1.LoanService on top of a LoanHandler, so gRPC
+// and REST callers share the store, the validation rules and the events.
+type LoanGRPCServer struct {
+	loanv1.UnimplementedLoanServiceServer
+	Loans *LoanHandler
+}
+
+func NewLoanGRPCServer(loans *LoanHandler) *LoanGRPCServer {
+	return &LoanGRPCServer{Loans: loans}
+}
+
+func (s *LoanGRPCServer) SubmitLoanApplication(ctx context.Context, req *loanv1.SubmitLoanApplicationRequest) (*loanv1.LoanApplication, error) {
+	app := model.LoanApplication{
+		ApplicantName: req.GetApplicantName(),
+		ApplicantSSN:  req.GetApplicantSsn(),
+		Country:       req.GetCountry(),
+		TaxID:         req.GetTaxId(),
+		LoanAmount:    req.GetLoanAmount(),
+		LoanPurpose:   req.GetLoanPurpose(),
+		AnnualIncome:  req.GetAnnualIncome(),
+		CreditScore:   int(req.GetCreditScore()),
+	}
+
+	principal, _ := middleware.PrincipalFromContext(ctx)
+	acceptLanguage := incomingHeader(ctx, "accept-language")
+	if err := validator.ValidateStruct(app); err != nil {
+		return nil, bindingError(err, acceptLanguage)
+	}
+	if fields := s.Loans.validateForTenant(app, principal.TenantID(), acceptLanguage); fields != nil {
+		return nil, apperror.ErrValidation.WithFields(fields)
+	}
+	created, err := s.Loans.createApplication(ctx, app, principal)
+	if err != nil {
+		return nil, err
+	}
+	return applicationToProto(model.GetMaskedApplication(created, principal)), nil
+}
+
+func (s *LoanGRPCServer) GetLoanApplication(ctx context.Context, req *loanv1.GetLoanApplicationRequest) (*loanv1.LoanApplication, error) {
+	principal, _ := middleware.PrincipalFromContext(ctx)
+	app, err := s.Loans.getApplication(ctx, principal, int(req.GetId()))
+	if err != nil {
+		return nil, err
+	}
+	return applicationToProto(model.GetMaskedApplication(app, principal)), nil
+}
+
+func (s *LoanGRPCServer) ListLoanApplications(req *loanv1.ListLoanApplicationsRequest, stream loanv1.LoanService_ListLoanApplicationsServer) error {
+	principal, _ := middleware.PrincipalFromContext(stream.Context())
+	apps, err := s.Loans.listApplications(stream.Context(), principal, req.GetStatus(), req.GetSla())
+	if err != nil {
+		return err
+	}
+	for _, app := range apps {
+		if err := stream.Send(applicationToProto(app)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LoanGRPCServer) UpdateLoanApplicationStatus(ctx context.Context, req *loanv1.UpdateLoanApplicationStatusRequest) (*loanv1.LoanApplication, error) {
+	update := statusUpdate{Status: req.GetStatus(), Reason: req.GetReason()}
+	if err := validator.ValidateStruct(update); err != nil {
+		return nil, bindingError(err, incomingHeader(ctx, "accept-language"))
+	}
+	principal, _ := middleware.PrincipalFromContext(ctx)
+	app, err := s.Loans.updateStatus(ctx, principal, int(req.GetId()), update.Status, strings.TrimSpace(update.Reason))
+	if err != nil {
+		return nil, err
+	}
+	return applicationToProto(model.GetMaskedApplication(app, principal)), nil
+}
+
+// UploadDocument reads the metadata from the first message and writes the
+// chunks that follow to the upload directory. The application is looked up
+// first so that uploads for unknown applications leave no file behind.
+func (s *LoanGRPCServer) UploadDocument(stream loanv1.LoanService_UploadDocumentServer) error {
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	meta := first.GetMetadata()
+	if meta == nil || meta.GetFilename() == "" {
+		return apperror.ErrDocumentMissing.WithDetail("The first message must carry the document metadata with a filename")
+	}
+
+	principal, _ := middleware.PrincipalFromContext(ctx)
+	id := int(meta.GetApplicationId())
+	if _, err := s.Loans.getApplication(ctx, principal, id); err != nil {
+		return err
+	}
+
+	filename := documentFilename(id, meta.GetFilename())
+	dst := s.Loans.documentPath(filename)
+	size, err := receiveDocument(stream, dst, s.Loans.MaxDocumentBytes)
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	metrics.UploadBytes.WithLabelValues(metrics.UploadDocument).Add(float64(size))
+
+	app, err := s.Loans.addDocument(ctx, principal, id, model.Document{Name: filename, Type: strings.TrimSpace(meta.GetDocumentType()), Path: dst})
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(applicationToProto(model.GetMaskedApplication(app, principal)))
+}
+
+// receiveDocument writes the chunks of stream to dst until the client closes
+// its side of the stream. It stops with ErrDocumentTooLarge, which gRPC
+// reports as ResourceExhausted, once the document grows past maxBytes.
+func receiveDocument(stream loanv1.LoanService_UploadDocumentServer, dst string, maxBytes int64) (int64, error) {
+	f, err := os.Create(dst)
+	if err != nil {
+		return 0, apperror.ErrStorageFailed.Wrap(err)
+	}
+	defer f.Close()
+
+	var size int64
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return size, err
+		}
+		if msg.GetMetadata() != nil {
+			return size, apperror.ErrMalformedRequest.WithDetail("Only the first message may carry metadata")
+		}
+		if size+int64(len(msg.GetChunk())) > maxBytes {
+			return size, apperror.ErrDocumentTooLarge.WithDetail("A document may be at most %d bytes", maxBytes)
+		}
+		n, err := f.Write(msg.GetChunk())
+		size += int64(n)
+		if err != nil {
+			return size, apperror.ErrStorageFailed.Wrap(err)
+		}
+	}
+	if size == 0 {
+		return size, apperror.ErrDocumentMissing.WithDetail("The document is empty")
+	}
+	if err := f.Close(); err != nil {
+		return size, apperror.ErrStorageFailed.Wrap(err)
+	}
+	return size, nil
+}
+
+func incomingHeader(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// applicationToProto converts an application that has already been masked.
+func applicationToProto(app model.LoanApplication) *loanv1.LoanApplication {
+	result := &loanv1.LoanApplication{
+		Id:                int64(app.ID),
+		Tenant:            app.Tenant,
+		ApplicantName:     app.ApplicantName,
+		ApplicantSsn:      app.ApplicantSSN,
+		Country:           app.Country,
+		TaxId:             app.TaxID,
+		LoanAmount:        app.LoanAmount,
+		LoanPurpose:       app.LoanPurpose,
+		AnnualIncome:      app.AnnualIncome,
+		CreditScore:       int32(app.CreditScore),
+		Status:            app.Status,
+		StatusUpdatedAt:   timestamppb.New(app.StatusUpdatedAt),
+		SubmittedAt:       timestamppb.New(app.SubmittedAt),
+		DocumentsUploaded: app.DocumentsUploaded,
+		SubmittedBy:       app.SubmittedBy,
+		AssignedTo:        app.AssignedTo,
+	}
+	if app.ProcessedAt != nil {
+		result.ProcessedAt = timestamppb.New(*app.ProcessedAt)
+	}
+	for _, doc := range app.Documents {
+		result.Documents = append(result.Documents, &loanv1.Document{Name: doc.Name, Type: doc.Type})
+	}
+	return result
+}
+
+// bindingError converts a ValidateStruct error the way respondBindingError
+// does for REST requests.
+func bindingError(err error, acceptLanguage string) error {
+	fields, errV := validator.ValidateLoanApplication(err, acceptLanguage)
+	if errV != nil {
+		return apperror.ErrValidation.WithFields(fields)
+	}
+	return apperror.ErrMalformedRequest.WithDetail("%s", err.Error())
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/health"
+)
+
+// HealthHandler serves the probes of an orchestrator. Neither needs a token.
+type HealthHandler struct {
+	Checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{Checker: checker}
+}
+
+// Liveness reports that the process is serving requests. It checks no
+// dependencies, so a failing dependency does not get the process restarted.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+// Readiness runs every check and answers 503 unless all of them pass.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.Checker.Run(c.Request.Context())
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/apperror"
+	"loan-api/middleware"
+	"loan-api/model"
+)
+
+// GetHistory lists the lifecycle events of an application, oldest first. The
+// caller sees the same events as on the event stream.
+func (h *LoanHandler) GetHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apperror.Respond(c, errInvalidApplicationID)
+		return
+	}
+
+	app, found := h.tenantStore(c).GetLoanApplication(id)
+	principal, _ := middleware.CurrentPrincipal(c)
+	if !found || !principal.CanViewApplication(app) {
+		apperror.Respond(c, apperror.ErrApplicationNotFound)
+		return
+	}
+	entries := []model.HistoryEntry{}
+	for _, entry := range h.History.ForTenant(principal.TenantID()).List(id) {
+		if principal.CanViewEvent(app, entry.Type, entry.Data) {
+			entry.Data = principal.VisibleEventData(entry.Data)
+			entries = append(entries, entry)
+		}
+	}
+	render(c, http.StatusOK, entries)
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/apperror"
+	"loan-api/importer"
+	"loan-api/metrics"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/store"
+	"loan-api/validator"
+)
+
+// progressInterval is how many rows an import processes between progress updates.
+const progressInterval = 50
+
+type ImportHandler struct {
+	Loans  *LoanHandler
+	Jobs   *store.ImportStore
+	Limits model.ImportLimits
+
+	// running holds a slot for each background import.
+	running chan struct{}
+}
+
+func NewImportHandler(loans *LoanHandler, jobs *store.ImportStore, limits model.ImportLimits) *ImportHandler {
+	return &ImportHandler{Loans: loans, Jobs: jobs, Limits: limits, running: make(chan struct{}, max(limits.MaxRunningJobs, 1))}
+}
+
+func (h *ImportHandler) ImportLoanApplications(c *gin.Context) {
+	format := strings.ToLower(c.Query("format"))
+	if format == "" {
+		format, _ = importer.FormatFromContentType(c.GetHeader("Content-Type"))
+	}
+	if format != model.ImportFormatCSV && format != model.ImportFormatNDJSON {
+		apperror.Respond(c, apperror.ErrUnsupportedMedia.WithDetail("Send text/csv or application/x-ndjson, or set format to csv or ndjson"))
+		return
+	}
+	dryRun, ok := boolQuery(c, "dry_run")
+	if !ok {
+		return
+	}
+	async, ok := boolQuery(c, "async")
+	if !ok {
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, h.Limits.MaxBytes)
+	rows, err := importer.Decode(metrics.CountUpload(metrics.UploadImport, body), format, h.Limits.MaxRows)
+	if errors.Is(err, importer.ErrTooManyRows) {
+		apperror.Respond(c, apperror.ErrImportTooLarge.WithDetail("An import may contain at most %d rows", h.Limits.MaxRows))
+		return
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		apperror.Respond(c, apperror.ErrImportTooLarge.WithDetail("An import may be at most %d bytes", tooLarge.Limit))
+		return
+	}
+	if err != nil {
+		apperror.Respond(c, apperror.ErrMalformedRequest.WithDetail("%s", err.Error()))
+		return
+	}
+
+	background := async || len(rows) > h.Limits.AsyncThreshold
+	if background {
+		select {
+		case h.running <- struct{}{}:
+		default:
+			apperror.Respond(c, apperror.ErrImportsBusy.WithDetail("At most %d imports run in the background; try again later", cap(h.running)))
+			return
+		}
+	}
+
+	principal, _ := middleware.CurrentPrincipal(c)
+	jobs := h.Jobs.ForTenant(principal.TenantID())
+	job := jobs.SaveImportJob(model.ImportJob{
+		Status:      model.ImportPending,
+		Format:      format,
+		DryRun:      dryRun,
+		TotalRows:   len(rows),
+		SubmittedBy: principal.Subject,
+	})
+
+	acceptLanguage := c.GetHeader("Accept-Language")
+	c.Header("Content-Language", validator.Translator(acceptLanguage).Locale())
+	if background {
+		// The job outlives the request, but its spans still belong to its trace.
+		ctx := context.WithoutCancel(c.Request.Context())
+		go func() {
+			defer func() { <-h.running }()
+			h.run(ctx, jobs, job, rows, principal, acceptLanguage)
+		}()
+		c.Header("Location", fmt.Sprintf("/%s/loan-applications/imports/%d", middleware.CurrentAPIVersion(c), job.ID))
+		render(c, http.StatusAccepted, job)
+		return
+	}
+	render(c, http.StatusOK, h.run(c.Request.Context(), jobs, job, rows, principal, acceptLanguage))
+}
+
+func (h *ImportHandler) GetImportJob(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Import ID")
+	if !ok {
+		return
+	}
+
+	principal, _ := middleware.CurrentPrincipal(c)
+	job, found := h.Jobs.ForTenant(principal.TenantID()).GetImportJob(id)
+	if !found || !principal.CanViewImport(job) {
+		apperror.Respond(c, apperror.ErrImportNotFound)
+		return
+	}
+	render(c, http.StatusOK, job)
+}
+
+func (h *ImportHandler) run(ctx context.Context, jobs *store.ImportStore, job model.ImportJob, rows []importer.Row, principal model.Principal, acceptLanguage string) model.ImportJob {
+	job.Status = model.ImportRunning
+	jobs.UpdateImportJob(job)
+
+	for i, row := range rows {
+		result := h.importRow(ctx, row, principal, job.DryRun, acceptLanguage)
+		job.Rows = append(job.Rows, result)
+		job.Processed++
+		switch {
+		case !result.Succeeded():
+			job.Failed++
+		case job.DryRun:
+			job.Valid++
+		default:
+			job.Valid++
+			job.Created++
+		}
+		if (i+1)%progressInterval == 0 {
+			jobs.UpdateImportJob(job)
+		}
+	}
+
+	now := time.Now()
+	job.Status = model.ImportCompleted
+	job.CompletedAt = &now
+	jobs.UpdateImportJob(job)
+	return job
+}
+
+// importRow validates a row with the same rules as SubmitLoanApplication and
+// creates the application unless this is a dry run.
+func (h *ImportHandler) importRow(ctx context.Context, row importer.Row, principal model.Principal, dryRun bool, acceptLanguage string) model.ImportRowResult {
+	result := model.ImportRowResult{Row: row.Line}
+
+	err := row.Err
+	if err == nil {
+		err = validator.ValidateStruct(row.Application)
+	}
+	if err == nil {
+		if fields := h.Loans.validateForTenant(row.Application, principal.TenantID(), acceptLanguage); fields != nil {
+			result.Errors = fields
+			return result
+		}
+	}
+	if err != nil {
+		fields, errV := validator.ValidateLoanApplication(err, acceptLanguage)
+		if errV != nil {
+			result.Errors = fields
+		} else {
+			result.Error = err.Error()
+		}
+		return result
+	}
+
+	if !dryRun {
+		created, err := h.Loans.createApplication(ctx, row.Application, principal)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.ApplicationID = created.ID
+	}
+	return result
+}
+
+func boolQuery(c *gin.Context, name string) (bool, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return false, true
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		apperror.Respond(c, apperror.ErrInvalidParameter.WithDetail("%s must be true or false", name))
+		return false, false
+	}
+	return b, true
+}
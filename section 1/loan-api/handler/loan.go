@@ -1,39 +1,61 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"loan-api/validator"
 	"net/http"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"loan-api/apperror"
+	"loan-api/events"
+	"loan-api/metrics"
+	"loan-api/middleware"
 	"loan-api/model"
+	"loan-api/serializer"
 	"loan-api/store"
 )
 
 type LoanHandler struct {
-	Store *store.MemoryStore
+	Store     *store.MemoryStore
+	Events    *events.Bus
+	UploadDir string
+	// Checklists maps a loan purpose key to the document types that must be
+	// uploaded before an application for that purpose can be approved.
+	Checklists map[string][]string
+	// Tenants holds per-tenant overrides of the limits above.
+	Tenants map[string]model.TenantSettings
+	// History records the lifecycle events of each application. The history
+	// endpoint is only served when it is set.
+	History *store.HistoryStore
+	// Duplicates decides what happens to submissions resembling an earlier
+	// application. The zero value accepts them unchecked.
+	Duplicates model.DuplicateRule
+	// MaxDocumentBytes caps the size of one uploaded document.
+	MaxDocumentBytes int64
 }
 
-func NewLoanHandler(s *store.MemoryStore) *LoanHandler {
-	return &LoanHandler{Store: s}
+func NewLoanHandler(s *store.MemoryStore, bus *events.Bus) *LoanHandler {
+	return &LoanHandler{Store: s, Events: bus, UploadDir: "./uploads", MaxDocumentBytes: 10 << 20}
 }
 
-func (h *LoanHandler) ListLoanApplications(c *gin.Context) {
-	allApps := h.Store.ListLoanApplications()
-
-	var result []model.LoanApplication
-	for _, app := range allApps {
-		result = append(result, model.GetMaskedApplication(app))
+func (h *LoanHandler) publish(eventType string, app model.LoanApplication, data map[string]string) {
+	if h.Events != nil {
+		h.Events.Publish(eventType, app, data)
 	}
+}
 
+func (h *LoanHandler) ListLoanApplications(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	statusFilter := c.Query("status")
-
 	if page < 1 {
 		page = 1
 	}
@@ -41,136 +63,330 @@ func (h *LoanHandler) ListLoanApplications(c *gin.Context) {
 		limit = 10
 	}
 
-	filteredResult := []model.LoanApplication{}
-	for _, app := range result {
-		if statusFilter == "" || strings.EqualFold(app.Status, statusFilter) {
-			filteredResult = append(filteredResult, app)
-		}
+	principal, _ := middleware.CurrentPrincipal(c)
+	filteredResult, err := h.listApplications(c.Request.Context(), principal, c.Query("status"), c.Query("sla"))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
 	}
 
-	start := (page - 1) * limit
-	end := start + limit
+	start := min((page-1)*limit, len(filteredResult))
+	end := min(start+limit, len(filteredResult))
 
-	if start >= len(filteredResult) {
-		c.JSON(http.StatusOK, []model.LoanApplication{})
-		return
-	}
-	if end > len(filteredResult) {
-		end = len(filteredResult)
+	render(c, http.StatusOK, serializer.List{
+		Items: filteredResult[start:end],
+		Page:  page,
+		Limit: limit,
+		Total: len(filteredResult),
+	})
+}
+
+// listApplications returns the masked applications the principal may view in
+// ID order, filtered by status and SLA state when they are not empty.
+func (h *LoanHandler) listApplications(ctx context.Context, principal model.Principal, statusFilter, slaFilter string) ([]model.LoanApplication, error) {
+	if slaFilter != "" && !slices.Contains(model.SLAStates, slaFilter) {
+		return nil, apperror.ErrInvalidParameter.WithDetail("sla must be one of: %s", strings.Join(model.SLAStates, ", "))
 	}
 
-	c.JSON(http.StatusOK, filteredResult[start:end])
+	span := storeCallSpan(ctx, principal.TenantID(), "ListLoanApplications", 0)
+	allApps := h.Store.ForTenant(principal.TenantID()).ListLoanApplications()
+	span.End()
+	sort.Slice(allApps, func(i, j int) bool { return allApps[i].ID < allApps[j].ID })
+
+	result := []model.LoanApplication{}
+	for _, app := range allApps {
+		if !principal.CanViewApplication(app) {
+			continue
+		}
+		if statusFilter != "" && !strings.EqualFold(app.Status, statusFilter) {
+			continue
+		}
+		if slaFilter != "" && (app.SLA == nil || app.SLA.State != slaFilter) {
+			continue
+		}
+		result = append(result, model.GetMaskedApplication(app, principal))
+	}
+	return result, nil
 }
 
 func (h *LoanHandler) GetLoanApplication(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{Error: "Invalid application ID", Details: []string{"ID must be an integer"}})
+		apperror.Respond(c, errInvalidApplicationID)
 		return
 	}
 
-	app, found := h.Store.GetLoanApplication(id)
-	if !found {
-		c.JSON(http.StatusNotFound, model.ErrorResponse{Error: "Loan application not found"})
+	principal, _ := middleware.CurrentPrincipal(c)
+	var app model.LoanApplication
+	if asOf := c.Query("as_of"); asOf != "" {
+		at, parseErr := time.Parse(time.RFC3339Nano, asOf)
+		if parseErr != nil {
+			apperror.Respond(c, apperror.ErrInvalidParameter.WithDetail("as_of must be an RFC 3339 timestamp"))
+			return
+		}
+		app, err = h.getApplicationAsOf(c.Request.Context(), principal, id, at)
+	} else {
+		app, err = h.getApplication(c.Request.Context(), principal, id)
+	}
+	if err != nil {
+		apperror.Respond(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, model.GetMaskedApplication(app))
+	render(c, http.StatusOK, model.GetMaskedApplication(app, principal))
+}
+
+// getApplicationAsOf returns the application as it was at the given time,
+// which is not found when it was submitted later or the principal may not
+// view it.
+func (h *LoanHandler) getApplicationAsOf(ctx context.Context, principal model.Principal, id int, at time.Time) (model.LoanApplication, error) {
+	span := storeCallSpan(ctx, principal.TenantID(), "LoanApplicationAsOf", id)
+	app, found := h.Store.ForTenant(principal.TenantID()).LoanApplicationAsOf(id, at)
+	span.End()
+	if !found || !principal.CanViewApplication(app) {
+		return app, apperror.ErrApplicationNotFound
+	}
+	return app, nil
+}
+
+// getApplication returns the application of the principal's tenant with the
+// given ID. Applications the principal may not view are reported as not found.
+func (h *LoanHandler) getApplication(ctx context.Context, principal model.Principal, id int) (model.LoanApplication, error) {
+	span := storeCallSpan(ctx, principal.TenantID(), "GetLoanApplication", id)
+	app, found := h.Store.ForTenant(principal.TenantID()).GetLoanApplication(id)
+	span.End()
+	if !found || !principal.CanViewApplication(app) {
+		return app, apperror.ErrApplicationNotFound
+	}
+	return app, nil
 }
 
 func (h *LoanHandler) SubmitLoanApplication(c *gin.Context) {
-	var newApp model.LoanApplication
-	if err := c.ShouldBindJSON(&newApp); err != nil {
-		if messages, errV := validator.ValidateLoanApplication(err); errV != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   errV.Error(),
-				"details": messages,
-			})
-			return
-		}
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{Error: "Invalid input", Details: []string{err.Error()}})
+	var input model.LoanApplicationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+	newApp := input.Application()
+	if err := validator.ValidateStruct(newApp); err != nil {
+		respondBindingError(c, err)
 		return
 	}
 
-	if len(newApp.ApplicantSSN) != 11 || newApp.ApplicantSSN[3] != '-' || newApp.ApplicantSSN[6] != '-' {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{Error: "Invalid SSN format", Details: []string{"SSN must be in XXX-XX-XXXX format"}})
+	principal, _ := middleware.CurrentPrincipal(c)
+	acceptLanguage := c.GetHeader("Accept-Language")
+	if fields := h.validateForTenant(newApp, principal.TenantID(), acceptLanguage); fields != nil {
+		c.Header("Content-Language", validator.Translator(acceptLanguage).Locale())
+		apperror.Respond(c, apperror.ErrValidation.WithFields(fields))
 		return
 	}
+	createdApp, err := h.createApplication(c.Request.Context(), newApp, principal)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	render(c, http.StatusCreated, model.GetMaskedApplication(createdApp, principal))
+}
+
+// createApplication stores a validated application on behalf of principal.
+// It fails when the duplicate policy rejects the application.
+func (h *LoanHandler) createApplication(ctx context.Context, app model.LoanApplication, principal model.Principal) (model.LoanApplication, error) {
+	if app.Country == "" {
+		app.Country = model.CountryUS
+	}
+	app.SubmittedBy = principal.Subject
 
-	createdApp := h.Store.SaveLoanApplication(newApp)
+	span := storeCallSpan(ctx, principal.TenantID(), "SubmitLoanApplication", 0)
+	createdApp, err := h.Store.ForTenant(principal.TenantID()).SubmitLoanApplication(app, h.Duplicates)
+	span.SetAttributes(attribute.Int("loan.application_id", createdApp.ID))
+	span.End()
+	var duplicateErr *store.DuplicateError
+	if errors.As(err, &duplicateErr) {
+		// Only staff learn which applications matched; applicants could use
+		// the references to probe for other applicants' submissions.
+		if !principal.IsStaff() {
+			return createdApp, apperror.ErrDuplicateApplication.WithDetail("An application was recently submitted with the same applicant details")
+		}
+		return createdApp, apperror.ErrDuplicateApplication.
+			WithDetail("Application %d was submitted with the same applicant details", duplicateErr.Matches[0].ApplicationID).
+			WithDuplicates(duplicateErr.Matches)
+	}
 
-	c.JSON(http.StatusCreated, model.GetMaskedApplication(createdApp))
+	var data map[string]string
+	if check := createdApp.DuplicateCheck; check != nil {
+		ids := make([]string, len(check.Matches))
+		for i, match := range check.Matches {
+			ids[i] = strconv.Itoa(match.ApplicationID)
+		}
+		data = map[string]string{"duplicate_of": strings.Join(ids, ","), "duplicate_action": check.Action}
+	}
+	h.publish(model.EventApplicationSubmitted, createdApp, data)
+	return createdApp, nil
 }
 
 func (h *LoanHandler) UpdateLoanApplicationStatus(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{Error: "Invalid application ID", Details: []string{"ID must be an integer"}})
+		apperror.Respond(c, errInvalidApplicationID)
 		return
 	}
 
-	var statusUpdate struct {
-		Status string `json:"status" binding:"required"`
-	}
+	var statusUpdate statusUpdate
 	if err := c.ShouldBindJSON(&statusUpdate); err != nil {
-		if messages, errV := validator.ValidateLoanApplication(err); errV != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   errV.Error(),
-				"details": messages,
-			})
-			return
-		}
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{Error: "Invalid input", Details: []string{err.Error()}})
+		respondBindingError(c, err)
 		return
 	}
 
-	validStatuses := map[string]bool{
-		"pending":      true,
-		"approved":     true,
-		"rejected":     true,
-		"under_review": true,
-	}
-	if !validStatuses[statusUpdate.Status] {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{Error: "Invalid status", Details: []string{"Status must be one of: pending, approved, rejected, under_review"}})
+	principal, _ := middleware.CurrentPrincipal(c)
+	updatedApp, err := h.updateStatus(c.Request.Context(), principal, id, statusUpdate.Status, strings.TrimSpace(statusUpdate.Reason))
+	if err != nil {
+		apperror.Respond(c, err)
 		return
 	}
 
-	updatedApp, found := h.Store.UpdateLoanApplicationStatus(id, statusUpdate.Status)
-	if !found {
-		c.JSON(http.StatusNotFound, model.ErrorResponse{Error: "Loan application not found"})
-		return
+	render(c, http.StatusOK, model.GetMaskedApplication(updatedApp, principal))
+}
+
+// statusUpdate is the body of a status change. Reason is optional and kept in
+// the application's history.
+type statusUpdate struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// updateStatus moves an application of the principal's tenant to status, for
+// staff only over every transport, enforcing the tenant's statuses, the allowed transitions, the document
+// checklist on approval and the condition rules. The principal and the
+// optional reason are recorded on the status change event.
+func (h *LoanHandler) updateStatus(ctx context.Context, principal model.Principal, id int, status, reason string) (model.LoanApplication, error) {
+	if !principal.IsStaff() {
+		return model.LoanApplication{}, apperror.ErrForbidden.WithDetail("Only officers and admins can change the status")
+	}
+	settings := h.settings(principal.TenantID())
+	if !slices.Contains(settings.Statuses, status) {
+		return model.LoanApplication{}, apperror.ErrInvalidStatus.WithDetail("Status must be one of: %s", strings.Join(settings.Statuses, ", "))
+	}
+
+	previousApp, err := h.getApplication(ctx, principal, id)
+	if err != nil {
+		return previousApp, err
 	}
 
-	c.JSON(http.StatusOK, model.GetMaskedApplication(updatedApp))
+	span := storeCallSpan(ctx, principal.TenantID(), "ChangeLoanApplicationStatus", id)
+	updatedApp, err := h.Store.ForTenant(principal.TenantID()).ChangeLoanApplicationStatus(id, status, settings.Checklists)
+	span.End()
+	switch {
+	case errors.Is(err, store.ErrApplicationNotFound):
+		return updatedApp, apperror.ErrApplicationNotFound
+	case errors.Is(err, store.ErrInvalidTransition):
+		return updatedApp, apperror.ErrInvalidTransition.WithDetail("A %s application cannot move to %s", updatedApp.Status, status)
+	case errors.Is(err, store.ErrChecklistIncomplete):
+		missing := h.checklist(updatedApp).Missing()
+		if status == model.StatusApprovedWithConditions {
+			missing = model.UncoveredDocuments(updatedApp, h.checklist(updatedApp))
+		}
+		return updatedApp, apperror.ErrChecklistIncomplete.WithDetail("Missing required documents: %s", strings.Join(missing, ", "))
+	case errors.Is(err, store.ErrNoConditions):
+		return updatedApp, apperror.ErrInvalidTransition.WithDetail("Add a condition before approving with conditions")
+	case errors.Is(err, store.ErrConditionsOutstanding):
+		return updatedApp, apperror.ErrConditionsOutstanding.WithDetail("%d conditions must be satisfied before funding", len(model.OutstandingConditions(updatedApp)))
+	}
+	data := map[string]string{
+		"previous_status": previousApp.Status,
+		"status":          updatedApp.Status,
+		"changed_by":      principal.Subject,
+	}
+	if reason != "" {
+		data["reason"] = reason
+	}
+	h.publish(model.EventApplicationStatusChanged, updatedApp, data)
+	return updatedApp, nil
 }
 
 func (h *LoanHandler) UploadSupportingDocuments(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse{Error: "Invalid application ID", Details: []string{"ID must be an integer"}})
+		apperror.Respond(c, errInvalidApplicationID)
+		return
+	}
+
+	principal, _ := middleware.CurrentPrincipal(c)
+	if _, err := h.getApplication(c.Request.Context(), principal, id); err != nil {
+		apperror.Respond(c, err)
 		return
 	}
 
 	// Get the file from the form data
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.MaxDocumentBytes)
 	file, err := c.FormFile("document")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		apperror.Respond(c, apperror.ErrDocumentTooLarge.WithDetail("A document may be at most %d bytes", tooLarge.Limit))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, model.ErrorResponse{Error: "Document upload failed"})
+		apperror.Respond(c, apperror.ErrDocumentMissing.WithDetail("A file must be sent in the \"document\" form field"))
 		return
 	}
 
 	// Save the file (simplified - in production would use secure storage)
-	filename := fmt.Sprintf("doc_%d_%s", id, file.Filename)
-	dst := filepath.Join("./uploads", fmt.Sprintf("%d_%s", time.Now().UnixNano(), filename))
+	filename := documentFilename(id, file.Filename)
+	dst := h.documentPath(filename)
 	if err := c.SaveUploadedFile(file, dst); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to save file"})
+		apperror.Respond(c, apperror.ErrStorageFailed.Wrap(err))
 		return
 	}
+	metrics.UploadBytes.WithLabelValues(metrics.UploadDocument).Add(float64(file.Size))
 
-	updatedApp, found := h.Store.AddDocumentToApplication(id, filename)
-	if !found {
-		c.JSON(http.StatusNotFound, model.ErrorResponse{Error: "Loan application not found"})
+	documentType := strings.TrimSpace(c.PostForm("document_type"))
+	updatedApp, err := h.addDocument(c.Request.Context(), principal, id, model.Document{Name: filename, Type: documentType, Path: dst})
+	if err != nil {
+		apperror.Respond(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, model.GetMaskedApplication(updatedApp))
+	render(c, http.StatusOK, model.GetMaskedApplication(updatedApp, principal))
+}
+
+func documentFilename(id int, name string) string {
+	return fmt.Sprintf("doc_%d_%s", id, filepath.Base(name))
+}
+
+// documentPath returns where a document is saved. The timestamp keeps repeated
+// uploads of the same file apart.
+func (h *LoanHandler) documentPath(filename string) string {
+	return filepath.Join(h.UploadDir, fmt.Sprintf("%d_%s", time.Now().UnixNano(), filename))
+}
+
+// addDocument records a document that has been saved at doc.Path on an
+// application of the principal's tenant.
+func (h *LoanHandler) addDocument(ctx context.Context, principal model.Principal, id int, doc model.Document) (model.LoanApplication, error) {
+	span := storeCallSpan(ctx, principal.TenantID(), "AddDocumentToApplication", id)
+	updatedApp, satisfied, found := h.Store.ForTenant(principal.TenantID()).AddDocumentToApplication(id, doc)
+	span.End()
+	if !found {
+		return updatedApp, apperror.ErrApplicationNotFound
+	}
+	h.publish(model.EventDocumentUploaded, updatedApp, map[string]string{"document": doc.Name, "document_type": doc.Type})
+	if satisfied != nil {
+		h.publish(model.EventConditionSatisfied, updatedApp, map[string]string{
+			"condition_id": strconv.Itoa(satisfied.ID),
+			"document":     doc.Name,
+		})
+	}
+	return updatedApp, nil
+}
+
+var errInvalidApplicationID = apperror.ErrInvalidID.WithDetail("ID must be an integer")
+
+func respondBindingError(c *gin.Context, err error) {
+	acceptLanguage := c.GetHeader("Accept-Language")
+	fields, errV := validator.ValidateLoanApplication(err, acceptLanguage)
+	if errV != nil {
+		c.Header("Content-Language", validator.Translator(acceptLanguage).Locale())
+		apperror.Respond(c, apperror.ErrValidation.WithFields(fields))
+		return
+	}
+	apperror.Respond(c, apperror.ErrMalformedRequest.WithDetail("%s", err.Error()))
 }
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/apperror"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/store"
+)
+
+type NoteHandler struct {
+	Loans *LoanHandler
+	Notes *store.NoteStore
+}
+
+func NewNoteHandler(loans *LoanHandler, notes *store.NoteStore) *NoteHandler {
+	return &NoteHandler{Loans: loans, Notes: notes}
+}
+
+type noteRequest struct {
+	Body       string `json:"body" binding:"required,max=5000"`
+	Visibility string `json:"visibility" binding:"omitempty,oneof=internal applicant"`
+	ParentID   *int   `json:"parent_id"`
+}
+
+// ListNotes returns the application's notes as threads, oldest first.
+// Applicants only see applicant-visible notes, without edit history.
+func (h *NoteHandler) ListNotes(c *gin.Context) {
+	app, principal, ok := h.application(c)
+	if !ok {
+		return
+	}
+
+	threads := []model.Note{}
+	index := map[int]int{}
+	for _, note := range h.notes(principal).ListNotes(app.ID) {
+		if !principal.CanViewNote(app, note) {
+			continue
+		}
+		note = noteView(note, principal)
+		if note.ParentID == nil {
+			index[note.ID] = len(threads)
+			threads = append(threads, note)
+			continue
+		}
+		// Replies whose thread is hidden from the caller are hidden too.
+		if i, found := index[*note.ParentID]; found {
+			threads[i].Replies = append(threads[i].Replies, note)
+		}
+	}
+	render(c, http.StatusOK, threads)
+}
+
+// CreateNote adds a note, or a reply when parent_id is set. Staff notes are
+// internal unless stated otherwise; applicants can only write
+// applicant-visible notes.
+func (h *NoteHandler) CreateNote(c *gin.Context) {
+	app, principal, ok := h.application(c)
+	if !ok {
+		return
+	}
+	var req noteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+	visibility, ok := noteVisibility(c, req.Visibility, principal)
+	if !ok {
+		return
+	}
+
+	note := model.Note{
+		ApplicationID: app.ID,
+		Author:        principal.Subject,
+		AuthorRole:    principal.Role,
+		Body:          req.Body,
+		Visibility:    visibility,
+		Mentions:      model.ParseMentions(req.Body),
+	}
+	if req.ParentID != nil {
+		parent, found := h.notes(principal).GetNote(*req.ParentID)
+		if !found || parent.ApplicationID != app.ID || !principal.CanViewNote(app, parent) {
+			apperror.Respond(c, apperror.ErrNoteNotFound.WithDetail("Note %d does not exist on this application", *req.ParentID))
+			return
+		}
+		// Replies to a reply join the same thread.
+		if parent.ParentID != nil {
+			parent, _ = h.notes(principal).GetNote(*parent.ParentID)
+		}
+		if !validReplyVisibility(c, parent, visibility) {
+			return
+		}
+		note.ParentID = &parent.ID
+	}
+
+	note = h.notes(principal).SaveNote(note)
+	h.publishNote(app, note)
+	render(c, http.StatusCreated, noteView(note, principal))
+}
+
+// UpdateNote edits a note's body or visibility. Only the author can edit a
+// note; the previous version is kept in its history.
+func (h *NoteHandler) UpdateNote(c *gin.Context) {
+	app, principal, ok := h.application(c)
+	if !ok {
+		return
+	}
+	noteID, ok := parseIDParam(c, "noteId", "Note ID")
+	if !ok {
+		return
+	}
+	note, found := h.notes(principal).GetNote(noteID)
+	if !found || note.ApplicationID != app.ID || !principal.CanViewNote(app, note) {
+		apperror.Respond(c, apperror.ErrNoteNotFound)
+		return
+	}
+	if note.Author != principal.Subject {
+		apperror.Respond(c, apperror.ErrForbidden.WithDetail("Only the author can edit a note"))
+		return
+	}
+
+	var req noteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+	if req.ParentID != nil {
+		apperror.Respond(c, apperror.ErrInvalidParameter.WithDetail("parent_id cannot be changed"))
+		return
+	}
+	visibility := note.Visibility
+	if req.Visibility != "" {
+		if visibility, ok = noteVisibility(c, req.Visibility, principal); !ok {
+			return
+		}
+	}
+	if note.ParentID != nil {
+		parent, _ := h.notes(principal).GetNote(*note.ParentID)
+		if !validReplyVisibility(c, parent, visibility) {
+			return
+		}
+	}
+
+	note, _ = h.notes(principal).UpdateNote(note.ID, principal.Subject, req.Body, visibility, model.ParseMentions(req.Body))
+	render(c, http.StatusOK, noteView(note, principal))
+}
+
+// MyMentions lists the notes that mention the caller, oldest first.
+func (h *NoteHandler) MyMentions(c *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(c)
+	render(c, http.StatusOK, h.notes(principal).ListMentions(principal.Subject))
+}
+
+func (h *NoteHandler) notes(principal model.Principal) *store.NoteStore {
+	return h.Notes.ForTenant(principal.TenantID())
+}
+
+// application loads the application in the path and checks that the caller
+// may see it. Applications the caller cannot see are reported as missing.
+func (h *NoteHandler) application(c *gin.Context) (model.LoanApplication, model.Principal, bool) {
+	principal, _ := middleware.CurrentPrincipal(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apperror.Respond(c, errInvalidApplicationID)
+		return model.LoanApplication{}, principal, false
+	}
+	app, found := h.Loans.tenantStore(c).GetLoanApplication(id)
+	if !found || !principal.CanViewApplication(app) {
+		apperror.Respond(c, apperror.ErrApplicationNotFound)
+		return app, principal, false
+	}
+	return app, principal, true
+}
+
+func (h *NoteHandler) publishNote(app model.LoanApplication, note model.Note) {
+	h.Loans.publish(model.EventNoteAdded, app, map[string]string{
+		"note_id":    strconv.Itoa(note.ID),
+		"author":     note.Author,
+		"visibility": note.Visibility,
+		"mentions":   strings.Join(note.Mentions, ","),
+	})
+}
+
+// noteVisibility applies the default visibility for the caller's role and
+// keeps applicants from writing internal notes.
+func noteVisibility(c *gin.Context, requested string, principal model.Principal) (string, bool) {
+	switch {
+	case requested == "" && principal.IsStaff():
+		return model.NoteInternal, true
+	case requested == "":
+		return model.NoteApplicant, true
+	case requested == model.NoteInternal && !principal.IsStaff():
+		apperror.Respond(c, apperror.ErrForbidden.WithDetail("Applicants can only write applicant-visible notes"))
+		return "", false
+	}
+	return requested, true
+}
+
+func validReplyVisibility(c *gin.Context, parent model.Note, visibility string) bool {
+	if parent.Visibility == model.NoteInternal && visibility == model.NoteApplicant {
+		apperror.Respond(c, apperror.ErrInvalidParameter.WithDetail("Replies to an internal note must be internal"))
+		return false
+	}
+	return true
+}
+
+// noteView hides the edit history from applicants, since earlier versions may
+// have been internal.
+func noteView(note model.Note, principal model.Principal) model.Note {
+	if !principal.IsStaff() {
+		note.History = nil
+	}
+	return note
+}
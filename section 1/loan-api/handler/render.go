@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"loan-api/middleware"
+	"loan-api/serializer"
+)
+
+// render writes v as JSON in the shape of the API version the route is
+// mounted under.
+func render(c *gin.Context, status int, v any) {
+	c.JSON(status, serializer.Serialize(middleware.CurrentAPIVersion(c), v))
+}
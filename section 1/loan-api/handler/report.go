@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/apperror"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/report"
+	"loan-api/sla"
+	"loan-api/store"
+)
+
+var groupByValues = []string{model.GroupByDay, model.GroupByWeek, model.GroupByMonth}
+
+type ReportHandler struct {
+	Store *store.MemoryStore
+	SLA   *sla.Sweeper
+}
+
+func NewReportHandler(s *store.MemoryStore) *ReportHandler {
+	return &ReportHandler{Store: s}
+}
+
+func (h *ReportHandler) PipelineReport(c *gin.Context) {
+	groupBy := c.DefaultQuery("group_by", model.GroupByMonth)
+	if !slices.Contains(groupByValues, groupBy) {
+		apperror.Respond(c, apperror.ErrInvalidParameter.WithDetail("group_by must be one of: day, week, month"))
+		return
+	}
+	filter, ok := newApplicationFilter(c)
+	if !ok {
+		return
+	}
+
+	principal, _ := middleware.CurrentPrincipal(c)
+	pipeline := report.NewPipeline(groupBy)
+	h.Store.ForTenant(principal.TenantID()).EachLoanApplication(func(app model.LoanApplication) bool {
+		if filter.allows(app) {
+			pipeline.Add(app)
+		}
+		return true
+	})
+
+	result := pipeline.Report()
+	result.From = c.Query("from")
+	result.To = c.Query("to")
+	result.LoanPurpose = filter.purpose
+	render(c, http.StatusOK, result)
+}
+
+func (h *ReportHandler) SLAReport(c *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(c)
+	render(c, http.StatusOK, h.SLA.Stats(principal.TenantID()))
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/apperror"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/retention"
+	"loan-api/store"
+)
+
+type RetentionHandler struct {
+	Loans  *LoanHandler
+	Purger *retention.Purger
+}
+
+func NewRetentionHandler(loans *LoanHandler, purger *retention.Purger) *RetentionHandler {
+	return &RetentionHandler{Loans: loans, Purger: purger}
+}
+
+type erasureRequest struct {
+	Subject      string `json:"subject" binding:"required_without=ApplicantSSN"`
+	ApplicantSSN string `json:"applicant_ssn" binding:"required_without=Subject"`
+	Reason       string `json:"reason" binding:"max=500"`
+}
+
+type legalHoldRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// EraseSubject anonymizes every application of the caller's tenant that was
+// submitted by subject or is for applicant_ssn, in any format. Applications on
+// legal hold are left untouched and listed as held; those that could not be
+// anonymized for another reason are listed as failed.
+func (h *RetentionHandler) EraseSubject(c *gin.Context) {
+	var req erasureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+	principal, _ := middleware.CurrentPrincipal(c)
+	subject, ssn := strings.TrimSpace(req.Subject), strings.TrimSpace(req.ApplicantSSN)
+	reason := req.Reason
+	if reason == "" {
+		reason = "erasure request"
+	}
+
+	tenantStore := h.Loans.tenantStore(c)
+	var ids []int
+	if subject != "" {
+		tenantStore.EachLoanApplication(func(app model.LoanApplication) bool {
+			if app.SubmittedBy == subject {
+				ids = append(ids, app.ID)
+			}
+			return true
+		})
+	}
+	if ssn != "" {
+		ids = append(ids, tenantStore.ApplicationsForSSN(ssn)...)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	result := model.ErasureResult{Erased: []int{}, Held: []int{}, Failed: []int{}}
+	for _, id := range ids {
+		_, err := h.Purger.Anonymize(principal.TenantID(), id, principal, reason)
+		switch {
+		case err == nil:
+			result.Erased = append(result.Erased, id)
+		case errors.Is(err, store.ErrLegalHold):
+			result.Held = append(result.Held, id)
+		default:
+			log.Printf("Retention - failed to erase application %d: %v", id, err)
+			result.Failed = append(result.Failed, id)
+		}
+	}
+	render(c, http.StatusOK, result)
+}
+
+// PlaceLegalHold keeps the application from being deleted or anonymized
+// until the hold is lifted.
+func (h *RetentionHandler) PlaceLegalHold(c *gin.Context) {
+	var req legalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return
+	}
+	principal, _ := middleware.CurrentPrincipal(c)
+	h.setLegalHold(c, &model.LegalHold{Reason: req.Reason, PlacedBy: principal.Subject, PlacedAt: time.Now()})
+}
+
+func (h *RetentionHandler) LiftLegalHold(c *gin.Context) {
+	h.setLegalHold(c, nil)
+}
+
+func (h *RetentionHandler) setLegalHold(c *gin.Context, hold *model.LegalHold) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apperror.Respond(c, errInvalidApplicationID)
+		return
+	}
+	app, found := h.Loans.tenantStore(c).SetLegalHold(id, hold)
+	if !found {
+		apperror.Respond(c, apperror.ErrApplicationNotFound)
+		return
+	}
+
+	principal, _ := middleware.CurrentPrincipal(c)
+	entry := model.AuditEntry{
+		Actor:   principal.Subject,
+		Role:    principal.Role,
+		Action:  model.AuditLegalHoldLifted,
+		Details: map[string]string{"application_id": strconv.Itoa(id)},
+	}
+	if hold != nil {
+		entry.Action = model.AuditLegalHoldPlaced
+		entry.Details["reason"] = hold.Reason
+	}
+	h.Purger.Audit.ForTenant(principal.TenantID()).Record(entry)
+	render(c, http.StatusOK, model.GetMaskedApplication(app, principal))
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"loan-api/apperror"
+	"loan-api/events"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/serializer"
+)
+
+const heartbeatInterval = 15 * time.Second
+
+type StreamHandler struct {
+	Stream   *events.Stream
+	upgrader websocket.Upgrader
+	done     chan struct{}
+	once     sync.Once
+}
+
+func NewStreamHandler(s *events.Stream) *StreamHandler {
+	return &StreamHandler{Stream: s, done: make(chan struct{})}
+}
+
+// Shutdown ends every open stream. Streams never become idle on their own, so
+// this must run when the HTTP server shuts down (see http.Server.RegisterOnShutdown).
+func (h *StreamHandler) Shutdown() {
+	h.once.Do(func() { close(h.done) })
+}
+
+// StreamEvents pushes application events as Server-Sent Events. Clients
+// resume with the Last-Event-ID header (or last_event_id query parameter).
+// Events are shaped by the API version of the route.
+func (h *StreamHandler) StreamEvents(c *gin.Context) {
+	filter, ok := newEventFilter(c)
+	if !ok {
+		return
+	}
+	version := middleware.CurrentAPIVersion(c)
+
+	backlog, gap, live, cancel := h.Stream.Subscribe(filter.lastEventID)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if gap != nil {
+		// Without an id, the gap does not move the client's Last-Event-ID.
+		data, _ := json.Marshal(gap)
+		fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", gap.Type, data)
+	}
+	for _, event := range backlog {
+		if filter.allows(event) {
+			writeSSE(c.Writer, version, filter.visible(event))
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-h.done:
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		case event, open := <-live:
+			if !open {
+				return
+			}
+			if filter.allows(event) {
+				writeSSE(c.Writer, version, filter.visible(event))
+				c.Writer.Flush()
+			}
+		}
+	}
+}
+
+// StreamEventsWebSocket sends the same events as StreamEvents, one JSON
+// message per event, over a WebSocket connection.
+func (h *StreamHandler) StreamEventsWebSocket(c *gin.Context) {
+	filter, ok := newEventFilter(c)
+	if !ok {
+		return
+	}
+	version := middleware.CurrentAPIVersion(c)
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already written the error response.
+		return
+	}
+	defer conn.Close()
+
+	backlog, gap, live, cancel := h.Stream.Subscribe(filter.lastEventID)
+	defer cancel()
+
+	// Reading is required to process control frames and detect closed sockets.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	if gap != nil {
+		if err := conn.WriteJSON(gap); err != nil {
+			return
+		}
+	}
+	for _, event := range backlog {
+		if filter.allows(event) {
+			if err := conn.WriteJSON(serializer.Serialize(version, filter.visible(event))); err != nil {
+				return
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-h.done:
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+			return
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+				return
+			}
+		case event, open := <-live:
+			if !open {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "listener fell behind"))
+				return
+			}
+			if filter.allows(event) {
+				if err := conn.WriteJSON(serializer.Serialize(version, filter.visible(event))); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+type eventFilter struct {
+	principal     model.Principal
+	lastEventID   int64
+	types         map[string]bool
+	applicationID int
+}
+
+func newEventFilter(c *gin.Context) (eventFilter, bool) {
+	principal, _ := middleware.CurrentPrincipal(c)
+	filter := eventFilter{principal: principal}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	if lastEventID != "" {
+		id, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			apperror.Respond(c, apperror.ErrInvalidParameter.WithDetail("Last-Event-ID must be an integer"))
+			return filter, false
+		}
+		filter.lastEventID = id
+	}
+
+	if types := c.Query("types"); types != "" {
+		filter.types = make(map[string]bool)
+		for _, eventType := range strings.Split(types, ",") {
+			if !model.IsValidEventType(eventType) {
+				apperror.Respond(c, apperror.ErrInvalidEventType.WithDetail("Types must be any of: %s", strings.Join(model.EventTypes, ", ")))
+				return filter, false
+			}
+			filter.types[eventType] = true
+		}
+	}
+
+	if appID := c.Query("application_id"); appID != "" {
+		id, err := strconv.Atoi(appID)
+		if err != nil {
+			apperror.Respond(c, errInvalidApplicationID)
+			return filter, false
+		}
+		filter.applicationID = id
+	}
+	return filter, true
+}
+
+func (f eventFilter) allows(event model.Event) bool {
+	if f.types != nil && !f.types[event.Type] {
+		return false
+	}
+	if f.applicationID != 0 && event.ApplicationID != f.applicationID {
+		return false
+	}
+	return f.principal.CanViewEvent(event.Application, event.Type, event.Data)
+}
+
+// visible returns event with its application and data as the listener may
+// see them.
+func (f eventFilter) visible(event model.Event) model.Event {
+	event.Application = model.GetMaskedApplication(event.Application, f.principal)
+	event.Data = f.principal.VisibleEventData(event.Data)
+	return event
+}
+
+func writeSSE(w gin.ResponseWriter, version string, event model.Event) {
+	data, err := json.Marshal(serializer.Serialize(version, event))
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/store"
+	"loan-api/validator"
+)
+
+// tenantStore returns the store of the caller's tenant. Applications of other
+// tenants are not in it, so they are reported as not found.
+func (h *LoanHandler) tenantStore(c *gin.Context) *store.MemoryStore {
+	principal, _ := middleware.CurrentPrincipal(c)
+	return h.Store.ForTenant(principal.TenantID())
+}
+
+// settings returns the tenant's settings with the deployment defaults filled in.
+func (h *LoanHandler) settings(tenant string) model.TenantSettings {
+	settings := h.Tenants[model.TenantID(tenant)]
+	if settings.Statuses == nil {
+		settings.Statuses = model.Statuses
+	}
+	if settings.Checklists == nil {
+		settings.Checklists = h.Checklists
+	}
+	return settings
+}
+
+// validateForTenant applies the tenant's loan amount limits, which narrow the
+// limits of the binding tags.
+func (h *LoanHandler) validateForTenant(app model.LoanApplication, tenant, acceptLanguage string) []model.FieldError {
+	settings := h.settings(tenant)
+	trans := validator.Translator(acceptLanguage)
+	switch {
+	case settings.MinLoanAmount > 0 && app.LoanAmount < settings.MinLoanAmount:
+		return []model.FieldError{validator.NewFieldError(trans, "loan_amount", validator.CodeTooSmall, validator.KeyBelowTenantMinimum)}
+	case settings.MaxLoanAmount > 0 && app.LoanAmount > settings.MaxLoanAmount:
+		return []model.FieldError{validator.NewFieldError(trans, "loan_amount", validator.CodeTooLarge, validator.KeyAboveTenantMaximum)}
+	}
+	return nil
+}
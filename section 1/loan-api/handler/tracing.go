@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"loan-api/tracing"
+)
+
+// storeCallSpan starts a span around a call the handler makes into the
+// in-memory store on behalf of the request in ctx. The store itself is not
+// instrumented, so the span times the call as the handler sees it, lock waits
+// included. Callers end it as soon as the store returns; id is 0 for calls
+// that are not about a single application.
+func storeCallSpan(ctx context.Context, tenant, operation string, id int) trace.Span {
+	attrs := []attribute.KeyValue{
+		attribute.String("loan.store_operation", operation),
+		attribute.String("loan.tenant", tenant),
+	}
+	if id != 0 {
+		attrs = append(attrs, attribute.Int("loan.application_id", id))
+	}
+	_, span := tracing.Tracer().Start(ctx, "store.call "+operation, trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(attrs...))
+	return span
+}
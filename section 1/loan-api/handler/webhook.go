@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/apperror"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/store"
+	"loan-api/validator"
+	"loan-api/webhook"
+)
+
+type WebhookHandler struct {
+	Store      *store.WebhookStore
+	Dispatcher *webhook.Dispatcher
+}
+
+func NewWebhookHandler(s *store.WebhookStore, d *webhook.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{Store: s, Dispatcher: d}
+}
+
+type webhookRequest struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+func (r webhookRequest) toSubscription() model.WebhookSubscription {
+	sub := model.WebhookSubscription{
+		URL:    r.URL,
+		Events: r.Events,
+		Secret: r.Secret,
+		Active: true,
+	}
+	if r.Active != nil {
+		sub.Active = *r.Active
+	}
+	if sub.Events == nil {
+		sub.Events = []string{}
+	}
+	return sub
+}
+
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	req, ok := h.bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	sub := req.toSubscription()
+	if sub.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			apperror.Respond(c, apperror.ErrInternal.WithDetail("Unable to generate webhook secret").Wrap(err))
+			return
+		}
+		sub.Secret = secret
+	}
+
+	created := h.tenantStore(c).SaveSubscription(sub)
+
+	// The secret is only ever returned on creation.
+	render(c, http.StatusCreated, created)
+}
+
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	result := []model.WebhookSubscription{}
+	for _, sub := range h.tenantStore(c).ListSubscriptions() {
+		result = append(result, model.GetRedactedSubscription(sub))
+	}
+	render(c, http.StatusOK, result)
+}
+
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Webhook ID")
+	if !ok {
+		return
+	}
+
+	sub, found := h.tenantStore(c).GetSubscription(id)
+	if !found {
+		apperror.Respond(c, apperror.ErrWebhookNotFound)
+		return
+	}
+	render(c, http.StatusOK, model.GetRedactedSubscription(sub))
+}
+
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Webhook ID")
+	if !ok {
+		return
+	}
+	req, ok := h.bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	updated, found := h.tenantStore(c).UpdateSubscription(id, req.toSubscription())
+	if !found {
+		apperror.Respond(c, apperror.ErrWebhookNotFound)
+		return
+	}
+	render(c, http.StatusOK, model.GetRedactedSubscription(updated))
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Webhook ID")
+	if !ok {
+		return
+	}
+
+	if !h.tenantStore(c).DeleteSubscription(id) {
+		apperror.Respond(c, apperror.ErrWebhookNotFound)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Webhook ID")
+	if !ok {
+		return
+	}
+
+	if _, found := h.tenantStore(c).GetSubscription(id); !found {
+		apperror.Respond(c, apperror.ErrWebhookNotFound)
+		return
+	}
+	render(c, http.StatusOK, h.tenantStore(c).ListDeliveries(id))
+}
+
+func (h *WebhookHandler) RedeliverDelivery(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Webhook ID")
+	if !ok {
+		return
+	}
+	deliveryID, ok := parseIDParam(c, "deliveryId", "Delivery ID")
+	if !ok {
+		return
+	}
+
+	principal, _ := middleware.CurrentPrincipal(c)
+	delivery, err := h.Dispatcher.Redeliver(principal.TenantID(), id, deliveryID)
+	switch {
+	case errors.Is(err, webhook.ErrDeliveryNotFound), errors.Is(err, webhook.ErrSubscriptionNotFound):
+		apperror.Respond(c, apperror.ErrDeliveryNotFound)
+		return
+	case errors.Is(err, webhook.ErrDeliveryInProgress):
+		apperror.Respond(c, apperror.ErrDeliveryInProgress)
+		return
+	case err != nil:
+		apperror.Respond(c, apperror.ErrInternal.WithDetail("Unable to redeliver webhook").Wrap(err))
+		return
+	}
+	render(c, http.StatusAccepted, delivery)
+}
+
+// tenantStore returns the subscriptions of the caller's tenant.
+func (h *WebhookHandler) tenantStore(c *gin.Context) *store.WebhookStore {
+	principal, _ := middleware.CurrentPrincipal(c)
+	return h.Store.ForTenant(principal.TenantID())
+}
+
+func (h *WebhookHandler) bindWebhookRequest(c *gin.Context) (webhookRequest, bool) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, err)
+		return req, false
+	}
+	// Deliveries are sent from inside the deployment, so the URL must not
+	// reach its internal services or the cloud metadata endpoint.
+	if err := h.Dispatcher.CheckTarget(c.Request.Context(), req.URL); err != nil {
+		trans := validator.Translator(c.GetHeader("Accept-Language"))
+		key := validator.KeyPrivateTarget
+		if !errors.Is(err, webhook.ErrPrivateTarget) {
+			key = "url"
+		}
+		c.Header("Content-Language", trans.Locale())
+		apperror.Respond(c, apperror.ErrValidation.WithFields([]model.FieldError{
+			validator.NewFieldError(trans, "url", validator.CodeInvalidURL, key),
+		}))
+		return req, false
+	}
+
+	for _, eventType := range req.Events {
+		if !model.IsValidEventType(eventType) {
+			apperror.Respond(c, apperror.ErrInvalidEventType.WithDetail("Events must be any of: %s", strings.Join(model.EventTypes, ", ")))
+			return req, false
+		}
+	}
+	return req, true
+}
+
+func parseIDParam(c *gin.Context, name, label string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		apperror.Respond(c, apperror.ErrInvalidID.WithDetail("%s must be an integer", label))
+		return 0, false
+	}
+	return id, true
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,25 @@
+package health
+
+import (
+	"context"
+
+	"loan-api/store"
+)
+
+// Store pings the application store and reports how many tenants and
+// applications it holds.
+func Store(s *store.MemoryStore) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		if err := s.Ping(ctx); err != nil {
+			return nil, err
+		}
+		tenants := s.Tenants()
+		applications := 0
+		for _, tenantStore := range tenants {
+			for _, count := range tenantStore.CountByStatus() {
+				applications += count
+			}
+		}
+		return map[string]any{"tenants": len(tenants), "applications": applications}, nil
+	}
+}
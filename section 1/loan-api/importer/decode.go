@@ -0,0 +1,179 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"loan-api/model"
+)
+
+// ErrTooManyRows is returned when a file has more data rows than allowed.
+var ErrTooManyRows = errors.New("import exceeds the maximum number of rows")
+
+const maxLineSize = 1 << 20
+
+// Row is one decoded application. Err is set when the row could not be
+// decoded; a *json.UnmarshalTypeError names the offending field.
+type Row struct {
+	Line        int
+	Application model.LoanApplication
+	Err         error
+}
+
+// FormatFromContentType maps a request Content-Type to an import format.
+func FormatFromContentType(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return model.ImportFormatCSV, true
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return model.ImportFormatNDJSON, true
+	default:
+		return "", false
+	}
+}
+
+// Decode reads every row of an import file. A maxRows of zero means no limit.
+func Decode(r io.Reader, format string, maxRows int) ([]Row, error) {
+	switch format {
+	case model.ImportFormatCSV:
+		return decodeCSV(r, maxRows)
+	case model.ImportFormatNDJSON:
+		return decodeNDJSON(r, maxRows)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+// csvColumns sets an application field from a CSV cell. Columns use the JSON
+// field names so both formats share one vocabulary.
+var csvColumns = map[string]func(app *model.LoanApplication, value string) error{
+	"applicant_name": func(app *model.LoanApplication, v string) error { app.ApplicantName = v; return nil },
+	"applicant_ssn":  func(app *model.LoanApplication, v string) error { app.ApplicantSSN = v; return nil },
+	"country":        func(app *model.LoanApplication, v string) error { app.Country = v; return nil },
+	"tax_id":         func(app *model.LoanApplication, v string) error { app.TaxID = v; return nil },
+	"loan_purpose":   func(app *model.LoanApplication, v string) error { app.LoanPurpose = v; return nil },
+	"loan_amount":    floatColumn("loan_amount", func(app *model.LoanApplication) *float64 { return &app.LoanAmount }),
+	"annual_income":  floatColumn("annual_income", func(app *model.LoanApplication) *float64 { return &app.AnnualIncome }),
+	"credit_score": func(app *model.LoanApplication, v string) error {
+		if v == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return &json.UnmarshalTypeError{Value: "string", Type: reflect.TypeOf(0), Field: "credit_score"}
+		}
+		app.CreditScore = n
+		return nil
+	},
+}
+
+func floatColumn(name string, field func(*model.LoanApplication) *float64) func(*model.LoanApplication, string) error {
+	return func(app *model.LoanApplication, v string) error {
+		if v == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return &json.UnmarshalTypeError{Value: "string", Type: reflect.TypeOf(0.0), Field: name}
+		}
+		*field(app) = f
+		return nil
+	}
+}
+
+func decodeCSV(r io.Reader, maxRows int) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []Row{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	setters := make([]func(*model.LoanApplication, string) error, len(header))
+	seen := make(map[string]bool)
+	for i, name := range header {
+		// Spreadsheet exports often start with a UTF-8 byte order mark.
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		setter, ok := csvColumns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate CSV column %q", name)
+		}
+		seen[name] = true
+		setters[i] = setter
+	}
+
+	rows := []Row{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if maxRows > 0 && len(rows) == maxRows {
+			return nil, ErrTooManyRows
+		}
+		line, _ := reader.FieldPos(0)
+		row := Row{Line: line}
+		if err != nil {
+			if !errors.Is(err, csv.ErrFieldCount) {
+				return nil, fmt.Errorf("invalid CSV: %w", err)
+			}
+			row.Err = fmt.Errorf("expected %d columns, got %d", len(header), len(record))
+			rows = append(rows, row)
+			continue
+		}
+		for i, value := range record {
+			if err := setters[i](&row.Application, strings.TrimSpace(value)); err != nil && row.Err == nil {
+				row.Err = err
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+func decodeNDJSON(r io.Reader, maxRows int) ([]Row, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	rows := []Row{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if maxRows > 0 && len(rows) == maxRows {
+			return nil, ErrTooManyRows
+		}
+		// Rows are read like POST /loan-applications bodies, so fields the
+		// server owns, such as legal_hold or anonymized_at, are ignored.
+		row := Row{Line: line}
+		var input model.LoanApplicationInput
+		row.Err = json.Unmarshal(data, &input)
+		row.Application = input.Application()
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid NDJSON: %w", err)
+	}
+	return rows, nil
+}
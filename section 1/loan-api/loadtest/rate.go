@@ -0,0 +1,60 @@
+package loadtest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is a number of requests per period, such as 5000 a day.
+type Rate struct {
+	Count int
+	Per   time.Duration
+}
+
+var ratePeriods = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+}
+
+// ParseRate parses a rate written as count/period, where the period is s, m,
+// h or d: "5000/d", "120/m" or "2/s". A count of 0 disables a scenario.
+func ParseRate(s string) (Rate, error) {
+	count, period, found := strings.Cut(s, "/")
+	per, known := ratePeriods[period]
+	n, err := strconv.Atoi(count)
+	if !found || !known || err != nil || n < 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q, want count/period with period s, m, h or d", s)
+	}
+	return Rate{Count: n, Per: per}, nil
+}
+
+// Interval returns the time between two requests, or 0 when the rate is 0.
+func (r Rate) Interval() time.Duration {
+	if r.Count == 0 {
+		return 0
+	}
+	return r.Per / time.Duration(r.Count)
+}
+
+func (r Rate) String() string {
+	for name, per := range ratePeriods {
+		if per == r.Per {
+			return fmt.Sprintf("%d/%s", r.Count, name)
+		}
+	}
+	return fmt.Sprintf("%d/%s", r.Count, r.Per)
+}
+
+// Set implements flag.Value.
+func (r *Rate) Set(s string) error {
+	parsed, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
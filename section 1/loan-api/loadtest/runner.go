@@ -0,0 +1,187 @@
+// Package loadtest replays requests at fixed rates and reports their latency
+// percentiles and error rates.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Scenario is one kind of request, sent at Rate. Do sends request i and
+// returns an error when it fails.
+type Scenario struct {
+	Name string
+	Rate Rate
+	Do   func(ctx context.Context, i int) error
+}
+
+// StatusError is returned by a scenario when the server answers with an
+// unexpected HTTP status. Failures are grouped by status in the report.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.Code)
+}
+
+// Runner sends each scenario's requests on schedule for Duration, whether or
+// not earlier requests have finished, as real applicants would. Requests due
+// while MaxInFlight requests of the scenario are still running are dropped
+// and counted, so an overloaded target shows up in the report instead of
+// slowing the schedule down.
+type Runner struct {
+	Duration    time.Duration
+	MaxInFlight int
+	// Timeout bounds each request; 0 means no limit.
+	Timeout time.Duration
+}
+
+// Report holds the results of one run.
+type Report struct {
+	Duration  time.Duration `json:"duration"`
+	Scenarios []Result      `json:"scenarios"`
+}
+
+// Result holds the results of one scenario. ErrorRate counts both failed and
+// dropped requests. Latencies are in milliseconds and only cover requests
+// that succeeded.
+type Result struct {
+	Name       string         `json:"name"`
+	Rate       string         `json:"rate"`
+	Sent       int            `json:"sent"`
+	Succeeded  int            `json:"succeeded"`
+	Failed     int            `json:"failed"`
+	Dropped    int            `json:"dropped"`
+	ErrorRate  float64        `json:"error_rate"`
+	Throughput float64        `json:"throughput_per_second"`
+	P50        float64        `json:"p50_ms"`
+	P90        float64        `json:"p90_ms"`
+	P95        float64        `json:"p95_ms"`
+	P99        float64        `json:"p99_ms"`
+	Max        float64        `json:"max_ms"`
+	Errors     map[string]int `json:"errors,omitempty"`
+}
+
+// Run runs the scenarios side by side until Duration has passed or ctx is
+// cancelled, waits for the requests still in flight and reports the results.
+func (r *Runner) Run(ctx context.Context, scenarios []Scenario) Report {
+	start := time.Now()
+	runCtx, cancel := context.WithTimeout(ctx, r.Duration)
+	defer cancel()
+
+	results := make([]Result, len(scenarios))
+	var wg sync.WaitGroup
+	for i, s := range scenarios {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.runScenario(runCtx, ctx, s)
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	for i := range results {
+		results[i].Throughput = float64(results[i].Succeeded) / elapsed.Seconds()
+	}
+	return Report{Duration: elapsed, Scenarios: results}
+}
+
+// runScenario schedules s until scheduleCtx is done. Requests run on
+// requestCtx, so the last ones are allowed to finish.
+func (r *Runner) runScenario(scheduleCtx, requestCtx context.Context, s Scenario) Result {
+	result := Result{Name: s.Name, Rate: s.Rate.String(), Errors: map[string]int{}}
+	interval := s.Rate.Interval()
+	if interval == 0 {
+		return result
+	}
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		wg        sync.WaitGroup
+	)
+	slots := make(chan struct{}, max(r.MaxInFlight, 1))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		select {
+		case slots <- struct{}{}:
+			result.Sent++
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				ctx := requestCtx
+				if r.Timeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+					defer cancel()
+				}
+				started := time.Now()
+				err := s.Do(ctx, i)
+				elapsed := time.Since(started)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					result.Failed++
+					result.Errors[classify(err)]++
+					return
+				}
+				result.Succeeded++
+				latencies = append(latencies, elapsed)
+			}()
+		default:
+			result.Dropped++
+		}
+
+		select {
+		case <-scheduleCtx.Done():
+			wg.Wait()
+			if total := result.Sent + result.Dropped; total > 0 {
+				result.ErrorRate = float64(result.Failed+result.Dropped) / float64(total)
+			}
+			slices.Sort(latencies)
+			result.P50, result.P90, result.P95, result.P99, result.Max =
+				percentile(latencies, 50), percentile(latencies, 90), percentile(latencies, 95), percentile(latencies, 99), percentile(latencies, 100)
+			return result
+		case <-ticker.C:
+		}
+	}
+}
+
+// classify names the kind of failure: the HTTP status, a timeout or a
+// transport error.
+func classify(err error) string {
+	var statusErr *StatusError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &statusErr):
+		return fmt.Sprintf("http_%d", statusErr.Code)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "cancelled"
+	default:
+		return "transport"
+	}
+}
+
+// percentile returns the p-th percentile of the sorted latencies in
+// milliseconds, using the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return float64(sorted[max(rank, 1)-1]) / float64(time.Millisecond)
+}
@@ -1,26 +1,212 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"loan-api/config"
+	"loan-api/events"
 	"loan-api/handler"
+	"loan-api/health"
+	"loan-api/metrics"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/retention"
 	"loan-api/routes"
+	"loan-api/sla"
 	"loan-api/store"
+	"loan-api/tracing"
+	"loan-api/webhook"
 )
 
 func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	for _, t := range cfg.Auth.Tokens {
+		middleware.RegisterToken(t.Token, model.Principal{Subject: t.Subject, Role: t.Role, Permissions: t.Permissions, Tenant: t.Tenant})
+	}
+	if err := os.MkdirAll(cfg.Uploads.Dir, 0o750); err != nil {
+		log.Fatalf("Unable to create upload directory: %v", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.Endpoint)
+	if err != nil {
+		log.Fatalf("Unable to set up tracing: %v", err)
+	}
+
 	router := gin.New()
 
 	memStore := store.NewMemoryStore()
+	if cfg.Duplicates.SSNSecret != "" {
+		memStore = store.NewMemoryStoreWithSSNSecret([]byte(cfg.Duplicates.SSNSecret))
+	}
+	webhookStore := store.NewWebhookStoreWithDeliveryLog(cfg.Webhooks.DeliveryLogSize)
+	importStore := store.NewImportStore()
+	auditStore := store.NewAuditStore()
+	noteStore := store.NewNoteStore()
+	historyStore := store.NewHistoryStore()
+
+	bus := events.NewBus()
+	dispatcher := webhook.NewDispatcher(webhookStore)
+	dispatcher.MaxAttempts = cfg.Webhooks.MaxAttempts
+	dispatcher.BaseBackoff = cfg.Webhooks.BaseBackoff.Duration
+	dispatcher.Client.Timeout = cfg.Webhooks.RequestTimeout.Duration
+	dispatcher.AllowPrivateTargets = cfg.Webhooks.AllowPrivateTargets
+	bus.Subscribe(dispatcher.HandleEvent)
+	stream := events.NewStream(cfg.Events.LogSize)
+	bus.Subscribe(stream.HandleEvent)
+	bus.Subscribe(historyStore.HandleEvent)
+
+	calendar, err := cfg.SLA.Calendar()
+	if err != nil {
+		log.Fatalf("Invalid SLA calendar: %v", err)
+	}
+	sweeper := sla.NewSweeper(memStore, bus, calendar)
+	sweeper.Deadlines = cfg.SLA.Deadlines
+	sweeper.Warning = cfg.SLA.Warning.Duration
+
+	purger := retention.NewPurger(memStore, noteStore, auditStore, bus)
+	purger.Rules = cfg.Retention.Rules
+
+	loanHandler := handler.NewLoanHandler(memStore, bus)
+	loanHandler.UploadDir = cfg.Uploads.Dir
+	loanHandler.MaxDocumentBytes = int64(cfg.Uploads.MaxBytes)
+	loanHandler.Checklists = cfg.Checklists
+	loanHandler.Tenants = cfg.Tenants
+	loanHandler.History = historyStore
+	loanHandler.Duplicates = cfg.Duplicates.Rule()
+	webhookHandler := handler.NewWebhookHandler(webhookStore, dispatcher)
+	streamHandler := handler.NewStreamHandler(stream)
+	importHandler := handler.NewImportHandler(loanHandler, importStore, cfg.Imports.Limits())
+	assignmentHandler := handler.NewAssignmentHandler(loanHandler, cfg.Assignment.Officers)
+	assignmentHandler.AutoAssign = cfg.Assignment.AutoAssign
+	bus.Subscribe(assignmentHandler.HandleEvent)
+	reportHandler := handler.NewReportHandler(memStore)
+	reportHandler.SLA = sweeper
+
+	checker := health.NewChecker()
+	checker.Register("store", health.Store(memStore))
+	checker.Register("uploads", health.Writable(cfg.Uploads.Dir))
+
+	routes.SetupRoutes(router, routes.Handlers{
+		Loan:       loanHandler,
+		Webhook:    webhookHandler,
+		Stream:     streamHandler,
+		Import:     importHandler,
+		Export:     handler.NewExportHandler(memStore, auditStore),
+		Audit:      handler.NewAuditHandler(auditStore),
+		Report:     reportHandler,
+		Assignment: assignmentHandler,
+		Note:       handler.NewNoteHandler(loanHandler, noteStore),
+		Retention:  handler.NewRetentionHandler(loanHandler, purger),
+		Health:     handler.NewHealthHandler(checker),
+	})
 
-	loanHandler := handler.NewLoanHandler(memStore)
+	server := &http.Server{
+		Addr:    ":" + strconv.Itoa(cfg.Server.Port),
+		Handler: router,
+	}
+	server.RegisterOnShutdown(streamHandler.Shutdown)
+
+	// Metrics are served on their own listener so they stay reachable without
+	// a token and can be kept off the public network.
+	var metricsServer *http.Server
+	if cfg.Metrics.Port != 0 {
+		prometheus.MustRegister(metrics.NewCollector(memStore, sweeper))
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		metricsServer = &http.Server{
+			Addr:    ":" + strconv.Itoa(cfg.Metrics.Port),
+			Handler: mux,
+		}
+	}
+
+	// gRPC gets its own listener because it needs HTTP/2 without TLS, which
+	// the REST server does not speak.
+	var grpcServer *grpc.Server
+	var grpcListener net.Listener
+	if cfg.GRPC.Port != 0 {
+		grpcServer = routes.NewGRPCServer(handler.NewLoanGRPCServer(loanHandler))
+		grpcListener, err = net.Listen("tcp", ":"+strconv.Itoa(cfg.GRPC.Port))
+		if err != nil {
+			log.Fatalf("gRPC server failed to listen: %v", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	routes.SetupRoutes(router, loanHandler)
+	go sweeper.Run(ctx, cfg.SLA.SweepInterval.Duration)
+	go purger.Run(ctx, cfg.Retention.SweepInterval.Duration)
+
+	if metricsServer != nil {
+		go func() {
+			log.Printf("Metrics server starting on %s", metricsServer.Addr)
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("Metrics server failed to start: %v", err)
+			}
+		}()
+	}
 
-	port := "8080"
-	log.Printf("Server starting on :%s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	if grpcServer != nil {
+		go func() {
+			log.Printf("gRPC server starting on %s", grpcListener.Addr())
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				log.Fatalf("gRPC server failed: %v", err)
+			}
+		}()
+	}
+
+	go func() {
+		log.Printf("Server starting on %s", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed to start: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Printf("Shutting down, draining requests for up to %s", cfg.Server.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown incomplete: %v", err)
+	}
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			log.Println("gRPC server shutdown incomplete, closing open streams")
+			grpcServer.Stop()
+		}
+	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Metrics server shutdown incomplete: %v", err)
+		}
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("Unable to flush traces: %v", err)
 	}
+	log.Println("Server stopped")
 }
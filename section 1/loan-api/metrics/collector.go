@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"loan-api/model"
+	"loan-api/sla"
+	"loan-api/store"
+)
+
+var (
+	applicationsDesc = prometheus.NewDesc(namespace+"_applications", "Applications by tenant and status", []string{"tenant", "status"}, nil)
+	slaDesc          = prometheus.NewDesc(namespace+"_sla_applications", "Applications at risk or in breach of their SLA at the last sweep", []string{"tenant", "state"}, nil)
+	slaEventsDesc    = prometheus.NewDesc(namespace+"_sla_events_total", "SLA events published by the sweeper", []string{"tenant", "state"}, nil)
+)
+
+// Collector reports gauges read from the store and the SLA sweeper at scrape
+// time. SLA is optional.
+type Collector struct {
+	Store *store.MemoryStore
+	SLA   *sla.Sweeper
+}
+
+func NewCollector(s *store.MemoryStore, sweeper *sla.Sweeper) *Collector {
+	return &Collector{Store: s, SLA: sweeper}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- applicationsDesc
+	if c.SLA != nil {
+		ch <- slaDesc
+		ch <- slaEventsDesc
+	}
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, tenant := range c.Store.Tenants() {
+		counts := tenant.CountByStatus()
+		// Every status is reported, so a status that empties drops to zero
+		// instead of disappearing.
+		for _, status := range model.Statuses {
+			ch <- prometheus.MustNewConstMetric(applicationsDesc, prometheus.GaugeValue, float64(counts[status]), tenant.Tenant(), status)
+		}
+	}
+
+	if c.SLA == nil {
+		return
+	}
+	for _, tenant := range c.Store.Tenants() {
+		stats := c.SLA.Stats(tenant.Tenant())
+		ch <- prometheus.MustNewConstMetric(slaDesc, prometheus.GaugeValue, float64(stats.AtRisk), tenant.Tenant(), model.SLAAtRisk)
+		ch <- prometheus.MustNewConstMetric(slaDesc, prometheus.GaugeValue, float64(stats.Breached), tenant.Tenant(), model.SLABreached)
+		ch <- prometheus.MustNewConstMetric(slaEventsDesc, prometheus.CounterValue, float64(stats.AtRiskTotal), tenant.Tenant(), model.SLAAtRisk)
+		ch <- prometheus.MustNewConstMetric(slaEventsDesc, prometheus.CounterValue, float64(stats.BreachedTotal), tenant.Tenant(), model.SLABreached)
+	}
+}
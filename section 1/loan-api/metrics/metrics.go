@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "loan_api"
+
+// Upload kinds counted by UploadBytes.
+const (
+	UploadDocument = "document"
+	UploadImport   = "import"
+)
+
+// Reasons counted by AuthFailures.
+const (
+	AuthMissingToken = "missing_token"
+	AuthInvalidToken = "invalid_token"
+	AuthForbidden    = "forbidden"
+)
+
+var (
+	RequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Namespace: namespace, Name: "http_requests_total", Help: "HTTP requests by route template and status"},
+		[]string{"method", "route", "status"})
+	RequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{Namespace: namespace, Name: "http_request_duration_seconds", Help: "HTTP request latency by route template and status", Buckets: prometheus.DefBuckets},
+		[]string{"method", "route", "status"})
+	RequestsInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Namespace: namespace, Name: "http_requests_in_flight", Help: "HTTP requests being served by route template"},
+		[]string{"method", "route"})
+	UploadBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Namespace: namespace, Name: "upload_bytes_total", Help: "Bytes received in document uploads and imports"},
+		[]string{"kind"})
+	AuthFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Namespace: namespace, Name: "auth_failures_total", Help: "Rejected requests by reason"},
+		[]string{"reason"})
+)
+
+func init() {
+	prometheus.MustRegister(RequestsTotal, RequestDuration, RequestsInFlight, UploadBytes, AuthFailures)
+}
+
+// CountUpload returns a reader that adds the bytes read from r to UploadBytes.
+func CountUpload(kind string, r io.Reader) io.Reader {
+	return &countingReader{r: r, counter: UploadBytes.WithLabelValues(kind)}
+}
+
+type countingReader struct {
+	r       io.Reader
+	counter prometheus.Counter
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.counter.Add(float64(n))
+	return n, err
+}
@@ -1,18 +1,82 @@
 package middleware
 
 import (
-	"net/http"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
+	"loan-api/apperror"
+	"loan-api/metrics"
 	"loan-api/model"
 )
 
+const principalKey = "principal"
+
+var (
+	tokens = map[string]model.Principal{
+		"mysecrettoken": {Subject: "admin", Role: model.RoleAdmin},
+	}
+	tokensLock sync.RWMutex
+)
+
+// RegisterToken makes the bearer token authenticate as the given principal.
+func RegisterToken(token string, principal model.Principal) {
+	tokensLock.Lock()
+	defer tokensLock.Unlock()
+	tokens[token] = principal
+}
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token := c.GetHeader("Authorization")
-		if token != "Bearer mysecrettoken" {
-			c.JSON(http.StatusUnauthorized, model.ErrorResponse{Error: "Unauthorized", Details: []string{"Missing or invalid authorization token"}})
-			c.Abort()
+		principal, err := authenticate(c.GetHeader("Authorization"))
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// authenticate looks up the principal of an "Authorization: Bearer" header
+// value and counts failures by reason.
+func authenticate(authorization string) (model.Principal, error) {
+	token, isBearer := strings.CutPrefix(authorization, "Bearer ")
+
+	tokensLock.RLock()
+	principal, found := tokens[token]
+	tokensLock.RUnlock()
+
+	if !isBearer || !found {
+		reason := metrics.AuthInvalidToken
+		if !isBearer || token == "" {
+			reason = metrics.AuthMissingToken
+		}
+		metrics.AuthFailures.WithLabelValues(reason).Inc()
+		return principal, apperror.ErrUnauthorized.WithDetail("Missing or invalid authorization token")
+	}
+	return principal, nil
+}
+
+// CurrentPrincipal returns the principal set by AuthMiddleware.
+func CurrentPrincipal(c *gin.Context) (model.Principal, bool) {
+	value, exists := c.Get(principalKey)
+	if !exists {
+		return model.Principal{}, false
+	}
+	principal, ok := value.(model.Principal)
+	return principal, ok
+}
+
+// RequireRole rejects principals whose role is not one of roles. It must run
+// after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, _ := CurrentPrincipal(c)
+		if !slices.Contains(roles, principal.Role) {
+			metrics.AuthFailures.WithLabelValues(metrics.AuthForbidden).Inc()
+			apperror.Respond(c, apperror.ErrForbidden.WithDetail("Requires one of the roles: %s", strings.Join(roles, ", ")))
 			return
 		}
 		c.Next()
@@ -1,12 +1,12 @@
 package middleware
 
 import (
+	"fmt"
 	"log"
-	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"loan-api/model"
+	"loan-api/apperror"
 )
 
 func ErrorRecoveryMiddleware() gin.HandlerFunc {
@@ -19,14 +19,12 @@ func ErrorRecoveryMiddleware() gin.HandlerFunc {
 				if _, err := strconv.Atoi(appID); err != nil {
 					appID = "N/A"
 				}
-				log.Printf("Request Error - Path: %s, Method: %s, AppID: %s, Error: %v",
-					c.Request.URL.Path, c.Request.Method, appID, r)
+				log.Printf("Request Error - RequestID: %s, Path: %s, Method: %s, AppID: %s, Error: %v",
+					RequestID(c), c.Request.URL.Path, c.Request.Method, appID, r)
 
-				c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-					Error:   "Internal Server Error",
-					Details: []string{"Something unexpected happened. Please try again later."},
-				})
-				c.Abort()
+				apperror.Respond(c, apperror.ErrInternal.
+					WithDetail("Something unexpected happened. Please try again later.").
+					Wrap(fmt.Errorf("panic: %v", r)))
 			}
 		}()
 		c.Next()
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"loan-api/apperror"
+	"loan-api/model"
+)
+
+type principalContextKey struct{}
+
+// PrincipalFromContext returns the principal set by the gRPC auth
+// interceptors.
+func PrincipalFromContext(ctx context.Context) (model.Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(model.Principal)
+	return principal, ok
+}
+
+// UnaryAuthInterceptor authenticates gRPC calls with the bearer token in the
+// "authorization" metadata, accepting the same tokens as AuthMiddleware.
+func UnaryAuthInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticateCall(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := handler(ctx, req)
+		logInternal(info.FullMethod, err)
+		return resp, err
+	}
+}
+
+// StreamAuthInterceptor is UnaryAuthInterceptor for streaming calls.
+func StreamAuthInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticateCall(ss.Context())
+		if err != nil {
+			return err
+		}
+		err = handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+		logInternal(info.FullMethod, err)
+		return err
+	}
+}
+
+func authenticateCall(ctx context.Context) (context.Context, error) {
+	var authorization string
+	if values := metadata.ValueFromIncomingContext(ctx, "authorization"); len(values) > 0 {
+		authorization = values[0]
+	}
+	principal, err := authenticate(authorization)
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, principalContextKey{}, principal), nil
+}
+
+// logInternal logs server errors, which clients only see as a code and title.
+func logInternal(method string, err error) {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) && appErr.Status >= 500 {
+		log.Printf("gRPC Error - Method: %s, Error: %v", method, appErr)
+	}
+}
+
+// authenticatedStream carries the authenticated principal in its context.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
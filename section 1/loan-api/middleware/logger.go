@@ -12,7 +12,7 @@ func RequestLoggerMiddleware() gin.HandlerFunc {
 		start := time.Now()
 		c.Next()
 		duration := time.Since(start)
-		log.Printf("Request - Method: %s, Path: %s, Status: %d, Duration: %v",
-			c.Request.Method, c.Request.URL.Path, c.Writer.Status(), duration)
+		log.Printf("Request - RequestID: %s, Method: %s, Path: %s, Status: %d, Duration: %v",
+			RequestID(c), c.Request.Method, c.Request.URL.Path, c.Writer.Status(), duration)
 	}
 }
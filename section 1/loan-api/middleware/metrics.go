@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/metrics"
+)
+
+// unmatchedRoute labels requests that match no route, so unknown paths do not
+// create new series.
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records request counts, latencies and in-flight requests
+// by route template, such as /loan-applications/:id.
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+
+		inFlight := metrics.RequestsInFlight.WithLabelValues(method, route)
+		inFlight.Inc()
+		// Deferred so a panicking handler does not leave the gauge raised.
+		defer inFlight.Dec()
+		start := time.Now()
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.RequestsTotal.WithLabelValues(method, route, status).Inc()
+		metrics.RequestDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/apperror"
+)
+
+const requestIDKey = "request_id"
+
+// maxRequestIDLength bounds client supplied IDs so they can be logged safely.
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware propagates the client's X-Request-ID or generates one,
+// and echoes it on the response so problem documents and logs can refer to it.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(apperror.RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(apperror.RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestID returns the ID set by RequestIDMiddleware.
+func RequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"loan-api/serializer"
+)
+
+const apiVersionKey = "api_version"
+
+// APIVersion marks the requests of a route group as using version, which
+// decides the shape of their responses.
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionKey, version)
+		c.Next()
+	}
+}
+
+// CurrentAPIVersion returns the version set by APIVersion, or serializer.V1
+// when none was set.
+func CurrentAPIVersion(c *gin.Context) string {
+	if version := c.GetString(apiVersionKey); version != "" {
+		return version
+	}
+	return serializer.V1
+}
+
+// DeprecatedAlias serves the unversioned routes as aliases of version. Every
+// response carries a Deprecation header and links to the versioned path so
+// clients can move before the aliases are removed.
+func DeprecatedAlias(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionKey, version)
+		c.Header("Deprecation", "true")
+		c.Header("Link", fmt.Sprintf("</%s%s>; rel=\"successor-version\"", version, c.Request.URL.Path))
+		c.Next()
+	}
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+const (
+	AuditApplicationsExported  = "applications.exported"
+	AuditApplicationAnonymized = "application.anonymized"
+	AuditApplicationDeleted    = "application.deleted"
+	AuditLegalHoldPlaced       = "application.legal_hold_placed"
+	AuditLegalHoldLifted       = "application.legal_hold_lifted"
+)
+
+// AuditEntry records a sensitive action taken by a principal.
+type AuditEntry struct {
+	ID         int               `json:"id"`
+	Actor      string            `json:"actor"`
+	Role       string            `json:"role"`
+	Action     string            `json:"action"`
+	Details    map[string]string `json:"details,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
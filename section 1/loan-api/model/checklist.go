@@ -0,0 +1,65 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Document is an uploaded supporting document. Type is the document type the
+// uploader declared, such as "pay_stub", and may be empty. Path is where the
+// file is stored, so it can be removed when the application is erased.
+type Document struct {
+	Name       string    `json:"name"`
+	Type       string    `json:"type,omitempty"`
+	Path       string    `json:"-"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// Checklist shows which of the documents required for an application's loan
+// purpose have been uploaded.
+type Checklist struct {
+	ApplicationID int             `json:"application_id"`
+	LoanPurpose   string          `json:"loan_purpose"`
+	Complete      bool            `json:"complete"`
+	Items         []ChecklistItem `json:"items"`
+}
+
+type ChecklistItem struct {
+	DocumentType string   `json:"document_type"`
+	Provided     bool     `json:"provided"`
+	Documents    []string `json:"documents"`
+}
+
+// BuildChecklist matches the application's documents against the required
+// document types, ignoring case.
+func BuildChecklist(app LoanApplication, required []string) Checklist {
+	checklist := Checklist{
+		ApplicationID: app.ID,
+		LoanPurpose:   LoanPurposeKey(app.LoanPurpose),
+		Complete:      true,
+		Items:         []ChecklistItem{},
+	}
+	for _, documentType := range required {
+		item := ChecklistItem{DocumentType: documentType, Documents: []string{}}
+		for _, document := range app.Documents {
+			if strings.EqualFold(document.Type, documentType) {
+				item.Documents = append(item.Documents, document.Name)
+			}
+		}
+		item.Provided = len(item.Documents) > 0
+		checklist.Complete = checklist.Complete && item.Provided
+		checklist.Items = append(checklist.Items, item)
+	}
+	return checklist
+}
+
+// Missing returns the document types that have not been provided.
+func (c Checklist) Missing() []string {
+	var missing []string
+	for _, item := range c.Items {
+		if !item.Provided {
+			missing = append(missing, item.DocumentType)
+		}
+	}
+	return missing
+}
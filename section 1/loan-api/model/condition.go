@@ -0,0 +1,60 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Condition is an outstanding item that must be cleared before an approved
+// application can be funded, such as "last 2 pay stubs". It is satisfied by
+// uploading a document of DocumentType.
+type Condition struct {
+	ID           int        `json:"id"`
+	Description  string     `json:"description"`
+	DocumentType string     `json:"document_type"`
+	DueDate      string     `json:"due_date,omitempty"` // YYYY-MM-DD
+	SatisfiedBy  string     `json:"satisfied_by,omitempty"`
+	SatisfiedAt  *time.Time `json:"satisfied_at,omitempty"`
+	CreatedBy    string     `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (c Condition) IsSatisfied() bool {
+	return c.SatisfiedAt != nil
+}
+
+// Matches reports whether a document of documentType clears the condition.
+func (c Condition) Matches(documentType string) bool {
+	return !c.IsSatisfied() && strings.EqualFold(c.DocumentType, strings.TrimSpace(documentType))
+}
+
+// UncoveredDocuments returns the document types checklist is missing that no
+// outstanding condition on app asks for. A conditional approval only needs
+// these: funding waits for the rest through the conditions.
+func UncoveredDocuments(app LoanApplication, checklist Checklist) []string {
+	var uncovered []string
+	for _, documentType := range checklist.Missing() {
+		covered := false
+		for _, condition := range app.Conditions {
+			if condition.Matches(documentType) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			uncovered = append(uncovered, documentType)
+		}
+	}
+	return uncovered
+}
+
+// OutstandingConditions returns the conditions on app that are not satisfied.
+func OutstandingConditions(app LoanApplication) []Condition {
+	var outstanding []Condition
+	for _, condition := range app.Conditions {
+		if !condition.IsSatisfied() {
+			outstanding = append(outstanding, condition)
+		}
+	}
+	return outstanding
+}
@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// Duplicate policies decide what happens to a submission that resembles an
+// earlier application.
+const (
+	DuplicatePolicyOff    = "off"
+	DuplicatePolicyReject = "reject"
+	DuplicatePolicyLink   = "link"
+	DuplicatePolicyFlag   = "flag"
+)
+
+var DuplicatePolicies = []string{DuplicatePolicyOff, DuplicatePolicyReject, DuplicatePolicyLink, DuplicatePolicyFlag}
+
+// Reasons an application matches an earlier one.
+const (
+	DuplicateSameSSN          = "same_ssn"
+	DuplicateSimilarApplicant = "similar_name_and_amount"
+)
+
+// Actions recorded on applications saved despite matching earlier ones.
+const (
+	DuplicateActionLinked  = "linked"
+	DuplicateActionFlagged = "flagged"
+)
+
+// DuplicateRule configures the duplicate check. Applications submitted within
+// Window before a submission are compared with it; amounts are near-identical
+// when they differ by at most AmountTolerance of the larger one. The zero
+// value disables the check.
+type DuplicateRule struct {
+	Policy          string
+	Window          time.Duration
+	AmountTolerance float64
+}
+
+// Enabled reports whether submissions are checked at all.
+func (r DuplicateRule) Enabled() bool {
+	return r.Policy != "" && r.Policy != DuplicatePolicyOff && r.Window > 0
+}
+
+// DuplicateMatch names an earlier application a submission resembles.
+type DuplicateMatch struct {
+	ApplicationID int       `json:"application_id"`
+	Reason        string    `json:"reason"`
+	SubmittedAt   time.Time `json:"submitted_at"`
+}
+
+// DuplicateCheck records the earlier applications a submission resembled and
+// what the policy did about it: linked them or flagged the submission for
+// review.
+type DuplicateCheck struct {
+	Matches []DuplicateMatch `json:"matches"`
+	Action  string           `json:"action"`
+}
+
+// Velocity counts the applications submitted with the same SSN, this one
+// included, in the 30 and 90 days up to its submission.
+type Velocity struct {
+	Last30Days int `json:"last_30_days"`
+	Last90Days int `json:"last_90_days"`
+}
@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+const (
+	EventApplicationSubmitted     = "application.submitted"
+	EventApplicationStatusChanged = "application.status_changed"
+	EventDocumentUploaded         = "document.uploaded"
+	EventSLAAtRisk                = "application.sla_at_risk"
+	EventSLABreached              = "application.sla_breached"
+	EventApplicationAssigned      = "application.assigned"
+	EventNoteAdded                = "application.note_added"
+	EventConditionSatisfied       = "application.condition_satisfied"
+	EventApplicationAnonymized    = "application.anonymized"
+	EventApplicationDeleted       = "application.deleted"
+)
+
+var EventTypes = []string{
+	EventApplicationSubmitted,
+	EventApplicationStatusChanged,
+	EventDocumentUploaded,
+	EventSLAAtRisk,
+	EventSLABreached,
+	EventApplicationAssigned,
+	EventNoteAdded,
+	EventConditionSatisfied,
+	EventApplicationAnonymized,
+	EventApplicationDeleted,
+}
+
+// Event describes a change in an application's lifecycle. Application always
+// carries the masked view of the application after the change.
+type Event struct {
+	ID            int64             `json:"id"`
+	Type          string            `json:"type"`
+	Tenant        string            `json:"tenant"`
+	ApplicationID int               `json:"application_id"`
+	OccurredAt    time.Time         `json:"occurred_at"`
+	Application   LoanApplication   `json:"application"`
+	Data          map[string]string `json:"data,omitempty"`
+}
+
+func IsValidEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// HistoryEntry is an event in an application's history, without the copy of
+// the application the event carried.
+type HistoryEntry struct {
+	EventID    int64             `json:"event_id"`
+	Type       string            `json:"type"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Data       map[string]string `json:"data,omitempty"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// ImportLimits bounds one import request. MaxRows and MaxBytes cap the file,
+// and imports with more rows than AsyncThreshold run in the background, at
+// most MaxRunningJobs at a time.
+type ImportLimits struct {
+	MaxRows        int
+	MaxBytes       int64
+	AsyncThreshold int
+	MaxRunningJobs int
+}
+
+// ImportJob tracks a bulk import of loan applications and its per-row report.
+type ImportJob struct {
+	ID          int               `json:"id"`
+	Status      string            `json:"status"` // pending, running, completed
+	Format      string            `json:"format"`
+	DryRun      bool              `json:"dry_run"`
+	TotalRows   int               `json:"total_rows"`
+	Processed   int               `json:"processed_rows"`
+	Created     int               `json:"created"`
+	Valid       int               `json:"valid"`
+	Failed      int               `json:"failed"`
+	Rows        []ImportRowResult `json:"rows"`
+	SubmittedBy string            `json:"submitted_by,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+}
+
+// ImportRowResult reports the outcome of one row. Row is the 1-based line
+// number in the uploaded file, so CSV data starts at row 2.
+type ImportRowResult struct {
+	Row           int          `json:"row"`
+	ApplicationID int          `json:"application_id,omitempty"`
+	Error         string       `json:"error,omitempty"`
+	Errors        []FieldError `json:"errors,omitempty"`
+}
+
+// Succeeded reports whether the row was valid.
+func (r ImportRowResult) Succeeded() bool {
+	return r.Error == "" && len(r.Errors) == 0
+}
@@ -1,36 +1,176 @@
 package model
 
 import (
+	"slices"
+	"strings"
 	"time"
 )
 
+const (
+	StatusPending                = "pending"
+	StatusUnderReview            = "under_review"
+	StatusApproved               = "approved"
+	StatusApprovedWithConditions = "approved_with_conditions"
+	StatusRejected               = "rejected"
+	StatusFunded                 = "funded"
+)
+
+var Statuses = []string{StatusPending, StatusApproved, StatusRejected, StatusUnderReview, StatusApprovedWithConditions, StatusFunded}
+
+// IsValidStatus reports whether status is one of Statuses.
+func IsValidStatus(status string) bool {
+	for _, s := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// statusTransitions lists the statuses an application may move to from each
+// status. Rejected and funded applications are final.
+var statusTransitions = map[string][]string{
+	StatusPending:                {StatusUnderReview, StatusApproved, StatusApprovedWithConditions, StatusRejected},
+	StatusUnderReview:            {StatusPending, StatusApproved, StatusApprovedWithConditions, StatusRejected},
+	StatusApproved:               {StatusApprovedWithConditions, StatusRejected, StatusFunded},
+	StatusApprovedWithConditions: {StatusApproved, StatusRejected, StatusFunded},
+}
+
+// CanTransition reports whether an application in status from may move to
+// status to.
+func CanTransition(from, to string) bool {
+	return slices.Contains(statusTransitions[from], to)
+}
+
+// IsFinal reports whether an application in status can no longer change.
+func IsFinal(status string) bool {
+	return len(statusTransitions[status]) == 0
+}
+
+// IsDecided reports whether an application in status has been decided.
+func IsDecided(status string) bool {
+	return IsApproved(status) || status == StatusRejected
+}
+
+// IsApproved reports whether status is an approval, including conditional
+// approvals and funded applications.
+func IsApproved(status string) bool {
+	return status == StatusApproved || status == StatusApprovedWithConditions || status == StatusFunded
+}
+
 type LoanApplication struct {
-	ID                int        `json:"id"`
-	ApplicantName     string     `json:"applicant_name" binding:"required"`
-	ApplicantSSN      string     `json:"applicant_ssn" binding:"required,len=11"` // Format: XXX-XX-XXXX
-	LoanAmount        float64    `json:"loan_amount" binding:"required,min=1000,max=1000000"`
-	LoanPurpose       string     `json:"loan_purpose" binding:"required"`
-	AnnualIncome      float64    `json:"annual_income" binding:"required,min=0"`
-	CreditScore       int        `json:"credit_score" binding:"required,min=300,max=850"`
-	Status            string     `json:"status"` // pending, approved, rejected, under_review
-	SubmittedAt       time.Time  `json:"submitted_at"`
-	ProcessedAt       *time.Time `json:"processed_at,omitempty"`
-	DocumentsUploaded []string   `json:"documents_uploaded"`
+	ID                int         `json:"id"`
+	Tenant            string      `json:"tenant,omitempty"`
+	ApplicantName     string      `json:"applicant_name" binding:"required"`
+	ApplicantSSN      string      `json:"applicant_ssn" binding:"required"` // US: XXX-XX-XXXX, ID: 16 digit NIK
+	Country           string      `json:"country" binding:"omitempty,oneof=US ID"`
+	TaxID             string      `json:"tax_id,omitempty"`               // ID: NPWP
+	LoanAmount        float64     `json:"loan_amount" binding:"required"` // limits depend on Country
+	LoanPurpose       string      `json:"loan_purpose" binding:"required,loan_purpose"`
+	AnnualIncome      float64     `json:"annual_income" binding:"required,min=0"`
+	CreditScore       int         `json:"credit_score" binding:"required,min=300,max=850"`
+	Status            string      `json:"status"` // pending, under_review, approved, approved_with_conditions, rejected, funded
+	StatusUpdatedAt   time.Time   `json:"status_updated_at"`
+	SubmittedAt       time.Time   `json:"submitted_at"`
+	ProcessedAt       *time.Time  `json:"processed_at,omitempty"`
+	DocumentsUploaded []string    `json:"documents_uploaded"`
+	Documents         []Document  `json:"documents,omitempty"`
+	SubmittedBy       string      `json:"submitted_by,omitempty"`
+	AssignedTo        string      `json:"assigned_to,omitempty"`
+	AssignedAt        *time.Time  `json:"assigned_at,omitempty"`
+	SLA               *SLA        `json:"sla,omitempty"`
+	Conditions        []Condition `json:"conditions,omitempty"`
+	LegalHold         *LegalHold  `json:"legal_hold,omitempty"`
+	AnonymizedAt      *time.Time  `json:"anonymized_at,omitempty"`
+	// Set by the duplicate check on submission.
+	DuplicateCheck     *DuplicateCheck `json:"duplicate_check,omitempty"`
+	LinkedApplications []int           `json:"linked_applications,omitempty"`
+	Velocity           *Velocity       `json:"velocity,omitempty"`
 }
 
-type ErrorResponse struct {
-	Error   string   `json:"error"`
-	Details []string `json:"details,omitempty"`
+// LoanApplicationInput holds the fields a client sets when submitting an
+// application. Every other field of LoanApplication is owned by the server,
+// so submissions are decoded into this type rather than the application.
+type LoanApplicationInput struct {
+	ApplicantName string  `json:"applicant_name"`
+	ApplicantSSN  string  `json:"applicant_ssn"`
+	Country       string  `json:"country"`
+	TaxID         string  `json:"tax_id"`
+	LoanAmount    float64 `json:"loan_amount"`
+	LoanPurpose   string  `json:"loan_purpose"`
+	AnnualIncome  float64 `json:"annual_income"`
+	CreditScore   int     `json:"credit_score"`
+}
+
+// Application returns the new application described by the input. It still
+// has to be validated.
+func (in LoanApplicationInput) Application() LoanApplication {
+	return LoanApplication{
+		ApplicantName: in.ApplicantName,
+		ApplicantSSN:  in.ApplicantSSN,
+		Country:       in.Country,
+		TaxID:         in.TaxID,
+		LoanAmount:    in.LoanAmount,
+		LoanPurpose:   in.LoanPurpose,
+		AnnualIncome:  in.AnnualIncome,
+		CreditScore:   in.CreditScore,
+	}
+}
+
+// FieldError describes why a single request field was rejected. Field is the
+// JSON field name and Code a stable identifier; Message is localized.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 func MaskSSN(ssn string) string {
 	if len(ssn) == 11 {
 		return "XXX-XX-" + ssn[7:]
 	}
+	if len(ssn) == 16 {
+		// NIK
+		return strings.Repeat("X", 12) + ssn[12:]
+	}
 	return "********"
 }
 
-func GetMaskedApplication(app LoanApplication) LoanApplication {
+func MaskTaxID(taxID string) string {
+	if len(taxID) <= 4 {
+		return strings.Repeat("X", len(taxID))
+	}
+	return strings.Repeat("X", len(taxID)-4) + taxID[len(taxID)-4:]
+}
+
+// GetMaskedApplication returns app as viewer may see it. Identity numbers are
+// masked for everyone; the fraud signals are only kept for staff, so that
+// applicants cannot learn what the duplicate check found.
+func GetMaskedApplication(app LoanApplication, viewer Principal) LoanApplication {
+	if !viewer.IsStaff() {
+		app = WithoutFraudSignals(app)
+	}
+	return MaskIdentity(app)
+}
+
+// WithoutFraudSignals drops the velocity, duplicate check and linked
+// applications of app.
+func WithoutFraudSignals(app LoanApplication) LoanApplication {
+	app.Velocity = nil
+	app.DuplicateCheck = nil
+	app.LinkedApplications = nil
+	return app
+}
+
+// MaskIdentity masks the SSN or NIK and the tax ID of app. Masking is
+// idempotent, and anonymized applications are returned as they are.
+func MaskIdentity(app LoanApplication) LoanApplication {
+	if app.AnonymizedAt != nil {
+		return app
+	}
 	app.ApplicantSSN = MaskSSN(app.ApplicantSSN)
+	if app.TaxID != "" {
+		app.TaxID = MaskTaxID(app.TaxID)
+	}
 	return app
 }
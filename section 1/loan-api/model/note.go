@@ -0,0 +1,58 @@
+package model
+
+import (
+	"regexp"
+	"time"
+)
+
+const (
+	NoteInternal  = "internal"
+	NoteApplicant = "applicant"
+)
+
+var NoteVisibilities = []string{NoteInternal, NoteApplicant}
+
+// Note is a comment on an application. Replies carry the ID of the note that
+// starts their thread in ParentID; threads are one level deep.
+type Note struct {
+	ID            int            `json:"id"`
+	ApplicationID int            `json:"application_id"`
+	ParentID      *int           `json:"parent_id,omitempty"`
+	Author        string         `json:"author"`
+	AuthorRole    string         `json:"author_role"`
+	Body          string         `json:"body"`
+	Visibility    string         `json:"visibility"` // internal, applicant
+	Mentions      []string       `json:"mentions,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     *time.Time     `json:"updated_at,omitempty"`
+	History       []NoteRevision `json:"history,omitempty"`
+	Replies       []Note         `json:"replies,omitempty"`
+}
+
+// NoteRevision is a previous version of a note, kept when the note is edited.
+type NoteRevision struct {
+	Body       string    `json:"body"`
+	Visibility string    `json:"visibility"`
+	EditedBy   string    `json:"edited_by"`
+	EditedAt   time.Time `json:"edited_at"`
+}
+
+func IsValidNoteVisibility(visibility string) bool {
+	return visibility == NoteInternal || visibility == NoteApplicant
+}
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.-]*\w)`)
+
+// ParseMentions returns the subjects mentioned as @subject in body, in order
+// of first appearance.
+func ParseMentions(body string) []string {
+	var mentions []string
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			mentions = append(mentions, match[1])
+		}
+	}
+	return mentions
+}
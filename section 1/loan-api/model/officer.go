@@ -0,0 +1,26 @@
+package model
+
+// Officer is a loan officer who can be assigned applications of their tenant.
+// Capacity is the most open applications the officer works at once; zero
+// means unlimited.
+type Officer struct {
+	Subject  string `json:"subject"`
+	Tenant   string `json:"tenant,omitempty"`
+	Capacity int    `json:"capacity"`
+}
+
+// OfficerWorkload is an officer's current number of open assignments.
+type OfficerWorkload struct {
+	Officer
+	Open int `json:"open"`
+}
+
+// HasCapacity reports whether the officer can take another application.
+func (w OfficerWorkload) HasCapacity() bool {
+	return w.Capacity == 0 || w.Open < w.Capacity
+}
+
+// IsOpenAssignment reports whether app counts towards its officer's workload.
+func IsOpenAssignment(app LoanApplication) bool {
+	return app.AssignedTo != "" && !IsDecided(app.Status)
+}
@@ -0,0 +1,118 @@
+package model
+
+import "slices"
+
+const (
+	RoleAdmin     = "admin"
+	RoleOfficer   = "officer"
+	RoleApplicant = "applicant"
+)
+
+// PermissionViewPII allows reading unmasked identity numbers.
+const PermissionViewPII = "pii:read"
+
+// Permissions lists every permission that can be granted to a token.
+var Permissions = []string{PermissionViewPII}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject     string   `json:"subject"`
+	Role        string   `json:"role"`
+	Tenant      string   `json:"tenant,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// TenantID returns the tenant whose data the principal works on.
+func (p Principal) TenantID() string {
+	return TenantID(p.Tenant)
+}
+
+// HasPermission reports whether the principal was granted permission.
+// Admins hold every permission.
+func (p Principal) HasPermission(permission string) bool {
+	if p.Role == RoleAdmin {
+		return true
+	}
+	for _, granted := range p.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// CanViewApplication reports whether the principal may see the application.
+// Staff see every application of their tenant, applicants only the ones they
+// submitted.
+func (p Principal) CanViewApplication(app LoanApplication) bool {
+	if TenantID(app.Tenant) != p.TenantID() {
+		return false
+	}
+	switch p.Role {
+	case RoleAdmin, RoleOfficer:
+		return true
+	case RoleApplicant:
+		return app.SubmittedBy != "" && app.SubmittedBy == p.Subject
+	default:
+		return false
+	}
+}
+
+// CanViewImport reports whether the principal may see the import job. Staff
+// see every job, other callers only the ones they started.
+func (p Principal) CanViewImport(job ImportJob) bool {
+	switch p.Role {
+	case RoleAdmin, RoleOfficer:
+		return true
+	default:
+		return job.SubmittedBy != "" && job.SubmittedBy == p.Subject
+	}
+}
+
+// CanViewNote reports whether the principal may read a note on app. Staff
+// read every note of their tenant, applicants only applicant-visible notes on
+// their own applications.
+func (p Principal) CanViewNote(app LoanApplication, note Note) bool {
+	if !p.CanViewApplication(app) {
+		return false
+	}
+	return p.IsStaff() || note.Visibility == NoteApplicant
+}
+
+// CanViewEvent reports whether the principal may see an event of the given
+// type and data about app. Assignments are for staff only and note events
+// follow the visibility of their note.
+func (p Principal) CanViewEvent(app LoanApplication, eventType string, data map[string]string) bool {
+	switch eventType {
+	case EventApplicationAssigned:
+		return p.IsStaff() && p.CanViewApplication(app)
+	case EventNoteAdded:
+		return p.CanViewNote(app, Note{Visibility: data["visibility"]})
+	}
+	return p.CanViewApplication(app)
+}
+
+// staffEventData lists the event data keys only staff may read: what the
+// duplicate check found about a submission, and who changed an application
+// and why.
+var staffEventData = []string{"duplicate_of", "duplicate_action", "changed_by", "reason"}
+
+// VisibleEventData returns the event data the principal may read. Staff read
+// all of it; for anyone else the staff-only keys are left out of a copy.
+func (p Principal) VisibleEventData(data map[string]string) map[string]string {
+	if p.IsStaff() || data == nil {
+		return data
+	}
+	visible := make(map[string]string, len(data))
+	for key, value := range data {
+		if !slices.Contains(staffEventData, key) {
+			visible[key] = value
+		}
+	}
+	return visible
+}
+
+// IsStaff reports whether the principal is an admin or a loan officer.
+func (p Principal) IsStaff() bool {
+	return p.Role == RoleAdmin || p.Role == RoleOfficer
+}
@@ -0,0 +1,85 @@
+package model
+
+import "strings"
+
+const (
+	CountryUS = "US"
+	CountryID = "ID"
+)
+
+// currencies maps each supported country to the ISO 4217 code of the amounts
+// entered for it.
+var currencies = map[string]string{
+	CountryUS: "USD",
+	CountryID: "IDR",
+}
+
+// Currency returns the currency of an application from country. Applications
+// without a country are US applications.
+func Currency(country string) string {
+	if currency, found := currencies[country]; found {
+		return currency
+	}
+	return currencies[CountryUS]
+}
+
+// AmountLimits is the range of loan amounts accepted in one currency.
+type AmountLimits struct {
+	Min, Max float64
+}
+
+// loanAmountLimits holds the accepted loan amounts of each country, in its
+// currency. The rupiah limits are the dollar limits at roughly 15,000 IDR to
+// the dollar.
+var loanAmountLimits = map[string]AmountLimits{
+	CountryUS: {Min: 1000, Max: 1000000},
+	CountryID: {Min: 15000000, Max: 15000000000},
+}
+
+// LoanAmountLimits returns the accepted loan amounts of an application from
+// country. Applications without a country are US applications.
+func LoanAmountLimits(country string) AmountLimits {
+	if limits, found := loanAmountLimits[country]; found {
+		return limits
+	}
+	return loanAmountLimits[CountryUS]
+}
+
+const (
+	PurposeHomeRenovation    = "home_renovation"
+	PurposeHomePurchase      = "home_purchase"
+	PurposeBusinessExpansion = "business_expansion"
+	PurposeCarPurchase       = "car_purchase"
+	PurposeEducation         = "education"
+	PurposeDebtConsolidation = "debt_consolidation"
+	PurposeMedical           = "medical"
+	PurposePersonal          = "personal"
+)
+
+var LoanPurposes = []string{
+	PurposeHomeRenovation,
+	PurposeHomePurchase,
+	PurposeBusinessExpansion,
+	PurposeCarPurchase,
+	PurposeEducation,
+	PurposeDebtConsolidation,
+	PurposeMedical,
+	PurposePersonal,
+}
+
+// LoanPurposeKey normalizes a free text purpose such as "Home Renovation"
+// to its enumeration key ("home_renovation").
+func LoanPurposeKey(purpose string) string {
+	key := strings.ToLower(strings.TrimSpace(purpose))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+}
+
+func IsValidLoanPurpose(purpose string) bool {
+	key := LoanPurposeKey(purpose)
+	for _, p := range LoanPurposes {
+		if p == key {
+			return true
+		}
+	}
+	return false
+}
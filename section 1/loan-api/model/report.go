@@ -0,0 +1,46 @@
+package model
+
+const (
+	GroupByDay   = "day"
+	GroupByWeek  = "week"
+	GroupByMonth = "month"
+)
+
+// PipelineReport summarizes applications over a date range, overall and per period.
+type PipelineReport struct {
+	GroupBy     string           `json:"group_by"`
+	From        string           `json:"from,omitempty"`
+	To          string           `json:"to,omitempty"`
+	LoanPurpose string           `json:"loan_purpose,omitempty"`
+	Summary     PipelineStats    `json:"summary"`
+	Periods     []PipelinePeriod `json:"periods"`
+}
+
+// PipelinePeriod holds the stats of applications submitted in the period
+// starting on Start (YYYY-MM-DD, weeks start on Monday).
+type PipelinePeriod struct {
+	Start string `json:"start"`
+	PipelineStats
+}
+
+// PipelineStats are computed from Status, SubmittedAt and ProcessedAt. Rates
+// are the share of decided (approved or rejected) applications; decision
+// times are nil when nothing was decided. Amounts are summed per currency,
+// keyed by ISO 4217 code, since amounts in different currencies cannot be
+// added up.
+type PipelineStats struct {
+	Total               int                     `json:"total"`
+	ByStatus            map[string]int          `json:"by_status"`
+	ByPurpose           map[string]PurposeStats `json:"by_purpose"`
+	ApprovalRate        float64                 `json:"approval_rate"`
+	RejectionRate       float64                 `json:"rejection_rate"`
+	MedianDecisionHours *float64                `json:"median_decision_hours"`
+	P90DecisionHours    *float64                `json:"p90_decision_hours"`
+	TotalRequested      map[string]float64      `json:"total_requested"`
+	TotalApproved       map[string]float64      `json:"total_approved"`
+}
+
+type PurposeStats struct {
+	Count  int                `json:"count"`
+	Amount map[string]float64 `json:"amount"`
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	RetentionDelete    = "delete"
+	RetentionAnonymize = "anonymize"
+)
+
+// ErasedValue replaces personal data that has to stay present, such as the
+// applicant name, in anonymized applications.
+const ErasedValue = "[erased]"
+
+// RetentionRule removes applications that have been in Status for at least
+// AfterDays days, either deleting them or anonymizing them in place.
+type RetentionRule struct {
+	Status    string `json:"status"`
+	AfterDays int    `json:"after_days"`
+	Action    string `json:"action"` // delete, anonymize
+}
+
+func IsValidRetentionAction(action string) bool {
+	return action == RetentionDelete || action == RetentionAnonymize
+}
+
+// LegalHold keeps an application from being deleted or anonymized.
+type LegalHold struct {
+	Reason   string    `json:"reason"`
+	PlacedBy string    `json:"placed_by"`
+	PlacedAt time.Time `json:"placed_at"`
+}
+
+// Anonymize erases the personal data of app: the applicant's name and
+// identity numbers, who submitted it and its documents. Amounts, purpose,
+// income, credit score, statuses, decisions and dates are kept for reporting
+// and audit.
+func Anonymize(app LoanApplication, now time.Time) LoanApplication {
+	app.ApplicantName = ErasedValue
+	app.ApplicantSSN = ""
+	app.TaxID = ""
+	app.SubmittedBy = ""
+	app.DocumentsUploaded = nil
+	app.Documents = nil
+	app.Conditions = slices.Clone(app.Conditions)
+	for i := range app.Conditions {
+		if app.Conditions[i].SatisfiedBy != "" {
+			app.Conditions[i].SatisfiedBy = ErasedValue
+		}
+	}
+	app.AnonymizedAt = &now
+	return app
+}
+
+// IsErasureEvent reports whether events of eventType remove an application's
+// personal data, after which earlier events must not carry it either.
+func IsErasureEvent(eventType string) bool {
+	return eventType == EventApplicationAnonymized || eventType == EventApplicationDeleted
+}
+
+// Redacted returns the event with its application replaced by the anonymized
+// one and its data dropped, since data may name documents.
+func (e Event) Redacted(app LoanApplication) Event {
+	e.Application = app
+	e.Data = nil
+	return e
+}
+
+// ErasureResult lists the applications a subject erasure anonymized, the
+// ones it left untouched because they are on legal hold and the ones it
+// failed to anonymize, which still hold personal data.
+type ErasureResult struct {
+	Erased []int `json:"erased"`
+	Held   []int `json:"held"`
+	Failed []int `json:"failed"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+const (
+	SLAOnTrack  = "on_track"
+	SLAAtRisk   = "at_risk"
+	SLABreached = "breached"
+)
+
+var SLAStates = []string{SLAOnTrack, SLAAtRisk, SLABreached}
+
+// SLA is the deadline for an application to leave its current status. It is
+// maintained by the SLA sweeper and cleared whenever the status changes.
+type SLA struct {
+	State      string     `json:"state"` // on_track, at_risk, breached
+	DueAt      time.Time  `json:"due_at"`
+	BreachedAt *time.Time `json:"breached_at,omitempty"`
+}
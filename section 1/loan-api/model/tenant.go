@@ -0,0 +1,21 @@
+package model
+
+// DefaultTenant owns the data of principals that are not assigned a tenant.
+const DefaultTenant = "default"
+
+// TenantID normalizes an empty tenant to DefaultTenant.
+func TenantID(tenant string) string {
+	if tenant == "" {
+		return DefaultTenant
+	}
+	return tenant
+}
+
+// TenantSettings overrides the deployment defaults for one tenant. Zero
+// amounts, a nil Statuses and a nil Checklists keep the defaults.
+type TenantSettings struct {
+	MinLoanAmount float64             `json:"min_loan_amount"`
+	MaxLoanAmount float64             `json:"max_loan_amount"`
+	Statuses      []string            `json:"statuses"`
+	Checklists    map[string][]string `json:"checklists"`
+}
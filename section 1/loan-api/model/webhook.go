@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+type WebhookSubscription struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url" binding:"required,url"`
+	Events    []string  `json:"events"` // empty means every event type
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int        `json:"id"`
+	SubscriptionID int        `json:"subscription_id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	ApplicationID  int        `json:"application_id"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"` // pending, succeeded, failed
+	Attempts       int        `json:"attempts"`
+	ResponseCode   int        `json:"response_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+}
+
+// Matches reports whether the subscription wants events of the given type.
+func (s WebhookSubscription) Matches(eventType string) bool {
+	if !s.Active {
+		return false
+	}
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func GetRedactedSubscription(sub WebhookSubscription) WebhookSubscription {
+	sub.Secret = ""
+	return sub
+}
@@ -0,0 +1,4 @@
+// Package loanv1 holds the generated code of loan.v1.LoanService.
+package loanv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative loan/v1/loan.proto
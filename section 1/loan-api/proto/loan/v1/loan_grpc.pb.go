@@ -0,0 +1,284 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: loan/v1/loan.proto
+
+package loanv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	LoanService_SubmitLoanApplication_FullMethodName       = "/loan.v1.LoanService/SubmitLoanApplication"
+	LoanService_GetLoanApplication_FullMethodName          = "/loan.v1.LoanService/GetLoanApplication"
+	LoanService_ListLoanApplications_FullMethodName        = "/loan.v1.LoanService/ListLoanApplications"
+	LoanService_UpdateLoanApplicationStatus_FullMethodName = "/loan.v1.LoanService/UpdateLoanApplicationStatus"
+	LoanService_UploadDocument_FullMethodName              = "/loan.v1.LoanService/UploadDocument"
+)
+
+// LoanServiceClient is the client API for LoanService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// LoanService mirrors the REST endpoints of LoanHandler. Calls carry the same
+// bearer token as REST in the "authorization" metadata, and identity numbers
+// are masked the same way.
+type LoanServiceClient interface {
+	SubmitLoanApplication(ctx context.Context, in *SubmitLoanApplicationRequest, opts ...grpc.CallOption) (*LoanApplication, error)
+	GetLoanApplication(ctx context.Context, in *GetLoanApplicationRequest, opts ...grpc.CallOption) (*LoanApplication, error)
+	// ListLoanApplications streams every matching application in ID order.
+	ListLoanApplications(ctx context.Context, in *ListLoanApplicationsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LoanApplication], error)
+	UpdateLoanApplicationStatus(ctx context.Context, in *UpdateLoanApplicationStatusRequest, opts ...grpc.CallOption) (*LoanApplication, error)
+	// UploadDocument takes the metadata in the first message and the file
+	// contents in the chunks that follow.
+	UploadDocument(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadDocumentRequest, LoanApplication], error)
+}
+
+type loanServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLoanServiceClient(cc grpc.ClientConnInterface) LoanServiceClient {
+	return &loanServiceClient{cc}
+}
+
+func (c *loanServiceClient) SubmitLoanApplication(ctx context.Context, in *SubmitLoanApplicationRequest, opts ...grpc.CallOption) (*LoanApplication, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoanApplication)
+	err := c.cc.Invoke(ctx, LoanService_SubmitLoanApplication_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loanServiceClient) GetLoanApplication(ctx context.Context, in *GetLoanApplicationRequest, opts ...grpc.CallOption) (*LoanApplication, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoanApplication)
+	err := c.cc.Invoke(ctx, LoanService_GetLoanApplication_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loanServiceClient) ListLoanApplications(ctx context.Context, in *ListLoanApplicationsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LoanApplication], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &LoanService_ServiceDesc.Streams[0], LoanService_ListLoanApplications_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListLoanApplicationsRequest, LoanApplication]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LoanService_ListLoanApplicationsClient = grpc.ServerStreamingClient[LoanApplication]
+
+func (c *loanServiceClient) UpdateLoanApplicationStatus(ctx context.Context, in *UpdateLoanApplicationStatusRequest, opts ...grpc.CallOption) (*LoanApplication, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoanApplication)
+	err := c.cc.Invoke(ctx, LoanService_UpdateLoanApplicationStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *loanServiceClient) UploadDocument(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadDocumentRequest, LoanApplication], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &LoanService_ServiceDesc.Streams[1], LoanService_UploadDocument_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadDocumentRequest, LoanApplication]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LoanService_UploadDocumentClient = grpc.ClientStreamingClient[UploadDocumentRequest, LoanApplication]
+
+// LoanServiceServer is the server API for LoanService service.
+// All implementations must embed UnimplementedLoanServiceServer
+// for forward compatibility.
+//
+// LoanService mirrors the REST endpoints of LoanHandler. Calls carry the same
+// bearer token as REST in the "authorization" metadata, and identity numbers
+// are masked the same way.
+type LoanServiceServer interface {
+	SubmitLoanApplication(context.Context, *SubmitLoanApplicationRequest) (*LoanApplication, error)
+	GetLoanApplication(context.Context, *GetLoanApplicationRequest) (*LoanApplication, error)
+	// ListLoanApplications streams every matching application in ID order.
+	ListLoanApplications(*ListLoanApplicationsRequest, grpc.ServerStreamingServer[LoanApplication]) error
+	UpdateLoanApplicationStatus(context.Context, *UpdateLoanApplicationStatusRequest) (*LoanApplication, error)
+	// UploadDocument takes the metadata in the first message and the file
+	// contents in the chunks that follow.
+	UploadDocument(grpc.ClientStreamingServer[UploadDocumentRequest, LoanApplication]) error
+	mustEmbedUnimplementedLoanServiceServer()
+}
+
+// UnimplementedLoanServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLoanServiceServer struct{}
+
+func (UnimplementedLoanServiceServer) SubmitLoanApplication(context.Context, *SubmitLoanApplicationRequest) (*LoanApplication, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitLoanApplication not implemented")
+}
+func (UnimplementedLoanServiceServer) GetLoanApplication(context.Context, *GetLoanApplicationRequest) (*LoanApplication, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLoanApplication not implemented")
+}
+func (UnimplementedLoanServiceServer) ListLoanApplications(*ListLoanApplicationsRequest, grpc.ServerStreamingServer[LoanApplication]) error {
+	return status.Errorf(codes.Unimplemented, "method ListLoanApplications not implemented")
+}
+func (UnimplementedLoanServiceServer) UpdateLoanApplicationStatus(context.Context, *UpdateLoanApplicationStatusRequest) (*LoanApplication, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateLoanApplicationStatus not implemented")
+}
+func (UnimplementedLoanServiceServer) UploadDocument(grpc.ClientStreamingServer[UploadDocumentRequest, LoanApplication]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadDocument not implemented")
+}
+func (UnimplementedLoanServiceServer) mustEmbedUnimplementedLoanServiceServer() {}
+func (UnimplementedLoanServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeLoanServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LoanServiceServer will
+// result in compilation errors.
+type UnsafeLoanServiceServer interface {
+	mustEmbedUnimplementedLoanServiceServer()
+}
+
+func RegisterLoanServiceServer(s grpc.ServiceRegistrar, srv LoanServiceServer) {
+	// If the following call pancis, it indicates UnimplementedLoanServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LoanService_ServiceDesc, srv)
+}
+
+func _LoanService_SubmitLoanApplication_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitLoanApplicationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoanServiceServer).SubmitLoanApplication(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LoanService_SubmitLoanApplication_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoanServiceServer).SubmitLoanApplication(ctx, req.(*SubmitLoanApplicationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LoanService_GetLoanApplication_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLoanApplicationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoanServiceServer).GetLoanApplication(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LoanService_GetLoanApplication_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoanServiceServer).GetLoanApplication(ctx, req.(*GetLoanApplicationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LoanService_ListLoanApplications_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListLoanApplicationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LoanServiceServer).ListLoanApplications(m, &grpc.GenericServerStream[ListLoanApplicationsRequest, LoanApplication]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LoanService_ListLoanApplicationsServer = grpc.ServerStreamingServer[LoanApplication]
+
+func _LoanService_UpdateLoanApplicationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLoanApplicationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LoanServiceServer).UpdateLoanApplicationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LoanService_UpdateLoanApplicationStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LoanServiceServer).UpdateLoanApplicationStatus(ctx, req.(*UpdateLoanApplicationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LoanService_UploadDocument_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LoanServiceServer).UploadDocument(&grpc.GenericServerStream[UploadDocumentRequest, LoanApplication]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LoanService_UploadDocumentServer = grpc.ClientStreamingServer[UploadDocumentRequest, LoanApplication]
+
+// LoanService_ServiceDesc is the grpc.ServiceDesc for LoanService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LoanService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "loan.v1.LoanService",
+	HandlerType: (*LoanServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitLoanApplication",
+			Handler:    _LoanService_SubmitLoanApplication_Handler,
+		},
+		{
+			MethodName: "GetLoanApplication",
+			Handler:    _LoanService_GetLoanApplication_Handler,
+		},
+		{
+			MethodName: "UpdateLoanApplicationStatus",
+			Handler:    _LoanService_UpdateLoanApplicationStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListLoanApplications",
+			Handler:       _LoanService_ListLoanApplications_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UploadDocument",
+			Handler:       _LoanService_UploadDocument_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "loan/v1/loan.proto",
+}
@@ -0,0 +1,153 @@
+package report
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"loan-api/model"
+)
+
+// Pipeline accumulates applications into a pipeline report. Applications may
+// be added in any order; Report sorts the periods.
+type Pipeline struct {
+	groupBy string
+	summary *accumulator
+	periods map[time.Time]*accumulator
+}
+
+func NewPipeline(groupBy string) *Pipeline {
+	return &Pipeline{
+		groupBy: groupBy,
+		summary: newAccumulator(),
+		periods: make(map[time.Time]*accumulator),
+	}
+}
+
+func (p *Pipeline) Add(app model.LoanApplication) {
+	p.summary.add(app)
+
+	start := PeriodStart(app.SubmittedAt, p.groupBy)
+	acc, found := p.periods[start]
+	if !found {
+		acc = newAccumulator()
+		p.periods[start] = acc
+	}
+	acc.add(app)
+}
+
+// Report returns the summary and the non-empty periods in chronological order.
+func (p *Pipeline) Report() model.PipelineReport {
+	report := model.PipelineReport{
+		GroupBy: p.groupBy,
+		Summary: p.summary.stats(),
+		Periods: []model.PipelinePeriod{},
+	}
+
+	starts := make([]time.Time, 0, len(p.periods))
+	for start := range p.periods {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	for _, start := range starts {
+		report.Periods = append(report.Periods, model.PipelinePeriod{
+			Start:         start.Format(time.DateOnly),
+			PipelineStats: p.periods[start].stats(),
+		})
+	}
+	return report
+}
+
+// PeriodStart truncates t in UTC to the start of its day, ISO week (Monday)
+// or month.
+func PeriodStart(t time.Time, groupBy string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch groupBy {
+	case model.GroupByWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case model.GroupByMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+type accumulator struct {
+	result        model.PipelineStats
+	decisionHours []float64
+	approved      int
+	rejected      int
+}
+
+func newAccumulator() *accumulator {
+	return &accumulator{result: model.PipelineStats{
+		ByStatus:       make(map[string]int),
+		ByPurpose:      make(map[string]model.PurposeStats),
+		TotalRequested: make(map[string]float64),
+		TotalApproved:  make(map[string]float64),
+	}}
+}
+
+func (a *accumulator) add(app model.LoanApplication) {
+	a.result.Total++
+	a.result.ByStatus[app.Status]++
+	currency := model.Currency(app.Country)
+	a.result.TotalRequested[currency] += app.LoanAmount
+
+	purpose := a.result.ByPurpose[model.LoanPurposeKey(app.LoanPurpose)]
+	purpose.Count++
+	if purpose.Amount == nil {
+		purpose.Amount = make(map[string]float64)
+	}
+	purpose.Amount[currency] += app.LoanAmount
+	a.result.ByPurpose[model.LoanPurposeKey(app.LoanPurpose)] = purpose
+
+	switch {
+	case model.IsApproved(app.Status):
+		a.approved++
+		a.result.TotalApproved[currency] += app.LoanAmount
+	case app.Status == model.StatusRejected:
+		a.rejected++
+	default:
+		return
+	}
+	if app.ProcessedAt != nil {
+		a.decisionHours = append(a.decisionHours, app.ProcessedAt.Sub(app.SubmittedAt).Hours())
+	}
+}
+
+func (a *accumulator) stats() model.PipelineStats {
+	result := a.result
+	if decided := a.approved + a.rejected; decided > 0 {
+		result.ApprovalRate = round(float64(a.approved) / float64(decided))
+		result.RejectionRate = round(float64(a.rejected) / float64(decided))
+	}
+	if len(a.decisionHours) > 0 {
+		sorted := append([]float64(nil), a.decisionHours...)
+		sort.Float64s(sorted)
+		median := round(Percentile(sorted, 50))
+		p90 := round(Percentile(sorted, 90))
+		result.MedianDecisionHours = &median
+		result.P90DecisionHours = &p90
+	}
+	return result
+}
+
+// Percentile returns the p-th percentile of sorted values using linear
+// interpolation between the closest ranks.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	weight := rank - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*weight
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
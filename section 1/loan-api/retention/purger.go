@@ -0,0 +1,179 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"loan-api/events"
+	"loan-api/model"
+	"loan-api/store"
+)
+
+// System is the actor recorded in the audit log for removals made by the
+// purge job.
+var System = model.Principal{Subject: "retention", Role: "system"}
+
+// Stats describes the purge job. Held counts the applications that were due
+// for removal but on legal hold at the last run; the totals count removals.
+type Stats struct {
+	Held            int       `json:"held"`
+	AnonymizedTotal int64     `json:"anonymized_total"`
+	DeletedTotal    int64     `json:"deleted_total"`
+	LastRun         time.Time `json:"last_run"`
+}
+
+// Purger removes applications once their retention period has passed and
+// erases applications on request. Removing an application also removes its
+// uploaded files and notes, records an audit entry and publishes an event.
+type Purger struct {
+	Store  *store.MemoryStore
+	Notes  *store.NoteStore
+	Audit  *store.AuditStore
+	Events *events.Bus
+	// Rules decide when applications are removed. When several rules of a
+	// status have expired, the one with the longest period applies.
+	Rules []model.RetentionRule
+	Now   func() time.Time
+
+	lock  sync.Mutex
+	stats Stats
+}
+
+func NewPurger(s *store.MemoryStore, notes *store.NoteStore, audit *store.AuditStore, bus *events.Bus) *Purger {
+	return &Purger{Store: s, Notes: notes, Audit: audit, Events: bus, Now: time.Now}
+}
+
+// Run purges every interval until ctx is cancelled.
+func (p *Purger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	p.Purge()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Purge()
+		}
+	}
+}
+
+// Purge applies the retention rules to every application of every tenant
+// once and returns the updated stats.
+func (p *Purger) Purge() Stats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.Now()
+	p.stats.Held = 0
+	for _, apps := range p.Store.Tenants() {
+		apps.EachLoanApplication(func(app model.LoanApplication) bool {
+			rule := p.Due(app, now)
+			if rule == nil {
+				return true
+			}
+			reason := fmt.Sprintf("retention: %s for %d days", rule.Status, rule.AfterDays)
+			var err error
+			if rule.Action == model.RetentionDelete {
+				_, err = p.Delete(app.Tenant, app.ID, System, reason)
+			} else {
+				_, err = p.Anonymize(app.Tenant, app.ID, System, reason)
+			}
+			switch {
+			case errors.Is(err, store.ErrLegalHold):
+				p.stats.Held++
+			case err != nil:
+			case rule.Action == model.RetentionDelete:
+				p.stats.DeletedTotal++
+			default:
+				p.stats.AnonymizedTotal++
+			}
+			return true
+		})
+	}
+	p.stats.LastRun = now
+	return p.stats
+}
+
+func (p *Purger) Stats() Stats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.stats
+}
+
+// Due returns the rule to apply to app at now, or nil when none has expired.
+// Applications that are already anonymized are only subject to delete rules.
+func (p *Purger) Due(app model.LoanApplication, now time.Time) *model.RetentionRule {
+	since := app.StatusUpdatedAt
+	if since.IsZero() {
+		since = app.SubmittedAt
+	}
+
+	var due *model.RetentionRule
+	for i, rule := range p.Rules {
+		switch {
+		case rule.Status != app.Status:
+		case now.Sub(since) < time.Duration(rule.AfterDays)*24*time.Hour:
+		case rule.Action == model.RetentionAnonymize && app.AnonymizedAt != nil:
+		case due == nil || rule.AfterDays > due.AfterDays:
+			due = &p.Rules[i]
+		}
+	}
+	return due
+}
+
+// Anonymize erases the personal data of one of tenant's applications and
+// removes its documents and notes. It fails with store.ErrLegalHold while the
+// application is on hold.
+func (p *Purger) Anonymize(tenant string, id int, actor model.Principal, reason string) (model.LoanApplication, error) {
+	before, app, err := p.Store.ForTenant(tenant).AnonymizeLoanApplication(id, p.Now())
+	if err != nil {
+		return app, err
+	}
+	p.removed(tenant, before, app, actor, reason, model.AuditApplicationAnonymized, model.EventApplicationAnonymized)
+	return app, nil
+}
+
+// Delete removes one of tenant's applications together with its documents
+// and notes and returns its anonymized last state. It fails with
+// store.ErrLegalHold while the application is on hold.
+func (p *Purger) Delete(tenant string, id int, actor model.Principal, reason string) (model.LoanApplication, error) {
+	before, err := p.Store.ForTenant(tenant).DeleteLoanApplication(id)
+	if err != nil {
+		return before, err
+	}
+	app := model.Anonymize(before, p.Now())
+	p.removed(tenant, before, app, actor, reason, model.AuditApplicationDeleted, model.EventApplicationDeleted)
+	return app, nil
+}
+
+func (p *Purger) removed(tenant string, before, after model.LoanApplication, actor model.Principal, reason, action, eventType string) {
+	for _, document := range before.Documents {
+		if document.Path == "" {
+			continue
+		}
+		if err := os.Remove(document.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Retention - failed to remove document %s of application %d: %v", document.Name, before.ID, err)
+		}
+	}
+	p.Notes.ForTenant(tenant).DeleteNotes(before.ID)
+	p.Audit.ForTenant(tenant).Record(model.AuditEntry{
+		Actor:  actor.Subject,
+		Role:   actor.Role,
+		Action: action,
+		Details: map[string]string{
+			"application_id": strconv.Itoa(before.ID),
+			"reason":         reason,
+		},
+	})
+	if p.Events != nil {
+		p.Events.Publish(eventType, after, map[string]string{"reason": reason})
+	}
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"loan-api/handler"
+	"loan-api/middleware"
+	loanv1 "loan-api/proto/loan/v1"
+)
+
+// NewGRPCServer returns a gRPC server for loans that is traced and
+// authenticated like the REST routes.
+func NewGRPCServer(loans *handler.LoanGRPCServer) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(middleware.UnaryAuthInterceptor()),
+		grpc.ChainStreamInterceptor(middleware.StreamAuthInterceptor()),
+	)
+	loanv1.RegisterLoanServiceServer(server, loans)
+	return server
+}
@@ -1,24 +1,162 @@
 package routes
 
 import (
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"loan-api/apperror"
 	"loan-api/handler"
 	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/serializer"
+	"loan-api/tracing"
 )
 
-func SetupRoutes(router *gin.Engine, loanHandler *handler.LoanHandler) {
+// Handlers groups the HTTP handlers served by the API. Optional handlers that
+// are left nil do not have their routes registered.
+type Handlers struct {
+	Loan       *handler.LoanHandler
+	Webhook    *handler.WebhookHandler
+	Stream     *handler.StreamHandler
+	Import     *handler.ImportHandler
+	Export     *handler.ExportHandler
+	Audit      *handler.AuditHandler
+	Report     *handler.ReportHandler
+	Assignment *handler.AssignmentHandler
+	Note       *handler.NoteHandler
+	Retention  *handler.RetentionHandler
+	Health     *handler.HealthHandler
+}
+
+func SetupRoutes(router *gin.Engine, h Handlers) {
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(func(c *gin.Context) { apperror.Respond(c, apperror.ErrRouteNotFound) })
+	router.NoMethod(func(c *gin.Context) { apperror.Respond(c, apperror.ErrMethodNotAllowed) })
+
+	router.Use(otelgin.Middleware(tracing.ServiceName))
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.MetricsMiddleware())
 	router.Use(middleware.ErrorRecoveryMiddleware())
 	router.Use(middleware.RequestLoggerMiddleware())
 	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
 
-	authenticated := router.Group("/")
+	if h.Health != nil {
+		router.GET("/healthz", h.Health.Liveness)
+		router.GET("/readyz", h.Health.Readiness)
+	}
+
+	registerAPI(router.Group("/v1", middleware.APIVersion(serializer.V1)), h)
+	registerAPI(router.Group("/v2", middleware.APIVersion(serializer.V2)), h)
+	// The routes were first served without a version prefix. They stay as
+	// deprecated aliases of v1 until clients have moved.
+	registerAPI(router.Group("/", middleware.DeprecatedAlias(serializer.V1)), h)
+}
+
+// registerAPI registers the versioned API routes on group. Every version
+// shares the handlers, which shape their responses by the version set on the
+// group.
+func registerAPI(group *gin.RouterGroup, h Handlers) {
+	authenticated := group.Group("/")
 	authenticated.Use(middleware.AuthMiddleware())
 	{
-		authenticated.GET("/loan-applications", loanHandler.ListLoanApplications)
-		authenticated.GET("/loan-applications/:id", loanHandler.GetLoanApplication)
-		authenticated.POST("/loan-applications", loanHandler.SubmitLoanApplication)
-		authenticated.PUT("/loan-applications/:id/status", loanHandler.UpdateLoanApplicationStatus)
-		authenticated.POST("/loan-applications/:id/documents", loanHandler.UploadSupportingDocuments)
+		authenticated.GET("/loan-applications", h.Loan.ListLoanApplications)
+		authenticated.GET("/loan-applications/:id", h.Loan.GetLoanApplication)
+		authenticated.POST("/loan-applications", h.Loan.SubmitLoanApplication)
+		authenticated.POST("/loan-applications/:id/documents", h.Loan.UploadSupportingDocuments)
+		authenticated.GET("/loan-applications/:id/checklist", h.Loan.GetChecklist)
+	}
+
+	staff := middleware.RequireRole(model.RoleAdmin, model.RoleOfficer)
+	authenticated.PUT("/loan-applications/:id/status", staff, h.Loan.UpdateLoanApplicationStatus)
+	authenticated.POST("/loan-applications/:id/conditions", staff, h.Loan.AddCondition)
+	if h.Loan.History != nil {
+		authenticated.GET("/loan-applications/:id/history", h.Loan.GetHistory)
+	}
+
+	actions := map[string][]gin.HandlerFunc{}
+
+	if h.Import != nil {
+		actions["import"] = []gin.HandlerFunc{h.Import.ImportLoanApplications}
+		authenticated.GET("/loan-applications/imports/:id", h.Import.GetImportJob)
+	}
+
+	if h.Assignment != nil {
+		actions["claim"] = []gin.HandlerFunc{staff, h.Assignment.ClaimNextLoanApplication}
+		authenticated.POST("/loan-applications/:id/assign", staff, h.Assignment.AssignLoanApplication)
+		authenticated.GET("/me/queue", staff, h.Assignment.MyQueue)
+		authenticated.GET("/officers/workload", staff, h.Assignment.ListWorkloads)
+	}
+
+	if h.Note != nil {
+		authenticated.GET("/loan-applications/:id/notes", h.Note.ListNotes)
+		authenticated.POST("/loan-applications/:id/notes", h.Note.CreateNote)
+		authenticated.PATCH("/loan-applications/:id/notes/:noteId", h.Note.UpdateNote)
+		authenticated.GET("/me/mentions", staff, h.Note.MyMentions)
+	}
+
+	if len(actions) > 0 {
+		authenticated.POST("/loan-applications:action", customMethods(actions))
+	}
+
+	if h.Export != nil {
+		authenticated.GET("/loan-applications/export", h.Export.ExportLoanApplications)
+	}
+
+	if h.Retention != nil {
+		admin := middleware.RequireRole(model.RoleAdmin)
+		authenticated.POST("/erasure-requests", admin, h.Retention.EraseSubject)
+		authenticated.PUT("/loan-applications/:id/legal-hold", admin, h.Retention.PlaceLegalHold)
+		authenticated.DELETE("/loan-applications/:id/legal-hold", admin, h.Retention.LiftLegalHold)
+	}
+
+	if h.Audit != nil {
+		authenticated.GET("/audit-log", middleware.RequireRole(model.RoleAdmin), h.Audit.ListAuditLog)
+	}
+
+	if h.Report != nil {
+		authenticated.GET("/reports/pipeline", staff, h.Report.PipelineReport)
+		if h.Report.SLA != nil {
+			authenticated.GET("/reports/sla", staff, h.Report.SLAReport)
+		}
+	}
+
+	if h.Webhook != nil {
+		// Subscriptions receive every event of the tenant, including internal
+		// notes, so only staff manage them.
+		webhooks := authenticated.Group("/webhooks", staff)
+		webhooks.GET("", h.Webhook.ListWebhooks)
+		webhooks.POST("", h.Webhook.CreateWebhook)
+		webhooks.GET("/:id", h.Webhook.GetWebhook)
+		webhooks.PUT("/:id", h.Webhook.UpdateWebhook)
+		webhooks.DELETE("/:id", h.Webhook.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", h.Webhook.ListDeliveries)
+		webhooks.POST("/:id/deliveries/:deliveryId/redeliver", h.Webhook.RedeliverDelivery)
+	}
+
+	if h.Stream != nil {
+		authenticated.GET("/events/stream", h.Stream.StreamEvents)
+		authenticated.GET("/events/ws", h.Stream.StreamEventsWebSocket)
+	}
+}
+
+// customMethods dispatches custom methods such as /loan-applications:import.
+// Gin has no way to register a literal colon, so the route is registered as a
+// parameter and unknown methods are reported as missing routes. Each method
+// runs its handlers in order until one aborts.
+func customMethods(methods map[string][]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name, ok := strings.CutPrefix(c.Param("action"), ":")
+		handlers, found := methods[name]
+		if !ok || !found {
+			apperror.Respond(c, apperror.ErrRouteNotFound)
+			return
+		}
+		for _, handle := range handlers {
+			if c.IsAborted() {
+				return
+			}
+			handle(c)
+		}
 	}
 }
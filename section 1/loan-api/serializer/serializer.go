@@ -0,0 +1,254 @@
+package serializer
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"loan-api/model"
+)
+
+// Versions of the REST API. V1 is the original shape of every response and is
+// also served by the unversioned routes.
+const (
+	V1 = "v1"
+	V2 = "v2"
+)
+
+// Money is an amount written as a decimal string, so clients never have to
+// round it through a float.
+type Money struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func NewMoney(amount float64, currency string) Money {
+	return Money{Amount: strconv.FormatFloat(amount, 'f', 2, 64), Currency: currency}
+}
+
+// List is one page of a longer list. V1 writes the items alone; V2 wraps them
+// in an Envelope together with the paging details.
+type List struct {
+	Items any
+	Page  int
+	Limit int
+	Total int
+}
+
+// Envelope wraps every list response in V2, so metadata can be added later
+// without breaking clients.
+type Envelope struct {
+	Data       any         `json:"data"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+	Total int `json:"total"`
+}
+
+// LoanApplication is the V2 shape of an application. Its amounts shadow the
+// float fields of the embedded application.
+type LoanApplication struct {
+	model.LoanApplication
+	LoanAmount   Money `json:"loan_amount"`
+	AnnualIncome Money `json:"annual_income"`
+}
+
+func NewLoanApplication(app model.LoanApplication) LoanApplication {
+	currency := model.Currency(app.Country)
+	return LoanApplication{
+		LoanApplication: app,
+		LoanAmount:      NewMoney(app.LoanAmount, currency),
+		AnnualIncome:    NewMoney(app.AnnualIncome, currency),
+	}
+}
+
+// Event is the V2 shape of a stream event, carrying the V2 application.
+type Event struct {
+	model.Event
+	Application LoanApplication `json:"application"`
+}
+
+func NewEvent(event model.Event) Event {
+	return Event{Event: event, Application: NewLoanApplication(event.Application)}
+}
+
+// PipelineReport is the V2 shape of a pipeline report. Amounts of different
+// currencies cannot be added up, so every total is a list of Money with one
+// entry per currency, sorted by currency.
+type PipelineReport struct {
+	model.PipelineReport
+	Summary PipelineStats    `json:"summary"`
+	Periods []PipelinePeriod `json:"periods"`
+}
+
+type PipelinePeriod struct {
+	Start string `json:"start"`
+	PipelineStats
+}
+
+type PipelineStats struct {
+	model.PipelineStats
+	ByPurpose      map[string]PurposeStats `json:"by_purpose"`
+	TotalRequested []Money                 `json:"total_requested"`
+	TotalApproved  []Money                 `json:"total_approved"`
+}
+
+type PurposeStats struct {
+	Count  int     `json:"count"`
+	Amount []Money `json:"amount"`
+}
+
+func NewPipelineReport(report model.PipelineReport) PipelineReport {
+	shaped := PipelineReport{
+		PipelineReport: report,
+		Summary:        newPipelineStats(report.Summary),
+		Periods:        make([]PipelinePeriod, 0, len(report.Periods)),
+	}
+	for _, period := range report.Periods {
+		shaped.Periods = append(shaped.Periods, PipelinePeriod{Start: period.Start, PipelineStats: newPipelineStats(period.PipelineStats)})
+	}
+	return shaped
+}
+
+func newPipelineStats(stats model.PipelineStats) PipelineStats {
+	shaped := PipelineStats{
+		PipelineStats:  stats,
+		ByPurpose:      make(map[string]PurposeStats, len(stats.ByPurpose)),
+		TotalRequested: moneyByCurrency(stats.TotalRequested),
+		TotalApproved:  moneyByCurrency(stats.TotalApproved),
+	}
+	for purpose, purposeStats := range stats.ByPurpose {
+		shaped.ByPurpose[purpose] = PurposeStats{Count: purposeStats.Count, Amount: moneyByCurrency(purposeStats.Amount)}
+	}
+	return shaped
+}
+
+func moneyByCurrency(amounts map[string]float64) []Money {
+	currencies := make([]string, 0, len(amounts))
+	for currency := range amounts {
+		currencies = append(currencies, currency)
+	}
+	slices.Sort(currencies)
+	money := make([]Money, 0, len(currencies))
+	for _, currency := range currencies {
+		money = append(money, NewMoney(amounts[currency], currency))
+	}
+	return money
+}
+
+// Serialize returns v in the shape of version, ready to be encoded as JSON.
+// V1 returns v unchanged apart from unwrapping a List. V2 wraps lists in an
+// Envelope, writes amounts as Money and every timestamp in UTC, which
+// encoding/json writes as RFC 3339 with the fractional seconds that are set.
+func Serialize(version string, v any) any {
+	if version != V2 {
+		if list, ok := v.(List); ok {
+			return list.Items
+		}
+		return v
+	}
+	shaped := utc(reflect.ValueOf(shapeV2(v)))
+	if !shaped.IsValid() {
+		return nil
+	}
+	return shaped.Interface()
+}
+
+func shapeV2(v any) any {
+	switch v := v.(type) {
+	case List:
+		return Envelope{
+			Data:       shapeItems(v.Items),
+			Pagination: &Pagination{Page: v.Page, Limit: v.Limit, Total: v.Total},
+		}
+	case model.LoanApplication:
+		return NewLoanApplication(v)
+	case model.Event:
+		return NewEvent(v)
+	case model.PipelineReport:
+		return NewPipelineReport(v)
+	}
+	if v != nil && reflect.TypeOf(v).Kind() == reflect.Slice {
+		return Envelope{Data: shapeItems(v)}
+	}
+	return v
+}
+
+// shapeItems converts applications to their V2 shape and turns a nil slice
+// into an empty one, so Data is never null.
+func shapeItems(items any) any {
+	if apps, ok := items.([]model.LoanApplication); ok {
+		shaped := make([]LoanApplication, 0, len(apps))
+		for _, app := range apps {
+			shaped = append(shaped, NewLoanApplication(app))
+		}
+		return shaped
+	}
+	value := reflect.ValueOf(items)
+	if value.Kind() == reflect.Slice && value.IsNil() {
+		return reflect.MakeSlice(value.Type(), 0, 0).Interface()
+	}
+	return items
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// utc returns a copy of v in which every time.Time reachable through exported
+// fields, pointers, slices, maps and interfaces is in UTC.
+func utc(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	if v.Type() == timeType {
+		return reflect.ValueOf(v.Interface().(time.Time).UTC())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(utc(v.Elem()))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(utc(v.Elem()))
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range v.NumField() {
+			if field := out.Field(i); field.CanSet() {
+				field.Set(utc(v.Field(i)))
+			}
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(utc(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			out.SetMapIndex(iter.Key(), utc(iter.Value()))
+		}
+		return out
+	}
+	return v
+}
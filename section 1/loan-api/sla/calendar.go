@@ -0,0 +1,62 @@
+package sla
+
+import (
+	"time"
+)
+
+// Calendar counts business days: Monday to Friday, excluding holidays, in a
+// single time zone.
+type Calendar struct {
+	Location *time.Location
+	holidays map[string]bool
+}
+
+// NewCalendar returns a calendar for loc with the given YYYY-MM-DD holidays.
+func NewCalendar(loc *time.Location, holidays []string) (*Calendar, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	c := &Calendar{Location: loc, holidays: make(map[string]bool)}
+	for _, h := range holidays {
+		day, err := time.ParseInLocation(time.DateOnly, h, loc)
+		if err != nil {
+			return nil, err
+		}
+		c.holidays[day.Format(time.DateOnly)] = true
+	}
+	return c, nil
+}
+
+// IsBusinessDay reports whether t falls on a working day.
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	t = t.In(c.Location)
+	switch t.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	return !c.holidays[t.Format(time.DateOnly)]
+}
+
+// AddBusinessDays returns the time days business days after start, at the
+// same time of day. A start outside business days counts from the beginning
+// of the next business day.
+func (c *Calendar) AddBusinessDays(start time.Time, days int) time.Time {
+	t := start.In(c.Location)
+	if !c.IsBusinessDay(t) {
+		t = startOfDay(t)
+		for !c.IsBusinessDay(t) {
+			t = t.AddDate(0, 0, 1)
+		}
+	}
+	for days > 0 {
+		t = t.AddDate(0, 0, 1)
+		if c.IsBusinessDay(t) {
+			days--
+		}
+	}
+	return t
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
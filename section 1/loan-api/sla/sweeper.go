@@ -0,0 +1,178 @@
+package sla
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"loan-api/events"
+	"loan-api/model"
+	"loan-api/store"
+)
+
+// Stats describes the SLA sweeper's view of one tenant's pipeline, or of all
+// tenants together. AtRisk and Breached count applications as of the last
+// sweep; the totals count emitted events.
+type Stats struct {
+	AtRisk        int       `json:"at_risk"`
+	Breached      int       `json:"breached"`
+	AtRiskTotal   int64     `json:"at_risk_total"`
+	BreachedTotal int64     `json:"breached_total"`
+	LastSweep     time.Time `json:"last_sweep"`
+}
+
+// Sweeper periodically evaluates every open application against the deadline
+// of its current status and publishes an event when an application becomes at
+// risk or breaches its SLA.
+type Sweeper struct {
+	Store    *store.MemoryStore
+	Events   *events.Bus
+	Calendar *Calendar
+	// Deadlines maps a status to the business days an application may spend
+	// in it. Statuses without a deadline have no SLA.
+	Deadlines map[string]int
+	// Warning is how long before the deadline an application is at risk.
+	Warning time.Duration
+	Now     func() time.Time
+
+	lock      sync.Mutex
+	stats     map[string]Stats
+	lastSweep time.Time
+}
+
+func NewSweeper(s *store.MemoryStore, bus *events.Bus, calendar *Calendar) *Sweeper {
+	return &Sweeper{
+		Store:    s,
+		Events:   bus,
+		Calendar: calendar,
+		Deadlines: map[string]int{
+			model.StatusPending:     2,
+			model.StatusUnderReview: 5,
+		},
+		Warning: 4 * time.Hour,
+		Now:     time.Now,
+		stats:   make(map[string]Stats),
+	}
+}
+
+// Run sweeps every interval until ctx is cancelled.
+func (s *Sweeper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.Sweep()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sweep()
+		}
+	}
+}
+
+// Sweep evaluates every application of every tenant once and returns the
+// updated stats of all tenants together.
+func (s *Sweeper) Sweep() Stats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.Now()
+	total := Stats{LastSweep: now}
+	for _, tenant := range s.Store.Tenants() {
+		stats := s.stats[tenant.Tenant()]
+		stats.AtRisk, stats.Breached = 0, 0
+		s.sweep(tenant, now, &stats)
+		stats.LastSweep = now
+		s.stats[tenant.Tenant()] = stats
+
+		total.AtRisk += stats.AtRisk
+		total.Breached += stats.Breached
+		total.AtRiskTotal += stats.AtRiskTotal
+		total.BreachedTotal += stats.BreachedTotal
+	}
+	s.lastSweep = now
+	return total
+}
+
+// sweep evaluates the applications of one tenant's store into its stats.
+func (s *Sweeper) sweep(apps *store.MemoryStore, now time.Time, stats *Stats) {
+	apps.EachLoanApplication(func(app model.LoanApplication) bool {
+		next := s.Evaluate(app, now)
+		if next != nil {
+			switch next.State {
+			case model.SLAAtRisk:
+				stats.AtRisk++
+			case model.SLABreached:
+				stats.Breached++
+			}
+		}
+		if sameSLA(app.SLA, next) {
+			return true
+		}
+
+		updated, ok := apps.UpdateSLA(app.ID, app.Status, next)
+		if !ok || next == nil || (app.SLA != nil && app.SLA.State == next.State) {
+			return true
+		}
+		switch next.State {
+		case model.SLAAtRisk:
+			stats.AtRiskTotal++
+			s.publish(model.EventSLAAtRisk, updated)
+		case model.SLABreached:
+			stats.BreachedTotal++
+			s.publish(model.EventSLABreached, updated)
+		}
+		return true
+	})
+}
+
+// Stats returns the stats of one tenant as of the last sweep.
+func (s *Sweeper) Stats(tenant string) Stats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stats := s.stats[model.TenantID(tenant)]
+	stats.LastSweep = s.lastSweep
+	return stats
+}
+
+// Evaluate returns the SLA of app at now, or nil when its status has no deadline.
+func (s *Sweeper) Evaluate(app model.LoanApplication, now time.Time) *model.SLA {
+	days, ok := s.Deadlines[app.Status]
+	if !ok {
+		return nil
+	}
+
+	since := app.StatusUpdatedAt
+	if since.IsZero() {
+		since = app.SubmittedAt
+	}
+	sla := &model.SLA{State: model.SLAOnTrack, DueAt: s.Calendar.AddBusinessDays(since, days).UTC()}
+	switch {
+	case !now.Before(sla.DueAt):
+		sla.State = model.SLABreached
+		breachedAt := sla.DueAt
+		sla.BreachedAt = &breachedAt
+	case sla.DueAt.Sub(now) <= s.Warning:
+		sla.State = model.SLAAtRisk
+	}
+	return sla
+}
+
+func (s *Sweeper) publish(eventType string, app model.LoanApplication) {
+	if s.Events == nil {
+		return
+	}
+	s.Events.Publish(eventType, app, map[string]string{
+		"status": app.Status,
+		"due_at": app.SLA.DueAt.Format(time.RFC3339),
+	})
+}
+
+func sameSLA(a, b *model.SLA) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.State == b.State && a.DueAt.Equal(b.DueAt)
+}
@@ -0,0 +1,167 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"loan-api/model"
+)
+
+var (
+	ErrApplicationNotFound = errors.New("loan application not found")
+	ErrQueueEmpty          = errors.New("no unassigned pending applications")
+	ErrCapacityReached     = errors.New("officer has no capacity left")
+	ErrAlreadyDecided      = errors.New("loan application is already decided")
+)
+
+// AssignLoanApplication assigns the application to officer, replacing any
+// previous assignment. Decided applications cannot be assigned, and an
+// officer at capacity only keeps the applications they already hold.
+func (s *MemoryStore) AssignLoanApplication(id int, officer model.Officer) (model.LoanApplication, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	app, found := s.applications[id]
+	if !found {
+		return app, ErrApplicationNotFound
+	}
+	if model.IsDecided(app.Status) {
+		return app, ErrAlreadyDecided
+	}
+	if app.AssignedTo != officer.Subject && !s.workloads([]model.Officer{officer})[0].HasCapacity() {
+		return app, ErrCapacityReached
+	}
+	return s.assign(app, officer.Subject), nil
+}
+
+// AutoAssignLoanApplication assigns the application to the officer with the
+// lowest workload relative to capacity. Officers at capacity are skipped.
+func (s *MemoryStore) AutoAssignLoanApplication(id int, officers []model.Officer) (model.LoanApplication, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	app, found := s.applications[id]
+	if !found {
+		return app, ErrApplicationNotFound
+	}
+	if model.IsDecided(app.Status) {
+		return app, ErrAlreadyDecided
+	}
+
+	workloads := s.workloads(officers)
+	maxCapacity := 1
+	for _, w := range workloads {
+		maxCapacity = max(maxCapacity, w.Capacity)
+	}
+	var best *model.OfficerWorkload
+	for _, w := range workloads {
+		if !w.HasCapacity() {
+			continue
+		}
+		if best == nil || lessLoaded(w, *best, maxCapacity) {
+			best = &w
+		}
+	}
+	if best == nil {
+		return app, ErrCapacityReached
+	}
+	return s.assign(app, best.Subject), nil
+}
+
+// ClaimNextLoanApplication atomically assigns the oldest unassigned pending
+// application to officer. A capacity of zero means unlimited.
+func (s *MemoryStore) ClaimNextLoanApplication(officer string, capacity int) (model.LoanApplication, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	workload := s.workloads([]model.Officer{{Subject: officer, Capacity: capacity}})[0]
+	if !workload.HasCapacity() {
+		return model.LoanApplication{}, ErrCapacityReached
+	}
+
+	var next *model.LoanApplication
+	for _, app := range s.applications {
+		if app.AssignedTo != "" || app.Status != model.StatusPending {
+			continue
+		}
+		if next == nil || app.SubmittedAt.Before(next.SubmittedAt) ||
+			(app.SubmittedAt.Equal(next.SubmittedAt) && app.ID < next.ID) {
+			next = &app
+		}
+	}
+	if next == nil {
+		return model.LoanApplication{}, ErrQueueEmpty
+	}
+	return s.assign(*next, officer), nil
+}
+
+// Workloads returns the open assignments of each officer.
+func (s *MemoryStore) Workloads(officers []model.Officer) []model.OfficerWorkload {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.workloads(officers)
+}
+
+// AssignedTo returns the open applications assigned to officer, oldest first.
+func (s *MemoryStore) AssignedTo(officer string) []model.LoanApplication {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	result := []model.LoanApplication{}
+	for _, app := range s.applications {
+		if app.AssignedTo == officer && model.IsOpenAssignment(app) {
+			result = append(result, app)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].SubmittedAt.Equal(result[j].SubmittedAt) {
+			return result[i].SubmittedAt.Before(result[j].SubmittedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// workloads must be called with the lock held.
+func (s *MemoryStore) workloads(officers []model.Officer) []model.OfficerWorkload {
+	open := make(map[string]int)
+	for _, app := range s.applications {
+		if model.IsOpenAssignment(app) {
+			open[app.AssignedTo]++
+		}
+	}
+	result := make([]model.OfficerWorkload, 0, len(officers))
+	for _, officer := range officers {
+		result = append(result, model.OfficerWorkload{Officer: officer, Open: open[officer.Subject]})
+	}
+	return result
+}
+
+// assign must be called with the lock held.
+func (s *MemoryStore) assign(app model.LoanApplication, officer string) model.LoanApplication {
+	now := time.Now()
+	return s.append(app.ID, now, assigned{officer: officer, at: now})
+}
+
+// lessLoaded orders workloads by open/capacity, then open count, then subject.
+// Unlimited officers count as having maxCapacity, the largest capacity among
+// the candidates, so they are ordered by open count and do not win every tie.
+func lessLoaded(a, b model.OfficerWorkload, maxCapacity int) bool {
+	ra, rb := loadRatio(a, maxCapacity), loadRatio(b, maxCapacity)
+	if ra != rb {
+		return ra < rb
+	}
+	if a.Open != b.Open {
+		return a.Open < b.Open
+	}
+	return a.Subject < b.Subject
+}
+
+func loadRatio(w model.OfficerWorkload, maxCapacity int) float64 {
+	capacity := w.Capacity
+	if capacity == 0 {
+		capacity = maxCapacity
+	}
+	return float64(w.Open) / float64(capacity)
+}
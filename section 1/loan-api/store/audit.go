@@ -0,0 +1,50 @@
+package store
+
+import (
+	"loan-api/model"
+	"sync"
+	"time"
+)
+
+// AuditStore is an append-only log of audit entries.
+type AuditStore struct {
+	tenants *tenants[*AuditStore]
+	entries []model.AuditEntry
+	lock    sync.RWMutex
+}
+
+func NewAuditStore() *AuditStore {
+	return newTenants(func(_ string, registry *tenants[*AuditStore]) *AuditStore {
+		return &AuditStore{tenants: registry}
+	})
+}
+
+// ForTenant returns tenant's audit log.
+func (s *AuditStore) ForTenant(tenant string) *AuditStore {
+	return s.tenants.get(tenant)
+}
+
+func (s *AuditStore) Record(entry model.AuditEntry) model.AuditEntry {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	entry.ID = len(s.entries) + 1
+	entry.OccurredAt = time.Now()
+	s.entries = append(s.entries, entry)
+	return entry
+}
+
+// List returns the entries for action, or every entry when action is empty,
+// newest first.
+func (s *AuditStore) List(action string) []model.AuditEntry {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	result := []model.AuditEntry{}
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if action == "" || s.entries[i].Action == action {
+			result = append(result, s.entries[i])
+		}
+	}
+	return result
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"loan-api/model"
+)
+
+var (
+	ErrInvalidTransition     = errors.New("loan application cannot move to this status")
+	ErrNoConditions          = errors.New("loan application has no conditions")
+	ErrConditionsOutstanding = errors.New("loan application has outstanding conditions")
+	ErrChecklistIncomplete   = errors.New("loan application is missing required documents")
+)
+
+// AddCondition attaches a new condition to the application. Rejected and
+// funded applications are final and fail with ErrAlreadyDecided.
+func (s *MemoryStore) AddCondition(id int, condition model.Condition) (model.LoanApplication, model.Condition, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	app, found := s.applications[id]
+	switch {
+	case !found:
+		return app, condition, ErrApplicationNotFound
+	case model.IsFinal(app.Status):
+		return app, condition, ErrAlreadyDecided
+	}
+
+	condition.ID = len(app.Conditions) + 1
+	condition.CreatedAt = time.Now()
+	condition.SatisfiedBy = ""
+	condition.SatisfiedAt = nil
+	return s.append(id, condition.CreatedAt, conditionAdded{condition: condition}), condition, nil
+}
+
+// ChangeLoanApplicationStatus moves an application to status if
+// model.CanTransition allows it, failing with ErrInvalidTransition otherwise.
+// Approvals need the documents that checklists requires for the loan purpose
+// (ErrChecklistIncomplete), a conditional approval needs at least one
+// condition (ErrNoConditions) and funding needs every condition satisfied
+// (ErrConditionsOutstanding). A conditional approval may go ahead without
+// documents that outstanding conditions ask for, since only their upload
+// satisfies those conditions. The checks run under the same lock as the
+// update, so a concurrent upload or status change cannot slip in between. The
+// current application is returned on failure.
+func (s *MemoryStore) ChangeLoanApplicationStatus(id int, status string, checklists map[string][]string) (model.LoanApplication, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	app, found := s.applications[id]
+	switch {
+	case !found:
+		return app, ErrApplicationNotFound
+	case !model.CanTransition(app.Status, status):
+		return app, ErrInvalidTransition
+	case status == model.StatusApproved &&
+		!model.BuildChecklist(app, checklists[model.LoanPurposeKey(app.LoanPurpose)]).Complete:
+		return app, ErrChecklistIncomplete
+	case status == model.StatusApprovedWithConditions && len(app.Conditions) == 0:
+		return app, ErrNoConditions
+	case status == model.StatusApprovedWithConditions &&
+		len(model.UncoveredDocuments(app, model.BuildChecklist(app, checklists[model.LoanPurposeKey(app.LoanPurpose)]))) > 0:
+		return app, ErrChecklistIncomplete
+	case status == model.StatusFunded && len(model.OutstandingConditions(app)) > 0:
+		return app, ErrConditionsOutstanding
+	}
+
+	return s.setStatus(id, status), nil
+}
@@ -0,0 +1,188 @@
+package store
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"loan-api/model"
+)
+
+// maxNameDistance is the number of edits, such as a typo or a dropped
+// initial, that still make two applicant names near-identical. Names allow
+// one edit per five letters up to it, so short names must match exactly.
+const maxNameDistance = 2
+
+// DuplicateError is returned when a submission resembles earlier
+// applications and the policy rejects it. Nothing is saved.
+type DuplicateError struct {
+	Matches []model.DuplicateMatch
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("loan application duplicates application %d", e.Matches[0].ApplicationID)
+}
+
+// SubmitLoanApplication saves app like SaveLoanApplication, after comparing it
+// with the applications of the tenant submitted within rule.Window: those
+// with the same SSN, and those with a near-identical applicant name and loan
+// amount. Matches are rejected with a *DuplicateError, linked both ways or
+// flagged on the new application for review, depending on rule.Policy. The
+// SSN velocity is recorded either way.
+func (s *MemoryStore) SubmitLoanApplication(app model.LoanApplication, rule model.DuplicateRule) (model.LoanApplication, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	key := s.ssnKey(app.ApplicantSSN)
+	var matches []model.DuplicateMatch
+	if rule.Enabled() {
+		matches = s.findDuplicates(app, key, rule, now)
+	}
+	if len(matches) > 0 && rule.Policy == model.DuplicatePolicyReject {
+		return model.LoanApplication{}, &DuplicateError{Matches: matches}
+	}
+
+	app.DuplicateCheck = nil
+	app.LinkedApplications = nil
+	if len(matches) > 0 {
+		app.DuplicateCheck = &model.DuplicateCheck{Matches: matches, Action: model.DuplicateActionFlagged}
+		if rule.Policy == model.DuplicatePolicyLink {
+			app.DuplicateCheck.Action = model.DuplicateActionLinked
+			for _, match := range matches {
+				app.LinkedApplications = append(app.LinkedApplications, match.ApplicationID)
+			}
+		}
+	}
+	app.Velocity = s.velocity(key, now)
+	saved := s.save(app, now)
+	s.bySSN[key] = append(s.bySSN[key], saved.ID)
+
+	for _, id := range saved.LinkedApplications {
+		s.append(id, now, linked{id: saved.ID})
+	}
+	return saved, nil
+}
+
+// findDuplicates must be called with the lock held.
+func (s *MemoryStore) findDuplicates(app model.LoanApplication, key string, rule model.DuplicateRule, now time.Time) []model.DuplicateMatch {
+	since := now.Add(-rule.Window)
+	sameSSN := make(map[int]bool)
+	for _, id := range s.bySSN[key] {
+		sameSSN[id] = true
+	}
+	var matches []model.DuplicateMatch
+	name := normalizeName(app.ApplicantName)
+	maxDistance := min(maxNameDistance, len([]rune(name))/5)
+	for _, earlier := range s.applications {
+		if earlier.AnonymizedAt != nil || earlier.SubmittedAt.Before(since) {
+			continue
+		}
+		var reason string
+		switch {
+		case sameSSN[earlier.ID]:
+			reason = model.DuplicateSameSSN
+		case similarAmount(app.LoanAmount, earlier.LoanAmount, rule.AmountTolerance) &&
+			levenshtein(name, normalizeName(earlier.ApplicantName)) <= maxDistance:
+			reason = model.DuplicateSimilarApplicant
+		default:
+			continue
+		}
+		matches = append(matches, model.DuplicateMatch{ApplicationID: earlier.ID, Reason: reason, SubmittedAt: earlier.SubmittedAt})
+	}
+	slices.SortFunc(matches, func(a, b model.DuplicateMatch) int { return a.ApplicationID - b.ApplicationID })
+	return matches
+}
+
+// velocity must be called with the lock held. It counts the new application
+// in.
+func (s *MemoryStore) velocity(key string, now time.Time) *model.Velocity {
+	v := &model.Velocity{Last30Days: 1, Last90Days: 1}
+	for _, id := range s.bySSN[key] {
+		age := now.Sub(s.applications[id].SubmittedAt)
+		if age <= 90*24*time.Hour {
+			v.Last90Days++
+		}
+		if age <= 30*24*time.Hour {
+			v.Last30Days++
+		}
+	}
+	return v
+}
+
+// ApplicationsForSSN returns the IDs of the applications for ssn, oldest
+// first, however the SSN was formatted. Erased applications are not included.
+func (s *MemoryStore) ApplicationsForSSN(ssn string) []int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if !strings.ContainsFunc(ssn, unicode.IsDigit) {
+		return nil
+	}
+	return slices.Clone(s.bySSN[s.ssnKey(ssn)])
+}
+
+// forgetSSN must be called with the lock held. It removes an erased or
+// deleted application from the SSN index.
+func (s *MemoryStore) forgetSSN(app model.LoanApplication) {
+	key := s.ssnKey(app.ApplicantSSN)
+	s.bySSN[key] = slices.DeleteFunc(s.bySSN[key], func(id int) bool { return id == app.ID })
+	if len(s.bySSN[key]) == 0 {
+		delete(s.bySSN, key)
+	}
+}
+
+// ssnKey returns the HMAC-SHA256 of the digits of an SSN or NIK under the
+// store's secret, so keys do not depend on formatting. SSNs have few enough
+// digits that a plain hash could be reversed by hashing every number; without
+// the secret, the keys cannot be.
+func (s *MemoryStore) ssnKey(ssn string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, ssn)
+	mac := hmac.New(sha256.New, s.ssnSecret)
+	mac.Write([]byte(digits))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// normalizeName lowercases a name and keeps its letters and single spaces.
+func normalizeName(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool { return !unicode.IsLetter(r) })
+	return strings.Join(fields, " ")
+}
+
+func similarAmount(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance*math.Max(a, b)
+}
+
+// levenshtein returns the number of single rune insertions, deletions and
+// substitutions that turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
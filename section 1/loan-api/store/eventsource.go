@@ -0,0 +1,219 @@
+package store
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"loan-api/model"
+)
+
+// snapshotInterval is the number of events between two snapshots of an
+// application, which bounds the events replayed to rebuild a past state.
+const snapshotInterval = 20
+
+// change is what an event did to an application. Changes carry every value
+// they set, including times, so replaying them rebuilds the same state.
+type change interface {
+	apply(app model.LoanApplication) model.LoanApplication
+}
+
+// storedEvent is one change in an application's stream. Version counts the
+// application's events from 1.
+type storedEvent struct {
+	version    int
+	occurredAt time.Time
+	change     change
+}
+
+// snapshot is the state of an application after the event of its version.
+type snapshot struct {
+	version    int
+	occurredAt time.Time
+	app        model.LoanApplication
+}
+
+// stream is the event log of one application. It holds the changes needed to
+// rebuild past states, not the published lifecycle events; those are kept by
+// HistoryStore, which serves GET /loan-applications/:id/history.
+type stream struct {
+	events    []storedEvent
+	snapshots []snapshot
+}
+
+// append must be called with the lock held. It records c as the next event of
+// the application with the given ID and updates its projection in
+// s.applications, which it returns.
+func (s *MemoryStore) append(id int, occurredAt time.Time, c change) model.LoanApplication {
+	st := s.streams[id]
+	if st == nil {
+		st = &stream{}
+		s.streams[id] = st
+	}
+	app := c.apply(s.applications[id])
+	st.events = append(st.events, storedEvent{version: len(st.events) + 1, occurredAt: occurredAt, change: c})
+	if len(st.events)%snapshotInterval == 0 {
+		st.snapshots = append(st.snapshots, snapshot{version: len(st.events), occurredAt: occurredAt, app: app})
+	}
+	s.applications[id] = app
+	return app
+}
+
+// LoanApplicationAsOf returns the application as it was at the given time,
+// replayed from the latest snapshot taken by then. It reports false when the
+// application did not exist yet or has been deleted.
+func (s *MemoryStore) LoanApplicationAsOf(id int, at time.Time) (model.LoanApplication, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	st := s.streams[id]
+	if st == nil || len(st.events) == 0 || st.events[0].occurredAt.After(at) {
+		return model.LoanApplication{}, false
+	}
+
+	var app model.LoanApplication
+	next := 0
+	// Snapshots are in time order, so the last one taken by then is found by
+	// binary search.
+	if i := sort.Search(len(st.snapshots), func(i int) bool { return st.snapshots[i].occurredAt.After(at) }); i > 0 {
+		app, next = st.snapshots[i-1].app, st.snapshots[i-1].version
+	}
+	for _, event := range st.events[next:] {
+		if event.occurredAt.After(at) {
+			break
+		}
+		app = event.change.apply(app)
+	}
+	return app, true
+}
+
+// redactStream must be called with the lock held. It replaces every event and
+// snapshot of the application with its state at the time, anonymized, so
+// past states can still be audited without the personal data.
+func (s *MemoryStore) redactStream(id int, now time.Time) {
+	st := s.streams[id]
+	if st == nil {
+		return
+	}
+	var app model.LoanApplication
+	for i, event := range st.events {
+		app = event.change.apply(app)
+		st.events[i].change = replaced{app: redact(app, now)}
+	}
+	for i := range st.snapshots {
+		st.snapshots[i].app = redact(st.snapshots[i].app, now)
+	}
+}
+
+// redact anonymizes a past state, which keeps the anonymization time it had.
+func redact(app model.LoanApplication, now time.Time) model.LoanApplication {
+	anonymizedAt := app.AnonymizedAt
+	app = model.Anonymize(app, now)
+	app.AnonymizedAt = anonymizedAt
+	return app
+}
+
+type submitted struct{ app model.LoanApplication }
+
+func (c submitted) apply(model.LoanApplication) model.LoanApplication { return c.app }
+
+// replaced sets the whole state; erasure rewrites events into it.
+type replaced struct{ app model.LoanApplication }
+
+func (c replaced) apply(model.LoanApplication) model.LoanApplication { return c.app }
+
+type statusChanged struct {
+	status string
+	at     time.Time
+}
+
+func (c statusChanged) apply(app model.LoanApplication) model.LoanApplication {
+	wasDecided := model.IsDecided(app.Status)
+	app.Status = c.status
+	app.StatusUpdatedAt = c.at
+	// The SLA clock restarts with every status change; the sweeper sets it again.
+	app.SLA = nil
+	switch {
+	case !model.IsDecided(c.status):
+		app.ProcessedAt = nil
+	case !wasDecided || app.ProcessedAt == nil:
+		// ProcessedAt is the time of the decision, so funding keeps it.
+		at := c.at
+		app.ProcessedAt = &at
+	}
+	return app
+}
+
+type slaUpdated struct{ sla *model.SLA }
+
+func (c slaUpdated) apply(app model.LoanApplication) model.LoanApplication {
+	app.SLA = c.sla
+	return app
+}
+
+type documentAdded struct{ document model.Document }
+
+func (c documentAdded) apply(app model.LoanApplication) model.LoanApplication {
+	app, _ = addDocument(app, c.document)
+	return app
+}
+
+// addDocument records document, satisfying the oldest outstanding condition
+// asking for its type. It returns the index of that condition, or -1.
+func addDocument(app model.LoanApplication, document model.Document) (model.LoanApplication, int) {
+	app.DocumentsUploaded = append(slices.Clip(app.DocumentsUploaded), document.Name)
+	app.Documents = append(slices.Clip(app.Documents), document)
+	if document.Type == "" {
+		return app, -1
+	}
+	app.Conditions = slices.Clone(app.Conditions)
+	for i := range app.Conditions {
+		if app.Conditions[i].Matches(document.Type) {
+			at := document.UploadedAt
+			app.Conditions[i].SatisfiedBy = document.Name
+			app.Conditions[i].SatisfiedAt = &at
+			return app, i
+		}
+	}
+	return app, -1
+}
+
+type assigned struct {
+	officer string
+	at      time.Time
+}
+
+func (c assigned) apply(app model.LoanApplication) model.LoanApplication {
+	at := c.at
+	app.AssignedTo = c.officer
+	app.AssignedAt = &at
+	return app
+}
+
+type conditionAdded struct{ condition model.Condition }
+
+func (c conditionAdded) apply(app model.LoanApplication) model.LoanApplication {
+	app.Conditions = append(slices.Clip(app.Conditions), c.condition)
+	return app
+}
+
+type legalHoldSet struct{ hold *model.LegalHold }
+
+func (c legalHoldSet) apply(app model.LoanApplication) model.LoanApplication {
+	app.LegalHold = c.hold
+	return app
+}
+
+// linked records a later application found to duplicate this one.
+type linked struct{ id int }
+
+func (c linked) apply(app model.LoanApplication) model.LoanApplication {
+	app.LinkedApplications = append(slices.Clip(app.LinkedApplications), c.id)
+	return app
+}
+
+type anonymized struct{ at time.Time }
+
+func (c anonymized) apply(app model.LoanApplication) model.LoanApplication {
+	return model.Anonymize(app, c.at)
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"sync"
+
+	"loan-api/model"
+)
+
+// HistoryStore keeps every lifecycle event of each application, so the
+// application's history can be shown long after the event stream has moved on.
+//
+// It is deliberately separate from the application streams of MemoryStore.
+// Those record what changed the stored application, so that any past state
+// can be replayed, and have no public IDs. The history records the published
+// events with the IDs clients see on the event stream and in webhooks,
+// including events that change nothing stored, such as notes.
+type HistoryStore struct {
+	tenants *tenants[*HistoryStore]
+	entries map[int][]model.HistoryEntry
+	lock    sync.RWMutex
+}
+
+func NewHistoryStore() *HistoryStore {
+	return newTenants(func(_ string, registry *tenants[*HistoryStore]) *HistoryStore {
+		return &HistoryStore{tenants: registry, entries: make(map[int][]model.HistoryEntry)}
+	})
+}
+
+// ForTenant returns tenant's history.
+func (s *HistoryStore) ForTenant(tenant string) *HistoryStore {
+	return s.tenants.get(tenant)
+}
+
+// HandleEvent records the event in the history of its application. Erasure
+// events drop the data of the earlier entries, since data may name documents.
+// It is meant to be registered with Bus.Subscribe.
+func (s *HistoryStore) HandleEvent(event model.Event) {
+	tenantStore := s.ForTenant(event.Tenant)
+	tenantStore.lock.Lock()
+	defer tenantStore.lock.Unlock()
+
+	entries := tenantStore.entries[event.ApplicationID]
+	if model.IsErasureEvent(event.Type) {
+		for i := range entries {
+			entries[i].Data = nil
+		}
+	}
+	tenantStore.entries[event.ApplicationID] = append(entries, model.HistoryEntry{
+		EventID:    event.ID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Data:       event.Data,
+	})
+}
+
+// List returns the history of the application with the given ID, oldest
+// first.
+func (s *HistoryStore) List(id int) []model.HistoryEntry {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	result := make([]model.HistoryEntry, len(s.entries[id]))
+	copy(result, s.entries[id])
+	return result
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"loan-api/model"
+	"sync"
+	"time"
+)
+
+type ImportStore struct {
+	tenants *tenants[*ImportStore]
+	jobs    map[int]model.ImportJob
+	nextID  int
+	lock    sync.RWMutex
+}
+
+func NewImportStore() *ImportStore {
+	return newTenants(func(_ string, registry *tenants[*ImportStore]) *ImportStore {
+		return &ImportStore{
+			tenants: registry,
+			jobs:    make(map[int]model.ImportJob),
+			nextID:  1,
+		}
+	})
+}
+
+// ForTenant returns the store holding tenant's import jobs.
+func (s *ImportStore) ForTenant(tenant string) *ImportStore {
+	return s.tenants.get(tenant)
+}
+
+func (s *ImportStore) SaveImportJob(job model.ImportJob) model.ImportJob {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	job.ID = s.nextID
+	s.nextID++
+	job.CreatedAt = time.Now()
+	if job.Rows == nil {
+		job.Rows = []model.ImportRowResult{}
+	}
+	s.jobs[job.ID] = job
+	return job
+}
+
+func (s *ImportStore) GetImportJob(id int) (model.ImportJob, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	job, found := s.jobs[id]
+	if found {
+		// Rows is appended to while the job runs, so hand out a copy.
+		job.Rows = append([]model.ImportRowResult(nil), job.Rows...)
+	}
+	return job, found
+}
+
+func (s *ImportStore) UpdateImportJob(job model.ImportJob) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, found := s.jobs[job.ID]; !found {
+		return false
+	}
+	job.Rows = append([]model.ImportRowResult(nil), job.Rows...)
+	s.jobs[job.ID] = job
+	return true
+}
@@ -1,35 +1,96 @@
 package store
 
 import (
+	"context"
+	"crypto/rand"
+	"fmt"
 	"loan-api/model"
+	"sort"
 	"sync"
 	"time"
 )
 
+// MemoryStore keeps every change to an application as an event in the
+// application's stream. The applications map is the projection of those
+// streams, the current state, which reads are served from.
 type MemoryStore struct {
+	tenant       string
+	tenants      *tenants[*MemoryStore]
 	applications map[int]model.LoanApplication
-	nextID       int
-	lock         sync.RWMutex
+	streams      map[int]*stream
+	// bySSN indexes application IDs by the HMAC of their SSN under
+	// ssnSecret, oldest first.
+	bySSN     map[string][]int
+	ssnSecret []byte
+	nextID    int
+	lock      sync.RWMutex
+	// probe is closed once a pending Ping got the read lock; probeLock
+	// guards it, as the lock itself may be held.
+	probe     chan struct{}
+	probeLock sync.Mutex
 }
 
+// NewMemoryStore returns the default tenant's store. Other tenants are
+// reached through ForTenant. The SSN index is keyed with a random secret.
 func NewMemoryStore() *MemoryStore {
-	return &MemoryStore{
-		applications: make(map[int]model.LoanApplication),
-		nextID:       1,
-	}
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	return NewMemoryStoreWithSSNSecret(secret)
+}
+
+// NewMemoryStoreWithSSNSecret is NewMemoryStore with the secret that keys the
+// HMAC of every SSN or NIK in the index.
+func NewMemoryStoreWithSSNSecret(secret []byte) *MemoryStore {
+	return newTenants(func(tenant string, registry *tenants[*MemoryStore]) *MemoryStore {
+		return &MemoryStore{
+			tenant:       tenant,
+			tenants:      registry,
+			applications: make(map[int]model.LoanApplication),
+			streams:      make(map[int]*stream),
+			bySSN:        make(map[string][]int),
+			ssnSecret:    secret,
+			nextID:       1,
+		}
+	})
+}
+
+// ForTenant returns the store holding tenant's applications.
+func (s *MemoryStore) ForTenant(tenant string) *MemoryStore {
+	return s.tenants.get(tenant)
 }
 
+// Tenants returns the store of every tenant that has been used.
+func (s *MemoryStore) Tenants() []*MemoryStore {
+	return s.tenants.all()
+}
+
+// SaveLoanApplication saves a new application without checking it for
+// duplicates.
 func (s *MemoryStore) SaveLoanApplication(app model.LoanApplication) model.LoanApplication {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	saved, _ := s.SubmitLoanApplication(app, model.DuplicateRule{})
+	return saved
+}
 
+// save must be called with the lock held. It resets every field the server
+// owns, so a new application starts pending with no documents, conditions,
+// assignment or retention state, whatever the caller passed in.
+func (s *MemoryStore) save(app model.LoanApplication, now time.Time) model.LoanApplication {
 	app.ID = s.nextID
 	s.nextID++
-	app.Status = "pending"
-	app.SubmittedAt = time.Now()
+	app.Tenant = s.tenant
+	app.Status = model.StatusPending
+	app.SubmittedAt = now
+	app.StatusUpdatedAt = app.SubmittedAt
+	app.ProcessedAt = nil
+	app.SLA = nil
 	app.DocumentsUploaded = []string{}
-	s.applications[app.ID] = app
-	return app
+	app.Documents = nil
+	app.Conditions = nil
+	app.AssignedTo = ""
+	app.AssignedAt = nil
+	app.LegalHold = nil
+	app.AnonymizedAt = nil
+	return s.append(app.ID, app.SubmittedAt, submitted{app: app})
 }
 
 func (s *MemoryStore) GetLoanApplication(id int) (model.LoanApplication, bool) {
@@ -51,6 +112,29 @@ func (s *MemoryStore) ListLoanApplications() []model.LoanApplication {
 	return result
 }
 
+// EachLoanApplication calls fn for every application in ID order until fn
+// returns false. The lock is only held while each application is read, so fn
+// may block, for example on a slow client.
+func (s *MemoryStore) EachLoanApplication(fn func(model.LoanApplication) bool) {
+	s.lock.RLock()
+	ids := make([]int, 0, len(s.applications))
+	for id := range s.applications {
+		ids = append(ids, id)
+	}
+	s.lock.RUnlock()
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		app, found := s.GetLoanApplication(id)
+		if found && !fn(app) {
+			return
+		}
+	}
+}
+
+// UpdateLoanApplicationStatus sets the status without checking the transition,
+// for seeding and imports; status changes made by users go through
+// ChangeLoanApplicationStatus.
 func (s *MemoryStore) UpdateLoanApplicationStatus(id int, newStatus string) (model.LoanApplication, bool) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -59,35 +143,108 @@ func (s *MemoryStore) UpdateLoanApplicationStatus(id int, newStatus string) (mod
 	if !found {
 		return app, false
 	}
+	return s.setStatus(id, newStatus), true
+}
+
+// setStatus must be called with the lock held.
+func (s *MemoryStore) setStatus(id int, newStatus string) model.LoanApplication {
+	now := time.Now()
+	return s.append(id, now, statusChanged{status: newStatus, at: now})
+}
+
+// UpdateSLA sets the SLA of an application, unless its status changed since
+// status was read, in which case the stale SLA is discarded.
+func (s *MemoryStore) UpdateSLA(id int, status string, sla *model.SLA) (model.LoanApplication, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
 
-	app.Status = newStatus
-	if app.Status == "approved" || app.Status == "rejected" {
-		now := time.Now()
-		app.ProcessedAt = &now
-	} else {
-		app.ProcessedAt = nil
+	app, found := s.applications[id]
+	if !found || app.Status != status {
+		return app, false
 	}
-	s.applications[id] = app
-	return app, true
+	return s.append(id, time.Now(), slaUpdated{sla: sla}), true
 }
 
-func (s *MemoryStore) AddDocumentToApplication(id int, documentName string) (model.LoanApplication, bool) {
+// AddDocumentToApplication records an uploaded document. When the document
+// has a type, the oldest outstanding condition asking for that type is
+// satisfied by the document and returned.
+func (s *MemoryStore) AddDocumentToApplication(id int, document model.Document) (model.LoanApplication, *model.Condition, bool) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
 	app, found := s.applications[id]
 	if !found {
-		return app, false
+		return app, nil, false
+	}
+
+	document.UploadedAt = time.Now()
+	_, index := addDocument(app, document)
+	app = s.append(id, document.UploadedAt, documentAdded{document: document})
+	var satisfied *model.Condition
+	if index >= 0 {
+		satisfied = &app.Conditions[index]
+	}
+	return app, satisfied, true
+}
+
+// Tenant returns the tenant whose applications the store holds.
+func (s *MemoryStore) Tenant() string {
+	return s.tenant
+}
+
+// CountByStatus returns the number of applications in each status.
+func (s *MemoryStore) CountByStatus() map[string]int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	counts := make(map[string]int)
+	for _, app := range s.applications {
+		counts[app.Status]++
 	}
+	return counts
+}
 
-	app.DocumentsUploaded = append(app.DocumentsUploaded, documentName)
-	s.applications[id] = app
-	return app, true
+// Ping checks that every tenant's store can be read before ctx is done. It
+// fails when a writer has held a lock for too long.
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	for _, tenantStore := range s.Tenants() {
+		select {
+		case <-tenantStore.readable():
+		case <-ctx.Done():
+			return fmt.Errorf("store of tenant %s is locked: %w", tenantStore.tenant, ctx.Err())
+		}
+	}
+	return nil
+}
+
+// readable returns a channel that is closed once the store's read lock could
+// be taken. At most one goroutine waits for the lock: probes that time out
+// while it is blocked leave it running, and later probes wait on the same
+// channel instead of starting another.
+func (s *MemoryStore) readable() <-chan struct{} {
+	s.probeLock.Lock()
+	defer s.probeLock.Unlock()
+
+	if s.probe == nil {
+		probe := make(chan struct{})
+		s.probe = probe
+		go func() {
+			s.lock.RLock()
+			s.lock.RUnlock()
+			s.probeLock.Lock()
+			s.probe = nil
+			s.probeLock.Unlock()
+			close(probe)
+		}()
+	}
+	return s.probe
 }
 
 func (s *MemoryStore) ResetForTesting() {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.applications = make(map[int]model.LoanApplication)
+	s.streams = make(map[int]*stream)
+	s.bySSN = make(map[string][]int)
 	s.nextID = 1
 }
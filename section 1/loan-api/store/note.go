@@ -0,0 +1,123 @@
+package store
+
+import (
+	"loan-api/model"
+	"slices"
+	"sync"
+	"time"
+)
+
+type NoteStore struct {
+	tenants *tenants[*NoteStore]
+	notes   map[int]model.Note
+	nextID  int
+	lock    sync.RWMutex
+}
+
+func NewNoteStore() *NoteStore {
+	return newTenants(func(_ string, registry *tenants[*NoteStore]) *NoteStore {
+		return &NoteStore{
+			tenants: registry,
+			notes:   make(map[int]model.Note),
+			nextID:  1,
+		}
+	})
+}
+
+// ForTenant returns the store holding the notes on tenant's applications.
+func (s *NoteStore) ForTenant(tenant string) *NoteStore {
+	return s.tenants.get(tenant)
+}
+
+func (s *NoteStore) SaveNote(note model.Note) model.Note {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	note.ID = s.nextID
+	s.nextID++
+	note.CreatedAt = time.Now()
+	note.UpdatedAt = nil
+	note.History = nil
+	note.Replies = nil
+	s.notes[note.ID] = note
+	return copyNote(note)
+}
+
+func (s *NoteStore) GetNote(id int) (model.Note, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	note, found := s.notes[id]
+	return copyNote(note), found
+}
+
+// UpdateNote replaces the note's body, visibility and mentions, keeping the
+// previous version in its history.
+func (s *NoteStore) UpdateNote(id int, editor, body, visibility string, mentions []string) (model.Note, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	note, found := s.notes[id]
+	if !found {
+		return note, false
+	}
+	now := time.Now()
+	note.History = append(slices.Clip(note.History), model.NoteRevision{
+		Body:       note.Body,
+		Visibility: note.Visibility,
+		EditedBy:   editor,
+		EditedAt:   now,
+	})
+	note.Body = body
+	note.Visibility = visibility
+	note.Mentions = mentions
+	note.UpdatedAt = &now
+	s.notes[id] = note
+	return copyNote(note), true
+}
+
+// DeleteNotes removes every note on an application and returns how many were
+// removed.
+func (s *NoteStore) DeleteNotes(applicationID int) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	deleted := 0
+	for id, note := range s.notes {
+		if note.ApplicationID == applicationID {
+			delete(s.notes, id)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// ListNotes returns the notes on an application, oldest first.
+func (s *NoteStore) ListNotes(applicationID int) []model.Note {
+	return s.list(func(note model.Note) bool { return note.ApplicationID == applicationID })
+}
+
+// ListMentions returns the notes that mention subject, oldest first.
+func (s *NoteStore) ListMentions(subject string) []model.Note {
+	return s.list(func(note model.Note) bool { return slices.Contains(note.Mentions, subject) })
+}
+
+func (s *NoteStore) list(match func(model.Note) bool) []model.Note {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	result := []model.Note{}
+	for _, note := range s.notes {
+		if match(note) {
+			result = append(result, copyNote(note))
+		}
+	}
+	slices.SortFunc(result, func(a, b model.Note) int { return a.ID - b.ID })
+	return result
+}
+
+func copyNote(note model.Note) model.Note {
+	note.Mentions = slices.Clone(note.Mentions)
+	note.History = slices.Clone(note.History)
+	return note
+}
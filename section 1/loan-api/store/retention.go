@@ -0,0 +1,63 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"loan-api/model"
+)
+
+var ErrLegalHold = errors.New("loan application is under legal hold")
+
+// SetLegalHold places a legal hold on the application, or lifts it when hold
+// is nil.
+func (s *MemoryStore) SetLegalHold(id int, hold *model.LegalHold) (model.LoanApplication, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, found := s.applications[id]; !found {
+		return model.LoanApplication{}, false
+	}
+	return s.append(id, time.Now(), legalHoldSet{hold: hold}), true
+}
+
+// AnonymizeLoanApplication erases the personal data of the application,
+// including from its past states. It returns the application as it was
+// before, so its documents can be removed, together with the anonymized
+// application. It fails with ErrLegalHold while the application is on hold.
+func (s *MemoryStore) AnonymizeLoanApplication(id int, now time.Time) (model.LoanApplication, model.LoanApplication, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	app, found := s.applications[id]
+	switch {
+	case !found:
+		return app, app, ErrApplicationNotFound
+	case app.LegalHold != nil:
+		return app, app, ErrLegalHold
+	}
+
+	s.forgetSSN(app)
+	s.redactStream(id, now)
+	return app, s.append(id, now, anonymized{at: now}), nil
+}
+
+// DeleteLoanApplication removes the application and its events and returns
+// it. It fails with ErrLegalHold while the application is on hold.
+func (s *MemoryStore) DeleteLoanApplication(id int) (model.LoanApplication, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	app, found := s.applications[id]
+	switch {
+	case !found:
+		return app, ErrApplicationNotFound
+	case app.LegalHold != nil:
+		return app, ErrLegalHold
+	}
+
+	s.forgetSSN(app)
+	delete(s.applications, id)
+	delete(s.streams, id)
+	return app, nil
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"sort"
+	"sync"
+
+	"loan-api/model"
+)
+
+// tenants lazily creates one store per tenant, so that every tenant has its
+// own data and ID sequences. The store returned by newTenants serves
+// model.DefaultTenant.
+type tenants[S any] struct {
+	create func(tenant string) S
+	stores map[string]S
+	lock   sync.Mutex
+}
+
+func newTenants[S any](create func(tenant string, registry *tenants[S]) S) S {
+	registry := &tenants[S]{stores: make(map[string]S)}
+	registry.create = func(tenant string) S { return create(tenant, registry) }
+	return registry.get(model.DefaultTenant)
+}
+
+func (t *tenants[S]) get(tenant string) S {
+	tenant = model.TenantID(tenant)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	s, found := t.stores[tenant]
+	if !found {
+		s = t.create(tenant)
+		t.stores[tenant] = s
+	}
+	return s
+}
+
+// all returns every tenant's store ordered by tenant ID.
+func (t *tenants[S]) all() []S {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	ids := make([]string, 0, len(t.stores))
+	for id := range t.stores {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	result := make([]S, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, t.stores[id])
+	}
+	return result
+}
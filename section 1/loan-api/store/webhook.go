@@ -0,0 +1,208 @@
+package store
+
+import (
+	"loan-api/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultDeliveryLogSize is how many deliveries NewWebhookStore keeps per
+// tenant.
+const DefaultDeliveryLogSize = 1000
+
+type WebhookStore struct {
+	tenants        *tenants[*WebhookStore]
+	subscriptions  map[int]model.WebhookSubscription
+	deliveries     map[int]model.WebhookDelivery
+	maxDeliveries  int
+	nextSubID      int
+	nextDeliveryID int
+	lock           sync.RWMutex
+}
+
+func NewWebhookStore() *WebhookStore {
+	return NewWebhookStoreWithDeliveryLog(DefaultDeliveryLogSize)
+}
+
+// NewWebhookStoreWithDeliveryLog is NewWebhookStore keeping up to size
+// deliveries per tenant. The oldest finished deliveries are dropped first;
+// pending ones are kept until their attempts end.
+func NewWebhookStoreWithDeliveryLog(size int) *WebhookStore {
+	return newTenants(func(_ string, registry *tenants[*WebhookStore]) *WebhookStore {
+		return &WebhookStore{
+			tenants:        registry,
+			subscriptions:  make(map[int]model.WebhookSubscription),
+			deliveries:     make(map[int]model.WebhookDelivery),
+			maxDeliveries:  size,
+			nextSubID:      1,
+			nextDeliveryID: 1,
+		}
+	})
+}
+
+// ForTenant returns the store holding tenant's subscriptions and deliveries.
+func (s *WebhookStore) ForTenant(tenant string) *WebhookStore {
+	return s.tenants.get(tenant)
+}
+
+func (s *WebhookStore) SaveSubscription(sub model.WebhookSubscription) model.WebhookSubscription {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sub.ID = s.nextSubID
+	s.nextSubID++
+	sub.CreatedAt = time.Now()
+	sub.UpdatedAt = sub.CreatedAt
+	s.subscriptions[sub.ID] = sub
+	return sub
+}
+
+func (s *WebhookStore) GetSubscription(id int) (model.WebhookSubscription, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	sub, found := s.subscriptions[id]
+	return sub, found
+}
+
+func (s *WebhookStore) ListSubscriptions() []model.WebhookSubscription {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	result := []model.WebhookSubscription{}
+	for _, sub := range s.subscriptions {
+		result = append(result, sub)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+func (s *WebhookStore) UpdateSubscription(id int, update model.WebhookSubscription) (model.WebhookSubscription, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sub, found := s.subscriptions[id]
+	if !found {
+		return sub, false
+	}
+
+	sub.URL = update.URL
+	sub.Events = update.Events
+	sub.Active = update.Active
+	if update.Secret != "" {
+		sub.Secret = update.Secret
+	}
+	sub.UpdatedAt = time.Now()
+	s.subscriptions[id] = sub
+	return sub, true
+}
+
+func (s *WebhookStore) DeleteSubscription(id int) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, found := s.subscriptions[id]; !found {
+		return false
+	}
+	delete(s.subscriptions, id)
+	return true
+}
+
+func (s *WebhookStore) SaveDelivery(delivery model.WebhookDelivery) model.WebhookDelivery {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delivery.ID = s.nextDeliveryID
+	s.nextDeliveryID++
+	delivery.CreatedAt = time.Now()
+	s.deliveries[delivery.ID] = delivery
+	s.trimDeliveries()
+	return delivery
+}
+
+// trimDeliveries drops the oldest finished deliveries beyond maxDeliveries.
+// The caller holds the lock.
+func (s *WebhookStore) trimDeliveries() {
+	excess := len(s.deliveries) - s.maxDeliveries
+	if excess <= 0 {
+		return
+	}
+	finished := make([]int, 0, len(s.deliveries))
+	for id, delivery := range s.deliveries {
+		if delivery.Status != model.DeliveryPending {
+			finished = append(finished, id)
+		}
+	}
+	sort.Ints(finished)
+	for _, id := range finished[:min(excess, len(finished))] {
+		delete(s.deliveries, id)
+	}
+}
+
+// UpdateDelivery records the outcome of an attempt. The stored payload is
+// kept, since it may have been redacted while the attempt was in flight.
+func (s *WebhookStore) UpdateDelivery(delivery model.WebhookDelivery) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	stored, found := s.deliveries[delivery.ID]
+	if !found {
+		return
+	}
+	delivery.Payload = stored.Payload
+	s.deliveries[delivery.ID] = delivery
+	s.trimDeliveries()
+}
+
+// RestartDelivery marks a finished delivery pending again, clearing its last
+// error. It reports false when the delivery is missing or still pending, so
+// concurrent redeliveries start at most one round of attempts.
+func (s *WebhookStore) RestartDelivery(id int) (model.WebhookDelivery, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delivery, found := s.deliveries[id]
+	if !found || delivery.Status == model.DeliveryPending {
+		return delivery, false
+	}
+	delivery.Status = model.DeliveryPending
+	delivery.LastError = ""
+	s.deliveries[id] = delivery
+	return delivery, true
+}
+
+// RedactDeliveries rewrites the payload of every delivery of an application's
+// events with redact.
+func (s *WebhookStore) RedactDeliveries(applicationID int, redact func(payload string) string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for id, delivery := range s.deliveries {
+		if delivery.ApplicationID == applicationID {
+			delivery.Payload = redact(delivery.Payload)
+			s.deliveries[id] = delivery
+		}
+	}
+}
+
+func (s *WebhookStore) GetDelivery(id int) (model.WebhookDelivery, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	delivery, found := s.deliveries[id]
+	return delivery, found
+}
+
+func (s *WebhookStore) ListDeliveries(subscriptionID int) []model.WebhookDelivery {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	result := []model.WebhookDelivery{}
+	for _, delivery := range s.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			result = append(result, delivery)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return result
+}
@@ -0,0 +1,212 @@
+package synthetic
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+
+	"loan-api/model"
+)
+
+// incomeProfile is the log-normal annual income of a country's applicants in
+// the country's currency. Amounts are rounded to amountStep.
+type incomeProfile struct {
+	median, min, max float64
+	incomeStep       float64
+	amountStep       float64
+}
+
+var incomeProfiles = map[string]incomeProfile{
+	model.CountryUS: {median: 62000, min: 12000, max: 600000, incomeStep: 100, amountStep: 500},
+	model.CountryID: {median: 60000000, min: 12000000, max: 1200000000, incomeStep: 100000, amountStep: 500000},
+}
+
+// purposeProfile describes the applications for one loan purpose: how often
+// it is chosen, the median amount as a multiple of annual income and the
+// document usually sent with it.
+type purposeProfile struct {
+	key          string
+	weight       float64
+	incomeRatio  float64
+	documentType string
+}
+
+var purposeProfiles = []purposeProfile{
+	{model.PurposeHomeRenovation, 18, 0.5, "contractor_quote"},
+	{model.PurposeHomePurchase, 10, 3.5, "pay_stub"},
+	{model.PurposeBusinessExpansion, 9, 1.2, "bank_statement"},
+	{model.PurposeCarPurchase, 16, 0.5, "pay_stub"},
+	{model.PurposeEducation, 8, 0.6, "pay_stub"},
+	{model.PurposeDebtConsolidation, 20, 0.35, "bank_statement"},
+	{model.PurposeMedical, 6, 0.15, "pay_stub"},
+	{model.PurposePersonal, 13, 0.2, "pay_stub"},
+}
+
+var names = map[string]struct{ first, last []string }{
+	model.CountryUS: {
+		first: []string{"James", "Mary", "Robert", "Patricia", "Michael", "Jennifer", "David", "Linda", "Carlos", "Aisha", "Wei", "Priya"},
+		last:  []string{"Smith", "Johnson", "Williams", "Brown", "Garcia", "Miller", "Davis", "Martinez", "Nguyen", "Patel", "Kim", "Lopez"},
+	},
+	model.CountryID: {
+		first: []string{"Budi", "Siti", "Agus", "Dewi", "Eka", "Putri", "Nanda", "Rizky", "Wahyu", "Ayu", "Fajar", "Indah"},
+		last:  []string{"Santoso", "Wijaya", "Saputra", "Lestari", "Hidayat", "Kusuma", "Pratama", "Nugroho", "Setiawan", "Rahmawati", "Siregar", "Hasibuan"},
+	},
+}
+
+// NIK province codes, a subset of the ones the API accepts.
+var nikProvinces = []int{11, 12, 13, 14, 16, 31, 32, 33, 34, 35, 36, 51, 52, 61, 64, 71, 73, 81, 91}
+
+// Generator produces synthetic applications whose identity numbers pass the
+// API's checks and whose income, credit score and loan amount are correlated:
+// higher incomes come with better scores, and amounts follow income and
+// purpose. The same seed gives the same applications.
+type Generator struct {
+	// IDShare is the share of Indonesian applicants, who get a NIK instead of
+	// an SSN.
+	IDShare float64
+
+	rng *rand.Rand
+}
+
+func NewGenerator(seed uint64) *Generator {
+	return &Generator{IDShare: 0.3, rng: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))}
+}
+
+// Application returns a new applicant's application, ready to be submitted.
+func (g *Generator) Application() model.LoanApplication {
+	country := model.CountryUS
+	if g.Chance(g.IDShare) {
+		country = model.CountryID
+	}
+
+	// Income is log-normal around the country's median; the credit score
+	// shares part of its variation.
+	profile := incomeProfiles[country]
+	incomeZ := g.rng.NormFloat64()
+	income := clamp(math.Round(profile.median*math.Exp(0.55*incomeZ)/profile.incomeStep)*profile.incomeStep, profile.min, profile.max)
+	scoreZ := 0.45*incomeZ + math.Sqrt(1-0.45*0.45)*g.rng.NormFloat64()
+	score := int(clamp(math.Round(690+65*scoreZ), 300, 850))
+
+	purpose := g.purpose()
+	// Applicants with weaker credit ask for less.
+	ratio := purpose.incomeRatio * math.Exp(0.4*g.rng.NormFloat64()) * (0.7 + 0.6*float64(score-300)/550)
+	limits := model.LoanAmountLimits(country)
+	amount := clamp(math.Round(income*ratio/profile.amountStep)*profile.amountStep, limits.Min, limits.Max)
+
+	app := model.LoanApplication{
+		ApplicantName: g.name(country),
+		Country:       country,
+		LoanAmount:    amount,
+		LoanPurpose:   purposeName(purpose.key),
+		AnnualIncome:  income,
+		CreditScore:   score,
+	}
+	if country == model.CountryID {
+		app.ApplicantSSN = g.NIK()
+		// Since 2024 the NPWP of an individual is their NIK.
+		if g.rng.IntN(2) == 0 {
+			app.TaxID = app.ApplicantSSN
+		}
+	} else {
+		app.ApplicantSSN = g.SSN()
+	}
+	return app
+}
+
+// SSN returns a US Social Security Number that follows the SSA allocation
+// rules: the area is not 000, 666 or 900-999, the group not 00 and the serial
+// not 0000.
+func (g *Generator) SSN() string {
+	area := 1 + g.rng.IntN(899)
+	if area == 666 {
+		area = 665
+	}
+	return fmt.Sprintf("%03d-%02d-%04d", area, 1+g.rng.IntN(99), 1+g.rng.IntN(9999))
+}
+
+// NIK returns an Indonesian identity number of an adult: province, regency
+// and district codes, the birth date (with 40 added to the day for women) and
+// a serial.
+func (g *Generator) NIK() string {
+	day := 1 + g.rng.IntN(28)
+	if g.rng.IntN(2) == 0 {
+		day += 40
+	}
+	return fmt.Sprintf("%02d%02d%02d%02d%02d%02d%04d",
+		nikProvinces[g.rng.IntN(len(nikProvinces))],
+		1+g.rng.IntN(79),
+		1+g.rng.IntN(40),
+		day,
+		1+g.rng.IntN(12),
+		(60+g.rng.IntN(45))%100,
+		1+g.rng.IntN(9999))
+}
+
+// Chance returns true with probability p, drawn from the generator's seed.
+func (g *Generator) Chance(p float64) bool {
+	return g.rng.Float64() < p
+}
+
+// Document is a supporting document for an application.
+type Document struct {
+	Filename string
+	Type     string
+	Content  []byte
+}
+
+// Document returns the document usually sent for the application's purpose,
+// as a one page PDF naming the applicant.
+func (g *Generator) Document(app model.LoanApplication) Document {
+	documentType := "pay_stub"
+	for _, p := range purposeProfiles {
+		if p.key == model.LoanPurposeKey(app.LoanPurpose) {
+			documentType = p.documentType
+		}
+	}
+	lines := []string{
+		strings.ToUpper(strings.ReplaceAll(documentType, "_", " ")),
+		"Name: " + app.ApplicantName,
+		fmt.Sprintf("Annual income: %.2f %s", app.AnnualIncome, model.Currency(app.Country)),
+		fmt.Sprintf("Reference: %08d", g.rng.IntN(100000000)),
+	}
+	return Document{
+		Filename: fmt.Sprintf("%s_%d.pdf", documentType, g.rng.IntN(1000000)),
+		Type:     documentType,
+		Content:  PDF(lines...),
+	}
+}
+
+func (g *Generator) purpose() purposeProfile {
+	total := 0.0
+	for _, p := range purposeProfiles {
+		total += p.weight
+	}
+	pick := g.rng.Float64() * total
+	for _, p := range purposeProfiles {
+		if pick < p.weight {
+			return p
+		}
+		pick -= p.weight
+	}
+	return purposeProfiles[len(purposeProfiles)-1]
+}
+
+func (g *Generator) name(country string) string {
+	n := names[country]
+	return n.first[g.rng.IntN(len(n.first))] + " " + n.last[g.rng.IntN(len(n.last))]
+}
+
+// purposeName turns a purpose key such as "home_renovation" into the free
+// text applicants type, "Home Renovation".
+func purposeName(key string) string {
+	words := strings.Split(key, "_")
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/events"
+	"loan-api/handler"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/store"
+)
+
+func setupAssignmentRouter(officers []model.Officer) (*gin.Engine, *store.MemoryStore, *handler.AssignmentHandler) {
+	r := gin.New()
+	memStore := store.NewMemoryStore()
+	bus := events.NewBus()
+	loanHandler := handler.NewLoanHandler(memStore, bus)
+	assignmentHandler := handler.NewAssignmentHandler(loanHandler, officers)
+	bus.Subscribe(assignmentHandler.HandleEvent)
+	routes.SetupRoutes(r, routes.Handlers{Loan: loanHandler, Assignment: assignmentHandler})
+
+	middleware.RegisterToken("officer-budi", model.Principal{Subject: "budi", Role: model.RoleOfficer})
+	middleware.RegisterToken("officer-sari", model.Principal{Subject: "sari", Role: model.RoleOfficer})
+	middleware.RegisterToken("applicant-dewi", model.Principal{Subject: "dewi", Role: model.RoleApplicant})
+	return r, memStore, assignmentHandler
+}
+
+func doAs(router *gin.Engine, token, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	if body != "" {
+		req, _ = http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestClaimNextLoanApplication(t *testing.T) {
+	router, memStore, _ := setupAssignmentRouter([]model.Officer{{Subject: "budi", Capacity: 2}})
+	for i := 0; i < 3; i++ {
+		memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: fmt.Sprintf("Applicant %d", i)})
+	}
+
+	// Test Case 1: The oldest unassigned pending application is handed out
+	w := doAs(router, "officer-budi", http.MethodPost, "/loan-applications:claim", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var claimed model.LoanApplication
+	json.Unmarshal(w.Body.Bytes(), &claimed)
+	assert.Equal(t, 1, claimed.ID)
+	assert.Equal(t, "budi", claimed.AssignedTo)
+	assert.NotNil(t, claimed.AssignedAt)
+
+	// Test Case 2: Capacity is enforced
+	w = doAs(router, "officer-budi", http.MethodPost, "/loan-applications:claim", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doAs(router, "officer-budi", http.MethodPost, "/loan-applications:claim", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Test Case 3: Decided applications leave the queue and free capacity
+	memStore.UpdateLoanApplicationStatus(1, model.StatusApproved)
+	w = doAs(router, "officer-budi", http.MethodGet, "/me/queue", "")
+	var queue []model.LoanApplication
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	assert.Len(t, queue, 1)
+	assert.Equal(t, 2, queue[0].ID)
+
+	// Test Case 4: Officers without a configured capacity are unlimited, until the queue is empty
+	w = doAs(router, "officer-sari", http.MethodPost, "/loan-applications:claim", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doAs(router, "officer-sari", http.MethodPost, "/loan-applications:claim", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Test Case 5: Applicants cannot claim
+	w = doAs(router, "applicant-dewi", http.MethodPost, "/loan-applications:claim", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestClaimNextIsAtomic(t *testing.T) {
+	router, memStore, _ := setupAssignmentRouter(nil)
+	for i := 0; i < 5; i++ {
+		memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: fmt.Sprintf("Applicant %d", i)})
+	}
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	claimed := map[int]int{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := doAs(router, "officer-budi", http.MethodPost, "/loan-applications:claim", "")
+			if w.Code != http.StatusOK {
+				return
+			}
+			var app model.LoanApplication
+			json.Unmarshal(w.Body.Bytes(), &app)
+			lock.Lock()
+			claimed[app.ID]++
+			lock.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, claimed, 5)
+	for id, count := range claimed {
+		assert.Equal(t, 1, count, "application %d claimed more than once", id)
+	}
+}
+
+func TestAssignLoanApplication(t *testing.T) {
+	officers := []model.Officer{{Subject: "budi", Capacity: 4}, {Subject: "sari", Capacity: 2}}
+	router, memStore, assignmentHandler := setupAssignmentRouter(officers)
+	for i := 0; i < 4; i++ {
+		memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: fmt.Sprintf("Applicant %d", i)})
+	}
+
+	// Test Case 1: Manual assignment to a configured officer
+	w := doAs(router, "officer-budi", http.MethodPost, "/loan-applications/1/assign", `{"officer":"sari"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doAs(router, "officer-budi", http.MethodPost, "/loan-applications/1/assign", `{"officer":"nobody"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAs(router, "officer-budi", http.MethodPost, "/loan-applications/99/assign", `{"officer":"sari"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Test Case 2: Auto-assignment balances load relative to capacity
+	w = doAs(router, "officer-budi", http.MethodPost, "/loan-applications/2/assign", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	app, _ := memStore.GetLoanApplication(2)
+	assert.Equal(t, "budi", app.AssignedTo) // budi 0/4 vs sari 1/2
+
+	doAs(router, "officer-budi", http.MethodPost, "/loan-applications/3/assign", "")
+	app, _ = memStore.GetLoanApplication(3)
+	assert.Equal(t, "budi", app.AssignedTo) // budi 1/4 vs sari 1/2
+
+	doAs(router, "officer-budi", http.MethodPost, "/loan-applications/4/assign", "")
+	app, _ = memStore.GetLoanApplication(4)
+	assert.Equal(t, "sari", app.AssignedTo) // budi 2/4 ties sari 1/2, fewer open wins
+
+	// Test Case 3: Workloads and auto-assignment on submit
+	w = doAs(router, "officer-budi", http.MethodGet, "/officers/workload", "")
+	var workloads []model.OfficerWorkload
+	json.Unmarshal(w.Body.Bytes(), &workloads)
+	assert.Equal(t, 2, workloads[0].Open)
+	assert.Equal(t, 2, workloads[1].Open)
+
+	assignmentHandler.AutoAssign = true
+	w = doJSON(router, http.MethodPost, "/loan-applications", map[string]interface{}{
+		"applicant_name": "Eka", "applicant_ssn": "123-45-6789", "loan_amount": 5000,
+		"loan_purpose": "personal", "annual_income": 1000, "credit_score": 700,
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	app, _ = memStore.GetLoanApplication(5)
+	assert.Equal(t, "budi", app.AssignedTo)
+
+	// Test Case 4: Nobody left with capacity
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Fajar"})
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Gita"})
+	doAs(router, "officer-budi", http.MethodPost, "/loan-applications/6/assign", "")
+	w = doAs(router, "officer-budi", http.MethodPost, "/loan-applications/7/assign", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Test Case 5: Manual assignment respects capacity, except for the holder
+	w = doAs(router, "officer-budi", http.MethodPost, "/loan-applications/7/assign", `{"officer":"sari"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "capacity_reached", decodeProblem(t, w).Code)
+	w = doAs(router, "officer-budi", http.MethodPost, "/loan-applications/1/assign", `{"officer":"sari"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Test Case 6: Decided applications cannot be assigned
+	memStore.UpdateLoanApplicationStatus(7, model.StatusRejected)
+	memStore.UpdateLoanApplicationStatus(1, model.StatusRejected)
+	w = doAs(router, "officer-budi", http.MethodPost, "/loan-applications/7/assign", `{"officer":"sari"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "application_decided", decodeProblem(t, w).Code)
+	w = doAs(router, "officer-budi", http.MethodPost, "/loan-applications/7/assign", "")
+	assert.Equal(t, "application_decided", decodeProblem(t, w).Code)
+}
+
+func TestAutoAssignUnlimitedOfficers(t *testing.T) {
+	officers := []model.Officer{{Subject: "budi", Capacity: 4}, {Subject: "sari"}}
+	_, memStore, _ := setupAssignmentRouter(officers)
+	for i := range 8 {
+		memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: fmt.Sprintf("Applicant %d", i)})
+	}
+
+	// Test Case 1: Unlimited officers count as the largest capacity and share the load
+	assigned := map[string]int{}
+	for id := 1; id <= 6; id++ {
+		app, err := memStore.AutoAssignLoanApplication(id, officers)
+		assert.NoError(t, err)
+		assigned[app.AssignedTo]++
+	}
+	assert.Equal(t, map[string]int{"budi": 3, "sari": 3}, assigned)
+
+	// Test Case 2: Once the others are full, unlimited officers take the rest
+	for id := 7; id <= 8; id++ {
+		app, _ := memStore.AutoAssignLoanApplication(id, officers)
+		assigned[app.AssignedTo]++
+	}
+	assert.Equal(t, map[string]int{"budi": 4, "sari": 4}, assigned)
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/config"
+	"loan-api/handler"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/store"
+)
+
+func setupChecklistRouter(t *testing.T) *gin.Engine {
+	r := gin.New()
+	memStore := store.NewMemoryStore()
+	loanHandler := handler.NewLoanHandler(memStore, nil)
+	loanHandler.UploadDir = t.TempDir()
+	loanHandler.Checklists = config.Default().Checklists
+	routes.SetupRoutes(r, routes.Handlers{Loan: loanHandler})
+
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Nanda", LoanPurpose: "Home Renovation"})
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Budi", LoanPurpose: "Medical", SubmittedBy: "budi"})
+	return r
+}
+
+func TestDocumentChecklist(t *testing.T) {
+	router := setupChecklistRouter(t)
+
+	// Test Case 1: Nothing uploaded yet
+	w := doJSON(router, http.MethodGet, "/loan-applications/1/checklist", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var checklist model.Checklist
+	json.Unmarshal(w.Body.Bytes(), &checklist)
+	assert.Equal(t, "home_renovation", checklist.LoanPurpose)
+	assert.False(t, checklist.Complete)
+	assert.Equal(t, []string{"contractor_quote", "pay_stub"}, checklist.Missing())
+
+	// Test Case 2: Approval is refused while documents are missing
+	uploadDocument(router, "1", "quote.pdf", "Contractor_Quote")
+	w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "approved"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "checklist_incomplete", problem.Code)
+	assert.Contains(t, problem.Detail, "pay_stub")
+	assert.NotContains(t, problem.Detail, "contractor_quote")
+
+	w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "under_review"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Test Case 3: A complete checklist allows approval
+	uploadDocument(router, "1", "payslip.pdf", "pay_stub")
+	w = doJSON(router, http.MethodGet, "/loan-applications/1/checklist", nil)
+	json.Unmarshal(w.Body.Bytes(), &checklist)
+	assert.True(t, checklist.Complete)
+	assert.Equal(t, []string{"doc_1_payslip.pdf"}, checklist.Items[1].Documents)
+	w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "approved"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Test Case 4: Purposes without a checklist are always complete
+	w = doJSON(router, http.MethodGet, "/loan-applications/2/checklist", nil)
+	json.Unmarshal(w.Body.Bytes(), &checklist)
+	assert.True(t, checklist.Complete)
+	assert.Empty(t, checklist.Items)
+
+	// Test Case 5: Applicants only see their own checklists
+	middleware.RegisterToken("checklist-applicant", model.Principal{Subject: "budi", Role: model.RoleApplicant})
+	w = doAs(router, "checklist-applicant", http.MethodGet, "/loan-applications/2/checklist", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doAs(router, "checklist-applicant", http.MethodGet, "/loan-applications/1/checklist", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Test Case 6: The store checks the checklist together with the status change
+	memStore := store.NewMemoryStore()
+	app := memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Citra", LoanPurpose: "Home Renovation"})
+	checklists := config.Default().Checklists
+	_, err := memStore.ChangeLoanApplicationStatus(app.ID, model.StatusApproved, checklists)
+	assert.ErrorIs(t, err, store.ErrChecklistIncomplete)
+	memStore.AddDocumentToApplication(app.ID, model.Document{Name: "quote.pdf", Type: "contractor_quote"})
+	memStore.AddDocumentToApplication(app.ID, model.Document{Name: "payslip.pdf", Type: "pay_stub"})
+	approved, err := memStore.ChangeLoanApplicationStatus(app.ID, model.StatusApproved, checklists)
+	assert.NoError(t, err)
+	assert.Equal(t, model.StatusApproved, approved.Status)
+
+	// Test Case 7: Conditions can stand in for missing documents
+	router = setupChecklistRouter(t)
+	uploadDocument(router, "1", "quote.pdf", "contractor_quote")
+	doJSON(router, http.MethodPost, "/loan-applications/1/conditions", map[string]string{"description": "Proof of address", "document_type": "utility_bill"})
+	w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "approved_with_conditions"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "Missing required documents: pay_stub", decodeProblem(t, w).Detail)
+
+	doJSON(router, http.MethodPost, "/loan-applications/1/conditions", map[string]string{"description": "Last pay stub", "document_type": "pay_stub"})
+	w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "approved_with_conditions"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "approved"})
+	assert.Equal(t, "checklist_incomplete", decodeProblem(t, w).Code)
+
+	uploadDocument(router, "1", "bill.pdf", "utility_bill")
+	uploadDocument(router, "1", "payslip.pdf", "pay_stub")
+	w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "funded"})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSubmitIgnoresServerFields(t *testing.T) {
+	router := setupChecklistRouter(t)
+	middleware.RegisterToken("checklist-submitter", model.Principal{Subject: "citra", Role: model.RoleApplicant})
+	body := `{
+		"applicant_name": "Citra", "applicant_ssn": "123-45-6789", "loan_amount": 20000,
+		"loan_purpose": "Home Renovation", "annual_income": 75000, "credit_score": 720,
+		"id": 99, "status": "approved", "processed_at": "2026-03-01T00:00:00Z",
+		"documents": [{"name": "quote.pdf", "type": "contractor_quote"}, {"name": "payslip.pdf", "type": "pay_stub"}],
+		"documents_uploaded": ["quote.pdf", "payslip.pdf"],
+		"conditions": [{"id": 1, "description": "None"}],
+		"assigned_to": "budi", "assigned_at": "2026-03-01T00:00:00Z"
+	}`
+
+	// Test Case 1: Server-owned fields in the request are dropped
+	w := doAs(router, "checklist-submitter", http.MethodPost, "/loan-applications", body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var app model.LoanApplication
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &app))
+	assert.Equal(t, 3, app.ID)
+	assert.Equal(t, model.StatusPending, app.Status)
+	assert.Nil(t, app.ProcessedAt)
+	assert.Empty(t, app.Documents)
+	assert.Empty(t, app.DocumentsUploaded)
+	assert.Empty(t, app.Conditions)
+	assert.Empty(t, app.AssignedTo)
+	assert.Nil(t, app.AssignedAt)
+
+	// Test Case 2: Claimed documents do not satisfy the checklist
+	w = doJSON(router, http.MethodPut, "/loan-applications/3/status", map[string]string{"status": "approved"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "checklist_incomplete", decodeProblem(t, w).Code)
+}
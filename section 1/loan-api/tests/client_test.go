@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/client"
+	"loan-api/events"
+	"loan-api/handler"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/store"
+	"loan-api/webhook"
+)
+
+func setupClient(t *testing.T) (*client.Client, *store.MemoryStore) {
+	memStore := store.NewMemoryStore()
+	webhookStore := store.NewWebhookStore()
+	historyStore := store.NewHistoryStore()
+	bus := events.NewBus()
+	bus.Subscribe(historyStore.HandleEvent)
+
+	loanHandler := handler.NewLoanHandler(memStore, bus)
+	loanHandler.UploadDir = t.TempDir()
+	loanHandler.History = historyStore
+	r := gin.New()
+	routes.SetupRoutes(r, routes.Handlers{
+		Loan:    loanHandler,
+		Webhook: handler.NewWebhookHandler(webhookStore, webhook.NewDispatcher(webhookStore)),
+		Export:  handler.NewExportHandler(memStore, store.NewAuditStore()),
+	})
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return client.New(server.URL+"/", "mysecrettoken"), memStore
+}
+
+func TestClientApplications(t *testing.T) {
+	c, memStore := setupClient(t)
+	ctx := context.Background()
+
+	// Test Case 1: Submitted applications come back in the v2 shape
+	created, err := c.SubmitApplication(ctx, model.LoanApplication{
+		ApplicantName: "Nanda", ApplicantSSN: "123-45-6789", LoanAmount: 20000,
+		LoanPurpose: "Home Renovation", AnnualIncome: 75000, CreditScore: 720,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created.ID)
+	assert.Equal(t, "20000.00", created.LoanAmount.Amount)
+	assert.Equal(t, "USD", created.LoanAmount.Currency)
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Budi", ApplicantSSN: "987-65-4321"})
+
+	// Test Case 2: Lists carry the paging details
+	page, err := c.ListApplications(ctx, client.ListOptions{Limit: 1, Page: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, "Budi", page.Data[0].ApplicantName)
+	assert.Equal(t, 2, page.Pagination.Total)
+
+	// Test Case 3: Status changes, documents and their reasons show up in the history
+	_, err = c.UpdateStatus(ctx, 1, model.StatusUnderReview, "income needs checking")
+	assert.NoError(t, err)
+	app, err := c.UploadDocument(ctx, 1, "payslip.pdf", strings.NewReader("%PDF-1.4"), "pay_stub")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"doc_1_payslip.pdf"}, app.DocumentsUploaded)
+	history, err := c.History(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, history, 3)
+	assert.Equal(t, model.EventApplicationSubmitted, history[0].Type)
+	assert.Equal(t, model.EventApplicationStatusChanged, history[1].Type)
+	assert.Equal(t, "income needs checking", history[1].Data["reason"])
+	assert.Equal(t, "admin", history[1].Data["changed_by"])
+	assert.Equal(t, "pay_stub", history[2].Data["document_type"])
+
+	// Test Case 4: Problems are returned as errors
+	_, err = c.GetApplication(ctx, 99)
+	var apiErr *client.Error
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "application_not_found", apiErr.Problem.Code)
+	_, err = c.UpdateStatus(ctx, 1, model.StatusApproved, strings.Repeat("x", 501))
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "reason", apiErr.Problem.Errors[0].Field)
+
+	// Test Case 5: Exports are streamed as they are
+	var out bytes.Buffer
+	assert.NoError(t, c.Export(ctx, url.Values{"format": {"ndjson"}}, &out))
+	assert.Equal(t, 2, strings.Count(out.String(), "\n"))
+	err = c.Export(ctx, url.Values{"format": {"xml"}}, &out)
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadRequest, apiErr.Problem.Status)
+}
+
+func TestClientWebhooks(t *testing.T) {
+	c, _ := setupClient(t)
+	ctx := context.Background()
+
+	// Test Case 1: The secret is returned on creation only
+	created, err := c.CreateWebhook(ctx, client.WebhookRequest{URL: "https://203.0.113.10/hook", Events: []string{model.EventApplicationSubmitted}})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, created.Secret)
+	assert.True(t, created.Active)
+	subs, err := c.ListWebhooks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, subs, 1)
+	assert.Empty(t, subs[0].Secret)
+
+	// Test Case 2: Updates replace the subscription
+	inactive := false
+	updated, err := c.UpdateWebhook(ctx, created.ID, client.WebhookRequest{URL: "https://203.0.113.10/v2/hook", Active: &inactive})
+	assert.NoError(t, err)
+	assert.False(t, updated.Active)
+	deliveries, err := c.ListDeliveries(ctx, created.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	// Test Case 3: Deleted subscriptions are gone
+	assert.NoError(t, c.DeleteWebhook(ctx, created.ID))
+	_, err = c.GetWebhook(ctx, created.ID)
+	var apiErr *client.Error
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.Problem.Status)
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/events"
+	"loan-api/handler"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/store"
+)
+
+func setupConditionRouter(t *testing.T) (*gin.Engine, *store.MemoryStore, *[]model.Event) {
+	r := gin.New()
+	memStore := store.NewMemoryStore()
+	bus := events.NewBus()
+	var published []model.Event
+	bus.Subscribe(func(event model.Event) { published = append(published, event) })
+	loanHandler := handler.NewLoanHandler(memStore, bus)
+	loanHandler.UploadDir = t.TempDir()
+	routes.SetupRoutes(r, routes.Handlers{Loan: loanHandler})
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Nanda", LoanAmount: 50000})
+	return r, memStore, &published
+}
+
+func uploadDocument(router *gin.Engine, id, filename, documentType string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("document", filename)
+	part.Write([]byte("%PDF-1.4"))
+	if documentType != "" {
+		form.WriteField("document_type", documentType)
+	}
+	form.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, "/loan-applications/"+id+"/documents", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer mysecrettoken")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestApprovalConditions(t *testing.T) {
+	router, memStore, published := setupConditionRouter(t)
+
+	// Test Case 1: Conditions are validated and listed on the application
+	w := doJSON(router, http.MethodPost, "/loan-applications/1/conditions", map[string]string{
+		"description": "Last 2 pay stubs", "document_type": "pay_stub", "due_date": "2024-03-31",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var condition model.Condition
+	json.Unmarshal(w.Body.Bytes(), &condition)
+	assert.Equal(t, 1, condition.ID)
+	assert.Equal(t, "admin", condition.CreatedBy)
+	assert.False(t, condition.IsSatisfied())
+
+	doJSON(router, http.MethodPost, "/loan-applications/1/conditions", map[string]string{
+		"description": "Proof of address", "document_type": "utility_bill",
+	})
+	w = doJSON(router, http.MethodPost, "/loan-applications/1/conditions", map[string]string{
+		"description": "Bank statement", "document_type": "bank_statement", "due_date": "31/03/2024",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_format", decodeProblem(t, w).Errors[0].Code)
+	w = doJSON(router, http.MethodPost, "/loan-applications/99/conditions", map[string]string{
+		"description": "Pay stub", "document_type": "pay_stub",
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Test Case 2: Funding needs an approval
+	w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "funded"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "invalid_status_transition", decodeProblem(t, w).Code)
+
+	// Test Case 3: Funding is blocked while conditions are outstanding
+	w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "approved_with_conditions"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	approved, _ := memStore.GetLoanApplication(1)
+	assert.NotNil(t, approved.ProcessedAt)
+
+	w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "funded"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "conditions_outstanding", decodeProblem(t, w).Code)
+
+	// Test Case 4: Matching uploads satisfy conditions, others do not
+	w = uploadDocument(router, "1", "payslip.pdf", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = uploadDocument(router, "1", "payslip-mar.pdf", "Pay_Stub")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var app model.LoanApplication
+	json.Unmarshal(w.Body.Bytes(), &app)
+	assert.Equal(t, "doc_1_payslip-mar.pdf", app.Conditions[0].SatisfiedBy)
+	assert.NotNil(t, app.Conditions[0].SatisfiedAt)
+	assert.Len(t, model.OutstandingConditions(app), 1)
+
+	last := (*published)[len(*published)-1]
+	assert.Equal(t, model.EventConditionSatisfied, last.Type)
+	assert.Equal(t, "1", last.Data["condition_id"])
+
+	// Test Case 5: Funding succeeds once every condition clears
+	uploadDocument(router, "1", "bill.pdf", "utility_bill")
+	w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "funded"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	funded, _ := memStore.GetLoanApplication(1)
+	assert.Equal(t, model.StatusFunded, funded.Status)
+	assert.Equal(t, approved.ProcessedAt, funded.ProcessedAt)
+
+	// Test Case 6: Funded applications are final and take no new conditions
+	w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "pending"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "invalid_status_transition", decodeProblem(t, w).Code)
+	w = doJSON(router, http.MethodPost, "/loan-applications/1/conditions", map[string]string{
+		"description": "Pay stub", "document_type": "pay_stub",
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "application_decided", decodeProblem(t, w).Code)
+}
+
+func TestStatusTransitions(t *testing.T) {
+	router, memStore, _ := setupConditionRouter(t)
+
+	// Test Case 1: A conditional approval needs a condition
+	w := doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "approved_with_conditions"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "invalid_status_transition", decodeProblem(t, w).Code)
+
+	// Test Case 2: Rejected applications cannot be reopened or approved
+	w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": "rejected"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	for _, status := range []string{"pending", "under_review", "approved", "approved_with_conditions", "funded", "rejected"} {
+		w = doJSON(router, http.MethodPut, "/loan-applications/1/status", map[string]string{"status": status})
+		assert.Equal(t, http.StatusConflict, w.Code, status)
+	}
+	rejected, _ := memStore.GetLoanApplication(1)
+	assert.Equal(t, model.StatusRejected, rejected.Status)
+	assert.Empty(t, model.OutstandingConditions(rejected))
+
+	// Test Case 3: The table allows only listed moves
+	assert.True(t, model.CanTransition(model.StatusPending, model.StatusUnderReview))
+	assert.True(t, model.CanTransition(model.StatusApproved, model.StatusFunded))
+	assert.False(t, model.CanTransition(model.StatusPending, model.StatusFunded))
+	assert.False(t, model.CanTransition(model.StatusFunded, model.StatusPending))
+	assert.True(t, model.IsFinal(model.StatusRejected))
+	assert.False(t, model.IsFinal(model.StatusApproved))
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"loan-api/config"
+	"loan-api/model"
+)
+
+func TestLoadConfig(t *testing.T) {
+	// Test Case 1: Defaults
+	cfg, err := config.Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Server.Port)
+	assert.Equal(t, "./uploads", cfg.Uploads.Dir)
+	assert.Equal(t, 10<<20, cfg.Uploads.MaxBytes)
+
+	// Test Case 2: File, then environment, then flags
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+		"server": {"port": 9000, "shutdown_timeout": "30s"},
+		"uploads": {"dir": "/var/lib/loan-api/uploads"},
+		"auth": {"tokens": [{"token": "officertoken", "subject": "budi", "role": "officer"}]}
+	}`), 0o600))
+	t.Setenv("LOAN_API_PORT", "9100")
+	t.Setenv("LOAN_API_EVENT_LOG_SIZE", "50")
+	t.Setenv("LOAN_API_UPLOAD_MAX_BYTES", "2048")
+
+	cfg, err = config.Load([]string{"-config", path, "-upload-dir", "/tmp/uploads"})
+	assert.NoError(t, err)
+	assert.Equal(t, 9100, cfg.Server.Port)
+	assert.Equal(t, 30*time.Second, cfg.Server.ShutdownTimeout.Duration)
+	assert.Equal(t, "/tmp/uploads", cfg.Uploads.Dir)
+	assert.Equal(t, 50, cfg.Events.LogSize)
+	assert.Equal(t, 2048, cfg.Uploads.MaxBytes)
+	assert.Len(t, cfg.Auth.Tokens, 1)
+
+	cfg, err = config.Load([]string{"-config", path, "-port", "7000"})
+	assert.NoError(t, err)
+	assert.Equal(t, 7000, cfg.Server.Port)
+
+	// Test Case 3: Validation errors
+	t.Setenv("LOAN_API_PORT", "70000")
+	_, err = config.Load(nil)
+	assert.ErrorContains(t, err, "server.port")
+
+	t.Setenv("LOAN_API_PORT", "abc")
+	_, err = config.Load(nil)
+	assert.ErrorContains(t, err, "LOAN_API_PORT")
+
+	// Test Case 4: Unknown keys in the file are rejected
+	t.Setenv("LOAN_API_PORT", "8080")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"server": {"prot": 1}}`), 0o600))
+	_, err = config.Load([]string{"-config", path})
+	assert.ErrorContains(t, err, "unknown field")
+
+	// Test Case 5: SLA deadlines and holidays are validated
+	assert.NoError(t, os.WriteFile(path, []byte(`{"sla": {"deadlines": {"approved": 1}, "holidays": ["25/12/2024"]}}`), 0o600))
+	_, err = config.Load([]string{"-config", path})
+	assert.ErrorContains(t, err, "sla.deadlines.approved")
+	assert.ErrorContains(t, err, "sla: parsing time")
+
+	// Test Case 6: Checklists are keyed by loan purpose
+	assert.NoError(t, os.WriteFile(path, []byte(`{"checklists": {"Home Renovation": ["pay_stub"], "education": [""]}}`), 0o600))
+	_, err = config.Load([]string{"-config", path})
+	assert.ErrorContains(t, err, "checklists.Home Renovation")
+	assert.ErrorContains(t, err, "checklists.education has an empty document type")
+
+	// Test Case 7: Tenant settings are validated
+	assert.NoError(t, os.WriteFile(path, []byte(`{"tenants": {"acme": {"min_loan_amount": 5000, "max_loan_amount": 1000, "statuses": ["approved", "closed"]}}}`), 0o600))
+	_, err = config.Load([]string{"-config", path})
+	assert.ErrorContains(t, err, "tenants.acme loan amount limits")
+	assert.ErrorContains(t, err, "tenants.acme.statuses must include pending")
+	assert.ErrorContains(t, err, `tenants.acme.statuses has unknown status "closed"`)
+
+	// Test Case 8: Retention rules are validated
+	assert.NoError(t, os.WriteFile(path, []byte(`{"retention": {"rules": [{"status": "rejected", "after_days": 365, "action": "delete"}, {"status": "rejected", "after_days": 30, "action": "archive"}]}}`), 0o600))
+	_, err = config.Load([]string{"-config", path})
+	assert.ErrorContains(t, err, "retention.rules[1]")
+	assert.NotContains(t, err.Error(), "retention.rules[0]")
+
+	// Test Case 9: Metrics need their own port, 0 disables them
+	_, err = config.Load([]string{"-port", "9090"})
+	assert.ErrorContains(t, err, "metrics.port")
+	cfg, err = config.Load([]string{"-port", "9090", "-metrics-port", "0"})
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.Metrics.Port)
+
+	// Test Case 10: Trace exporters are checked by name
+	_, err = config.Load([]string{"-trace-exporter", "jaeger"})
+	assert.ErrorContains(t, err, "tracing.exporter")
+	t.Setenv("LOAN_API_TRACE_EXPORTER", "otlp")
+	t.Setenv("LOAN_API_TRACE_ENDPOINT", "http://collector:4318/v1/traces")
+	cfg, err = config.Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "otlp", cfg.Tracing.Exporter)
+	assert.Equal(t, "http://collector:4318/v1/traces", cfg.Tracing.Endpoint)
+
+	// Test Case 11: The gRPC port cannot share a listener, and 0 disables it
+	_, err = config.Load([]string{"-grpc-port", "9090"})
+	assert.ErrorContains(t, err, "grpc.port")
+	t.Setenv("LOAN_API_GRPC_PORT", "0")
+	cfg, err = config.Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cfg.GRPC.Port)
+
+	// Test Case 12: Duplicate checks default to off and are validated
+	assert.Equal(t, model.DuplicatePolicyOff, cfg.Duplicates.Policy)
+	assert.Empty(t, cfg.Duplicates.SSNSecret)
+	assert.Equal(t, 30*24*time.Hour, cfg.Duplicates.Window.Duration)
+	assert.NoError(t, os.WriteFile(path, []byte(`{"duplicates": {"policy": "merge", "window": "-1h", "amount_tolerance": 1.5}}`), 0o600))
+	_, err = config.Load([]string{"-config", path})
+	assert.ErrorContains(t, err, `duplicates.policy must be one of off, reject, link, flag, got "merge"`)
+	assert.ErrorContains(t, err, "duplicates.window")
+	assert.ErrorContains(t, err, "duplicates.amount_tolerance")
+	t.Setenv("LOAN_API_DUPLICATE_POLICY", "reject")
+	t.Setenv("LOAN_API_SSN_SECRET", "too short")
+	_, err = config.Load(nil)
+	assert.ErrorContains(t, err, "duplicates.ssn_secret must be at least 32 bytes")
+	t.Setenv("LOAN_API_SSN_SECRET", "0123456789abcdef0123456789abcdef")
+	cfg, err = config.Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, model.DuplicatePolicyReject, cfg.Duplicates.Rule().Policy)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef", cfg.Duplicates.SSNSecret)
+}
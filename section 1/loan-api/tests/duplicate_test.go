@@ -0,0 +1,207 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/events"
+	"loan-api/handler"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/store"
+)
+
+func setupDuplicateRouter(policy string) (*gin.Engine, *store.MemoryStore) {
+	r := gin.New()
+	memStore := store.NewMemoryStore()
+	loanHandler := handler.NewLoanHandler(memStore, events.NewBus())
+	loanHandler.Duplicates = model.DuplicateRule{Policy: policy, Window: 30 * 24 * time.Hour, AmountTolerance: 0.05}
+	routes.SetupRoutes(r, routes.Handlers{Loan: loanHandler})
+	return r, memStore
+}
+
+func duplicateApplication(name, ssn string, amount float64) map[string]interface{} {
+	return map[string]interface{}{
+		"applicant_name": name, "applicant_ssn": ssn, "loan_amount": amount,
+		"loan_purpose": "Home Renovation", "annual_income": 75000, "credit_score": 720,
+	}
+}
+
+func TestDuplicatePolicies(t *testing.T) {
+	submit := func(router *gin.Engine, body map[string]interface{}) (int, model.LoanApplication) {
+		w := doJSON(router, http.MethodPost, "/v1/loan-applications", body)
+		var app model.LoanApplication
+		if w.Code == http.StatusCreated {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &app))
+		}
+		return w.Code, app
+	}
+
+	// Test Case 1: Reject refuses the submission and names the earlier application
+	router, memStore := setupDuplicateRouter(model.DuplicatePolicyReject)
+	_, first := submit(router, duplicateApplication("Nanda Putri", "123-45-6789", 20000))
+	assert.Nil(t, first.DuplicateCheck)
+	w := doJSON(router, http.MethodPost, "/v1/loan-applications", duplicateApplication("Budi", "123-45-6789", 50000))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "duplicate_application", problem.Code)
+	if assert.Len(t, problem.Duplicates, 1) {
+		assert.Equal(t, first.ID, problem.Duplicates[0].ApplicationID)
+		assert.Equal(t, model.DuplicateSameSSN, problem.Duplicates[0].Reason)
+	}
+	assert.Len(t, memStore.ListLoanApplications(), 1)
+
+	// Test Case 2: Link saves the submission and links both applications
+	router, memStore = setupDuplicateRouter(model.DuplicatePolicyLink)
+	_, first = submit(router, duplicateApplication("Nanda Putri", "123-45-6789", 20000))
+	code, second := submit(router, duplicateApplication("Nanda Putri", "123-45-6789", 20000))
+	assert.Equal(t, http.StatusCreated, code)
+	if assert.NotNil(t, second.DuplicateCheck) {
+		assert.Equal(t, model.DuplicateActionLinked, second.DuplicateCheck.Action)
+	}
+	assert.Equal(t, []int{first.ID}, second.LinkedApplications)
+	first, _ = memStore.GetLoanApplication(first.ID)
+	assert.Equal(t, []int{second.ID}, first.LinkedApplications)
+
+	// Test Case 3: Flag saves the submission for review without linking
+	router, memStore = setupDuplicateRouter(model.DuplicatePolicyFlag)
+	_, first = submit(router, duplicateApplication("Nanda Putri", "123-45-6789", 20000))
+	_, second = submit(router, duplicateApplication("Nanda Putri", "123-45-6789", 20000))
+	if assert.NotNil(t, second.DuplicateCheck) {
+		assert.Equal(t, model.DuplicateActionFlagged, second.DuplicateCheck.Action)
+		assert.Equal(t, first.ID, second.DuplicateCheck.Matches[0].ApplicationID)
+	}
+	assert.Empty(t, second.LinkedApplications)
+	first, _ = memStore.GetLoanApplication(first.ID)
+	assert.Empty(t, first.LinkedApplications)
+
+	// Test Case 4: Off checks nothing but still records the velocity
+	router, _ = setupDuplicateRouter(model.DuplicatePolicyOff)
+	submit(router, duplicateApplication("Nanda Putri", "123-45-6789", 20000))
+	code, second = submit(router, duplicateApplication("Nanda Putri", "123-45-6789", 20000))
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Nil(t, second.DuplicateCheck)
+	assert.Equal(t, &model.Velocity{Last30Days: 2, Last90Days: 2}, second.Velocity)
+
+	// Test Case 5: Applicants learn nothing about the matches
+	middleware.RegisterToken("duplicate-applicant", model.Principal{Subject: "nanda", Role: model.RoleApplicant})
+	body, _ := json.Marshal(duplicateApplication("Nanda Putri", "123-45-6789", 20000))
+	router, _ = setupDuplicateRouter(model.DuplicatePolicyReject)
+	submit(router, duplicateApplication("Nanda Putri", "123-45-6789", 20000))
+	w = doAs(router, "duplicate-applicant", http.MethodPost, "/v1/loan-applications", string(body))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	problem = decodeProblem(t, w)
+	assert.Equal(t, "duplicate_application", problem.Code)
+	assert.Empty(t, problem.Duplicates)
+	assert.NotContains(t, problem.Detail, "1")
+
+	router, _ = setupDuplicateRouter(model.DuplicatePolicyLink)
+	submit(router, duplicateApplication("Nanda Putri", "123-45-6789", 20000))
+	w = doAs(router, "duplicate-applicant", http.MethodPost, "/v1/loan-applications", string(body))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	for _, field := range []string{"velocity", "duplicate_check", "linked_applications"} {
+		assert.NotContains(t, w.Body.String(), field)
+	}
+	w = doAs(router, "duplicate-applicant", http.MethodGet, "/v1/loan-applications/2", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "linked_applications")
+	w = doJSON(router, http.MethodGet, "/v1/loan-applications/2", nil)
+	assert.Contains(t, w.Body.String(), `"linked_applications":[1]`)
+	assert.Contains(t, w.Body.String(), `"velocity"`)
+
+	// Test Case 6: Claiming an erased application does not escape the check
+	router, memStore = setupDuplicateRouter(model.DuplicatePolicyReject)
+	erased := duplicateApplication("Nanda Putri", "123-45-6789", 20000)
+	erased["anonymized_at"] = "2024-01-01T00:00:00Z"
+	code, first = submit(router, erased)
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Nil(t, first.AnonymizedAt)
+	code, _ = submit(router, erased)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Len(t, memStore.ListLoanApplications(), 1)
+}
+
+func TestDuplicateDetection(t *testing.T) {
+	rule := model.DuplicateRule{Policy: model.DuplicatePolicyFlag, Window: time.Hour, AmountTolerance: 0.05}
+	application := func(name, ssn string, amount float64) model.LoanApplication {
+		return model.LoanApplication{ApplicantName: name, ApplicantSSN: ssn, LoanAmount: amount}
+	}
+
+	// Test Case 1: Near-identical names and amounts match across SSNs
+	memStore := store.NewMemoryStore()
+	first, _ := memStore.SubmitLoanApplication(application("Nanda Putri", "123-45-6789", 20000), rule)
+	similar, err := memStore.SubmitLoanApplication(application("nanda  putry", "987-65-4321", 20500), rule)
+	assert.NoError(t, err)
+	if assert.NotNil(t, similar.DuplicateCheck) {
+		assert.Equal(t, []model.DuplicateMatch{{ApplicationID: first.ID, Reason: model.DuplicateSimilarApplicant, SubmittedAt: first.SubmittedAt}}, similar.DuplicateCheck.Matches)
+	}
+	assert.Equal(t, &model.Velocity{Last30Days: 1, Last90Days: 1}, similar.Velocity)
+
+	// Test Case 2: Different amounts or names do not match
+	other, _ := memStore.SubmitLoanApplication(application("Nanda Putri", "111-22-3333", 30000), rule)
+	assert.Nil(t, other.DuplicateCheck)
+	other, _ = memStore.SubmitLoanApplication(application("Budi Santoso", "222-33-4444", 20000), rule)
+	assert.Nil(t, other.DuplicateCheck)
+	memStore.SubmitLoanApplication(application("Ana", "333-44-5555", 40000), rule)
+	other, _ = memStore.SubmitLoanApplication(application("Ani", "444-55-6666", 40000), rule)
+	assert.Nil(t, other.DuplicateCheck, "short names must match exactly")
+
+	// Test Case 3: The SSN matches however it is formatted
+	memStore = store.NewMemoryStore()
+	first, _ = memStore.SubmitLoanApplication(application("Nanda", "123-45-6789", 20000), rule)
+	second, _ := memStore.SubmitLoanApplication(application("Budi", "123 45 6789", 90000), rule)
+	if assert.NotNil(t, second.DuplicateCheck) {
+		assert.Equal(t, model.DuplicateSameSSN, second.DuplicateCheck.Matches[0].Reason)
+	}
+	third, _ := memStore.SubmitLoanApplication(application("Citra", "123456789", 5000), rule)
+	if assert.NotNil(t, third.DuplicateCheck) {
+		assert.Len(t, third.DuplicateCheck.Matches, 2)
+		assert.Equal(t, first.ID, third.DuplicateCheck.Matches[0].ApplicationID)
+	}
+	assert.Equal(t, &model.Velocity{Last30Days: 3, Last90Days: 3}, third.Velocity)
+
+	// Test Case 4: Applications outside the window do not match
+	memStore = store.NewMemoryStore()
+	short := model.DuplicateRule{Policy: model.DuplicatePolicyReject, Window: 20 * time.Millisecond}
+	memStore.SubmitLoanApplication(application("Nanda", "123-45-6789", 20000), short)
+	time.Sleep(30 * time.Millisecond)
+	later, err := memStore.SubmitLoanApplication(application("Nanda", "123-45-6789", 20000), short)
+	assert.NoError(t, err)
+	assert.Nil(t, later.DuplicateCheck)
+	assert.Equal(t, 2, later.Velocity.Last30Days)
+
+	// Test Case 5: Rejections return the matches and save nothing
+	_, err = memStore.SubmitLoanApplication(application("Nanda", "123-45-6789", 20000), short)
+	var duplicateErr *store.DuplicateError
+	if assert.True(t, errors.As(err, &duplicateErr)) {
+		assert.Equal(t, later.ID, duplicateErr.Matches[0].ApplicationID)
+	}
+	assert.Len(t, memStore.ListLoanApplications(), 2)
+
+	// Test Case 6: Erased and deleted applications are forgotten
+	memStore = store.NewMemoryStore()
+	first, _ = memStore.SubmitLoanApplication(application("Nanda", "123-45-6789", 20000), rule)
+	second, _ = memStore.SubmitLoanApplication(application("Budi", "987-65-4321", 20000), rule)
+	_, _, err = memStore.AnonymizeLoanApplication(first.ID, time.Now())
+	assert.NoError(t, err)
+	_, err = memStore.DeleteLoanApplication(second.ID)
+	assert.NoError(t, err)
+	for i, ssn := range []string{"123-45-6789", "987-65-4321"} {
+		app, _ := memStore.SubmitLoanApplication(application("Citra", ssn, float64(60000*(i+1))), rule)
+		assert.Nil(t, app.DuplicateCheck)
+		assert.Equal(t, 1, app.Velocity.Last30Days)
+	}
+
+	// Test Case 7: A configured secret keys the SSN index
+	memStore = store.NewMemoryStoreWithSSNSecret([]byte("0123456789abcdef0123456789abcdef"))
+	first, _ = memStore.SubmitLoanApplication(application("Nanda", "123-45-6789", 20000), rule)
+	second, _ = memStore.ForTenant("acme").SubmitLoanApplication(application("Nanda", "123-45-6789", 20000), rule)
+	assert.Nil(t, second.DuplicateCheck, "tenants are checked separately")
+	assert.Equal(t, []int{first.ID}, memStore.ApplicationsForSSN("123456789"))
+}
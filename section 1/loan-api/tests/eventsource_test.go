@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"loan-api/model"
+	"loan-api/store"
+)
+
+// tick separates the times of consecutive store calls.
+func tick() time.Time {
+	time.Sleep(time.Millisecond)
+	return time.Now()
+}
+
+func TestLoanApplicationAsOf(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	beforeSubmission := tick()
+	app := memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Nanda", ApplicantSSN: "123-45-6789", LoanAmount: 20000})
+
+	// Test Case 1: Every past state is rebuilt, across many snapshots
+	states := []model.LoanApplication{app}
+	times := []time.Time{tick()}
+	statuses := []string{model.StatusUnderReview, model.StatusPending}
+	for i := range 45 {
+		var next model.LoanApplication
+		switch i % 3 {
+		case 0:
+			next, _ = memStore.UpdateLoanApplicationStatus(app.ID, statuses[i%2])
+		case 1:
+			next, _, _ = memStore.AddDocumentToApplication(app.ID, model.Document{Name: fmt.Sprintf("doc_%d.pdf", i), Type: "pay_stub"})
+		case 2:
+			next, _ = memStore.AssignLoanApplication(app.ID, model.Officer{Subject: fmt.Sprintf("officer%d", i)})
+		}
+		states = append(states, next)
+		times = append(times, tick())
+	}
+	for i, at := range times {
+		past, found := memStore.LoanApplicationAsOf(app.ID, at)
+		assert.True(t, found)
+		assert.Equal(t, states[i], past, "state %d", i)
+	}
+	current, _ := memStore.GetLoanApplication(app.ID)
+	assert.Equal(t, current, states[len(states)-1])
+
+	// Test Case 2: Applications did not exist before their submission
+	_, found := memStore.LoanApplicationAsOf(app.ID, beforeSubmission)
+	assert.False(t, found)
+	_, found = memStore.LoanApplicationAsOf(99, time.Now())
+	assert.False(t, found)
+
+	// Test Case 3: Anonymization erases personal data from past states too
+	_, _, err := memStore.AnonymizeLoanApplication(app.ID, time.Now())
+	assert.NoError(t, err)
+	past, found := memStore.LoanApplicationAsOf(app.ID, times[10])
+	assert.True(t, found)
+	assert.Equal(t, model.ErasedValue, past.ApplicantName)
+	assert.Empty(t, past.ApplicantSSN)
+	assert.Empty(t, past.Documents)
+	assert.Nil(t, past.AnonymizedAt)
+	assert.Equal(t, states[10].Status, past.Status)
+	assert.Equal(t, states[10].AssignedTo, past.AssignedTo)
+	current, _ = memStore.GetLoanApplication(app.ID)
+	now, _ := memStore.LoanApplicationAsOf(app.ID, time.Now())
+	assert.Equal(t, current, now)
+	assert.NotNil(t, now.AnonymizedAt)
+
+	// Test Case 4: Deleted applications are gone from the past as well
+	_, err = memStore.DeleteLoanApplication(app.ID)
+	assert.NoError(t, err)
+	_, found = memStore.LoanApplicationAsOf(app.ID, times[10])
+	assert.False(t, found)
+}
+
+func TestGetLoanApplicationAsOf(t *testing.T) {
+	router, memStore := setupRouter()
+	app := memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Budi", ApplicantSSN: "123-45-6789", LoanAmount: 20000})
+	submitted := tick()
+	memStore.UpdateLoanApplicationStatus(app.ID, model.StatusUnderReview)
+	reviewed := tick()
+	memStore.UpdateLoanApplicationStatus(app.ID, model.StatusApproved)
+
+	asOf := func(at string) string {
+		return fmt.Sprintf("/v1/loan-applications/%d?as_of=%s", app.ID, url.QueryEscape(at))
+	}
+
+	// Test Case 1: as_of returns the state at that time
+	var past model.LoanApplication
+	w := doJSON(router, http.MethodGet, asOf(submitted.Format(time.RFC3339Nano)), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &past))
+	assert.Equal(t, model.StatusPending, past.Status)
+	assert.Equal(t, "XXX-XX-6789", past.ApplicantSSN)
+	w = doJSON(router, http.MethodGet, asOf(reviewed.Format(time.RFC3339Nano)), nil)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &past))
+	assert.Equal(t, model.StatusUnderReview, past.Status)
+	assert.Nil(t, past.ProcessedAt)
+	w = doJSON(router, http.MethodGet, fmt.Sprintf("/v1/loan-applications/%d", app.ID), nil)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &past))
+	assert.Equal(t, model.StatusApproved, past.Status)
+
+	// Test Case 2: Before submission the application is not found
+	w = doJSON(router, http.MethodGet, asOf("2020-01-01T00:00:00Z"), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Test Case 3: as_of must be a timestamp
+	w = doJSON(router, http.MethodGet, asOf("2020-01-01"), nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_parameter", decodeProblem(t, w).Code)
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+	"loan-api/handler"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/store"
+)
+
+func setupExportRouter() (*gin.Engine, *store.MemoryStore, *store.AuditStore) {
+	r := gin.New()
+	memStore := store.NewMemoryStore()
+	auditStore := store.NewAuditStore()
+	routes.SetupRoutes(r, routes.Handlers{
+		Loan:   handler.NewLoanHandler(memStore, nil),
+		Export: handler.NewExportHandler(memStore, auditStore),
+		Audit:  handler.NewAuditHandler(auditStore),
+	})
+
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Nanda", ApplicantSSN: "123-45-6789", LoanAmount: 50000, LoanPurpose: "Home Renovation", AnnualIncome: 75000, CreditScore: 720})
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Budi", ApplicantSSN: "987-65-4321", LoanAmount: 20000, LoanPurpose: "Education", AnnualIncome: 40000, CreditScore: 680})
+	memStore.UpdateLoanApplicationStatus(2, "approved")
+	return r, memStore, auditStore
+}
+
+func doExport(router *gin.Engine, token, query string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodGet, "/loan-applications/export"+query, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestExportLoanApplications(t *testing.T) {
+	router, _, auditStore := setupExportRouter()
+	middleware.RegisterToken("export-officer", model.Principal{Subject: "siti", Role: model.RoleOfficer})
+	middleware.RegisterToken("export-pii-officer", model.Principal{Subject: "agus", Role: model.RoleOfficer, Permissions: []string{model.PermissionViewPII}})
+
+	// Test Case 1: CSV export masks SSNs without the PII permission
+	w := doExport(router, "export-officer", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	records, err := csv.NewReader(w.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, "applicant_ssn", records[0][2])
+	assert.Equal(t, "XXX-XX-6789", records[1][2])
+
+	// Test Case 2: NDJSON export with the PII permission and filters
+	w = doExport(router, "export-pii-officer", "?format=ndjson&status=approved&loan_purpose=education")
+	assert.Equal(t, http.StatusOK, w.Code)
+	scanner := bufio.NewScanner(w.Body)
+	var apps []model.LoanApplication
+	for scanner.Scan() {
+		var app model.LoanApplication
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &app))
+		apps = append(apps, app)
+	}
+	assert.Len(t, apps, 1)
+	assert.Equal(t, "987-65-4321", apps[0].ApplicantSSN)
+
+	// Test Case 3: Parquet export
+	w = doExport(router, "export-officer", "?format=parquet")
+	assert.Equal(t, http.StatusOK, w.Code)
+	file, err := buffer.NewBufferFile(w.Body.Bytes())
+	assert.NoError(t, err)
+	pr, err := reader.NewParquetReader(file, nil, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), pr.GetNumRows())
+	pr.ReadStop()
+
+	// Test Case 4: Invalid parameters
+	w = doExport(router, "export-officer", "?format=xlsx")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doExport(router, "export-officer", "?from=yesterday")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Test Case 5: Every export is audited, and only admins read the audit log
+	entries := auditStore.List(model.AuditApplicationsExported)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "siti", entries[0].Actor)
+	assert.Equal(t, "parquet", entries[0].Details["format"])
+	assert.Equal(t, "true", entries[1].Details["include_pii"])
+	assert.Equal(t, "1", entries[1].Details["rows"])
+
+	req, _ := http.NewRequest(http.MethodGet, "/audit-log", nil)
+	req.Header.Set("Authorization", "Bearer export-officer")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doJSON(router, http.MethodGet, "/audit-log?action="+model.AuditApplicationsExported, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), `"actor":"agus"`))
+}
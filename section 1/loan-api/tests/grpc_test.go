@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"loan-api/handler"
+	"loan-api/middleware"
+	"loan-api/model"
+	loanv1 "loan-api/proto/loan/v1"
+	"loan-api/routes"
+	"loan-api/store"
+)
+
+func setupGRPC(t *testing.T) (loanv1.LoanServiceClient, *store.MemoryStore, string) {
+	memStore := store.NewMemoryStore()
+	loanHandler := handler.NewLoanHandler(memStore, nil)
+	loanHandler.UploadDir = t.TempDir()
+	loanHandler.MaxDocumentBytes = 1 << 10
+
+	listener := bufconn.Listen(1 << 20)
+	server := routes.NewGRPCServer(handler.NewLoanGRPCServer(loanHandler))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	middleware.RegisterToken("grpc-applicant", model.Principal{Subject: "dewi", Role: model.RoleApplicant})
+	return loanv1.NewLoanServiceClient(conn), memStore, loanHandler.UploadDir
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func errorReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+	return ""
+}
+
+func TestGRPCLoanService(t *testing.T) {
+	client, memStore, _ := setupGRPC(t)
+	admin := withToken("mysecrettoken")
+	submit := &loanv1.SubmitLoanApplicationRequest{
+		ApplicantName: "Nanda", ApplicantSsn: "123-45-6789", LoanAmount: 20000,
+		LoanPurpose: "Home Renovation", AnnualIncome: 75000, CreditScore: 720,
+	}
+
+	// Test Case 1: Calls need a token
+	_, err := client.SubmitLoanApplication(context.Background(), submit)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "unauthorized", errorReason(err))
+
+	// Test Case 2: Submitted applications are stored and returned masked
+	created, err := client.SubmitLoanApplication(withToken("grpc-applicant"), submit)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), created.GetId())
+	assert.Equal(t, "XXX-XX-6789", created.GetApplicantSsn())
+	assert.Equal(t, "dewi", created.GetSubmittedBy())
+	stored, _ := memStore.GetLoanApplication(1)
+	assert.Equal(t, "123-45-6789", stored.ApplicantSSN)
+
+	// Test Case 3: Validation errors carry the field violations
+	submit.CreditScore = 200
+	_, err = client.SubmitLoanApplication(admin, submit)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "validation_failed", errorReason(err))
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = badRequest.GetFieldViolations()
+		}
+	}
+	assert.Len(t, violations, 1)
+	assert.Equal(t, "credit_score", violations[0].GetField())
+
+	// Test Case 4: Status updates follow the REST rules
+	app, err := client.UpdateLoanApplicationStatus(admin, &loanv1.UpdateLoanApplicationStatusRequest{Id: 1, Status: model.StatusUnderReview})
+	assert.NoError(t, err)
+	assert.Equal(t, model.StatusUnderReview, app.GetStatus())
+	_, err = client.UpdateLoanApplicationStatus(admin, &loanv1.UpdateLoanApplicationStatusRequest{Id: 1, Status: model.StatusFunded})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, "invalid_status_transition", errorReason(err))
+	_, err = client.GetLoanApplication(admin, &loanv1.GetLoanApplicationRequest{Id: 99})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.UpdateLoanApplicationStatus(withToken("grpc-applicant"), &loanv1.UpdateLoanApplicationStatusRequest{Id: 1, Status: model.StatusApproved})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	stored, _ = memStore.GetLoanApplication(1)
+	assert.Equal(t, model.StatusUnderReview, stored.Status)
+
+	// Test Case 5: Listing streams the filtered applications in ID order
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Budi", ApplicantSSN: "987-65-4321"})
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Eka", ApplicantSSN: "111-22-3333"})
+	stream, err := client.ListLoanApplications(admin, &loanv1.ListLoanApplicationsRequest{Status: model.StatusPending})
+	assert.NoError(t, err)
+	var ids []int64
+	for {
+		app, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		assert.Contains(t, app.GetApplicantSsn(), "XXX-XX-")
+		ids = append(ids, app.GetId())
+	}
+	assert.Equal(t, []int64{2, 3}, ids)
+}
+
+func TestGRPCUploadDocument(t *testing.T) {
+	client, memStore, uploadDir := setupGRPC(t)
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Nanda", ApplicantSSN: "123-45-6789"})
+	admin := withToken("mysecrettoken")
+
+	upload := func(id int64, chunks ...string) (*loanv1.LoanApplication, error) {
+		stream, err := client.UploadDocument(admin)
+		assert.NoError(t, err)
+		stream.Send(&loanv1.UploadDocumentRequest{Payload: &loanv1.UploadDocumentRequest_Metadata{
+			Metadata: &loanv1.DocumentMetadata{ApplicationId: id, Filename: "payslip.pdf", DocumentType: "pay_stub"},
+		}})
+		for _, chunk := range chunks {
+			stream.Send(&loanv1.UploadDocumentRequest{Payload: &loanv1.UploadDocumentRequest_Chunk{Chunk: []byte(chunk)}})
+		}
+		return stream.CloseAndRecv()
+	}
+
+	// Test Case 1: Chunks are joined into one document
+	app, err := upload(1, "%PDF-", "1.4")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"doc_1_payslip.pdf"}, app.GetDocumentsUploaded())
+	assert.Equal(t, "pay_stub", app.GetDocuments()[0].GetType())
+	stored, _ := memStore.GetLoanApplication(1)
+	content, err := os.ReadFile(stored.Documents[0].Path)
+	assert.NoError(t, err)
+	assert.Equal(t, "%PDF-1.4", string(content))
+
+	// Test Case 2: Unknown applications and empty documents leave no file behind
+	_, err = upload(99, "%PDF-1.4")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = upload(1)
+	assert.Equal(t, "document_missing", errorReason(err))
+	entries, _ := os.ReadDir(uploadDir)
+	assert.Len(t, entries, 1)
+
+	// Test Case 3: Documents over the size limit are refused and removed
+	_, err = upload(1, strings.Repeat("x", 600), strings.Repeat("x", 600))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, "document_too_large", errorReason(err))
+	entries, _ = os.ReadDir(uploadDir)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/handler"
+	"loan-api/health"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/store"
+)
+
+func probe(router *gin.Engine, path string) (*httptest.ResponseRecorder, health.Report) {
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var report health.Report
+	json.Unmarshal(w.Body.Bytes(), &report)
+	return w, report
+}
+
+func TestHealthProbes(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Nanda"})
+	memStore.ForTenant("acme").SaveLoanApplication(model.LoanApplication{ApplicantName: "Eka"})
+	checker := health.NewChecker()
+	checker.Register("store", health.Store(memStore))
+	checker.Register("uploads", health.Writable(t.TempDir()))
+
+	r := gin.New()
+	routes.SetupRoutes(r, routes.Handlers{
+		Loan:   handler.NewLoanHandler(memStore, nil),
+		Health: handler.NewHealthHandler(checker),
+	})
+
+	// Test Case 1: Probes need no token
+	w, report := probe(r, "/healthz")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, health.StatusOK, report.Status)
+
+	// Test Case 2: Readiness reports every check with details
+	w, report = probe(r, "/readyz")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, health.StatusOK, report.Checks["store"].Status)
+	assert.Equal(t, 2.0, report.Checks["store"].Details["applications"])
+	assert.Equal(t, 2.0, report.Checks["store"].Details["tenants"])
+	assert.Equal(t, health.StatusOK, report.Checks["uploads"].Status)
+
+	// Test Case 3: A failing check makes the service unready but still alive
+	checker.Register("uploads", health.Writable(filepath.Join(t.TempDir(), "missing")))
+	w, report = probe(r, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, health.StatusFailing, report.Status)
+	assert.Contains(t, report.Checks["uploads"].Error, "not writable")
+	assert.Equal(t, health.StatusOK, report.Checks["store"].Status)
+	w, _ = probe(r, "/healthz")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Test Case 4: Concurrent pings of every tenant succeed
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, memStore.Ping(context.Background()))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Timeout = 20 * time.Millisecond
+	checker.Register("stuck", func(ctx context.Context) (map[string]any, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	})
+	checker.Register("broken", func(ctx context.Context) (map[string]any, error) {
+		return map[string]any{"attempts": 3}, errors.New("connection refused")
+	})
+
+	// Test Case 1: Checks that do not answer in time fail
+	start := time.Now()
+	report := checker.Run(context.Background())
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.False(t, report.OK())
+	assert.Contains(t, report.Checks["stuck"].Error, "timed out")
+
+	// Test Case 2: Errors keep the check's details
+	assert.Equal(t, "connection refused", report.Checks["broken"].Error)
+	assert.Equal(t, 3, report.Checks["broken"].Details["attempts"])
+}
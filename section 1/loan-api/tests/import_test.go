@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/events"
+	"loan-api/handler"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/store"
+)
+
+func setupImportRouter() (*gin.Engine, *store.MemoryStore) {
+	return setupImportRouterWithLimits(model.ImportLimits{MaxRows: 100, MaxBytes: 1 << 20, AsyncThreshold: 5})
+}
+
+func setupImportRouterWithLimits(limits model.ImportLimits) (*gin.Engine, *store.MemoryStore) {
+	r := gin.New()
+	memStore := store.NewMemoryStore()
+	loanHandler := handler.NewLoanHandler(memStore, events.NewBus())
+	importHandler := handler.NewImportHandler(loanHandler, store.NewImportStore(), limits)
+	routes.SetupRoutes(r, routes.Handlers{Loan: loanHandler, Import: importHandler})
+	return r, memStore
+}
+
+func doImport(router *gin.Engine, query, contentType, body string) (*httptest.ResponseRecorder, model.ImportJob) {
+	req, _ := http.NewRequest(http.MethodPost, "/loan-applications:import"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer mysecrettoken")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var job model.ImportJob
+	json.Unmarshal(w.Body.Bytes(), &job)
+	return w, job
+}
+
+const importCSV = "\ufeffapplicant_name,applicant_ssn,loan_amount,loan_purpose,annual_income,credit_score\n" +
+	"Nanda,123-45-6789,50000,Home Renovation,75000,720\n" +
+	"Budi,000-45-6789,50000,Education,75000,720\n" +
+	"Sari,123-45-6789,lots,Education,75000,720\n" +
+	"Dewi,123-45-6789,20000,Car Purchase,60000,690\n"
+
+func TestImportLoanApplications(t *testing.T) {
+	router, memStore := setupImportRouter()
+
+	// Test Case 1: Dry run validates every row without creating anything
+	w, job := doImport(router, "?dry_run=true", "text/csv", importCSV)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, model.ImportCompleted, job.Status)
+	assert.True(t, job.DryRun)
+	assert.Equal(t, 4, job.TotalRows)
+	assert.Equal(t, 2, job.Valid)
+	assert.Equal(t, 2, job.Failed)
+	assert.Equal(t, 0, job.Created)
+	assert.Empty(t, memStore.ListLoanApplications())
+
+	// Test Case 2: Per-row report uses file line numbers and field errors
+	assert.Equal(t, 2, job.Rows[0].Row)
+	assert.Equal(t, 3, job.Rows[1].Row)
+	assert.Equal(t, "applicant_ssn", job.Rows[1].Errors[0].Field)
+	assert.Equal(t, "invalid_format", job.Rows[1].Errors[0].Code)
+	assert.Equal(t, "loan_amount", job.Rows[2].Errors[0].Field)
+	assert.Equal(t, "invalid_type", job.Rows[2].Errors[0].Code)
+
+	// Test Case 3: A real import creates the valid rows only
+	w, job = doImport(router, "", "text/csv; charset=utf-8", importCSV)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, job.Created)
+	assert.NotZero(t, job.Rows[0].ApplicationID)
+	assert.Zero(t, job.Rows[1].ApplicationID)
+	created, found := memStore.GetLoanApplication(job.Rows[3].ApplicationID)
+	assert.True(t, found)
+	assert.Equal(t, "Dewi", created.ApplicantName)
+	assert.Equal(t, "admin", created.SubmittedBy)
+	assert.Equal(t, model.CountryUS, created.Country)
+
+	// Test Case 4: Unknown columns and formats are rejected
+	w, _ = doImport(router, "", "text/csv", "applicant_name,favourite_colour\nNanda,blue\n")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = doImport(router, "", "application/xml", "<applications/>")
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	// Test Case 5: Files over the row or byte limit are rejected
+	router, memStore = setupImportRouterWithLimits(model.ImportLimits{MaxRows: 3, MaxBytes: 1 << 20, AsyncThreshold: 5})
+	w, _ = doImport(router, "", "text/csv", importCSV)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, "import_too_large", decodeProblem(t, w).Code)
+	router, memStore = setupImportRouterWithLimits(model.ImportLimits{MaxRows: 100, MaxBytes: 128, AsyncThreshold: 5})
+	w, _ = doImport(router, "", "text/csv", importCSV)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, "An import may be at most 128 bytes", decodeProblem(t, w).Detail)
+	assert.Empty(t, memStore.ListLoanApplications())
+}
+
+func TestImportLoanApplicationsAsync(t *testing.T) {
+	router, memStore := setupImportRouter()
+
+	var body strings.Builder
+	for i := 0; i < 8; i++ {
+		fmt.Fprintf(&body, `{"applicant_name":"Applicant %d","applicant_ssn":"123-45-6789","loan_amount":5000,"loan_purpose":"personal","annual_income":1000,"credit_score":700}`+"\n", i)
+	}
+	body.WriteString("\n{not json}\n")
+
+	// Test Case 1: Imports above the threshold run in the background
+	w, job := doImport(router, "", "application/x-ndjson", body.String())
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, fmt.Sprintf("/v1/loan-applications/imports/%d", job.ID), w.Header().Get("Location"))
+	assert.Equal(t, 9, job.TotalRows)
+
+	// Test Case 2: The status endpoint reports the finished job
+	deadline := time.Now().Add(2 * time.Second)
+	for job.Status != model.ImportCompleted && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		status := doJSON(router, http.MethodGet, w.Header().Get("Location"), nil)
+		assert.Equal(t, http.StatusOK, status.Code)
+		json.Unmarshal(status.Body.Bytes(), &job)
+	}
+	assert.Equal(t, model.ImportCompleted, job.Status)
+	assert.Equal(t, 8, job.Created)
+	assert.Equal(t, 1, job.Failed)
+	assert.Equal(t, 10, job.Rows[8].Row)
+	assert.NotEmpty(t, job.Rows[8].Error)
+	assert.Len(t, memStore.ListLoanApplications(), 8)
+
+	// Test Case 3: Unknown jobs and custom methods
+	w = doJSON(router, http.MethodGet, "/loan-applications/imports/999", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doJSON(router, http.MethodPost, "/loan-applications:purge", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestImportJobLimit(t *testing.T) {
+	// Events are delivered while the application is created, so a blocked
+	// subscriber holds the first background import.
+	bus := events.NewBus()
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	bus.Subscribe(func(model.Event) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+	})
+	r := gin.New()
+	loanHandler := handler.NewLoanHandler(store.NewMemoryStore(), bus)
+	importHandler := handler.NewImportHandler(loanHandler, store.NewImportStore(), model.ImportLimits{MaxRows: 100, MaxBytes: 1 << 20, AsyncThreshold: 5, MaxRunningJobs: 1})
+	routes.SetupRoutes(r, routes.Handlers{Loan: loanHandler, Import: importHandler})
+	row := `{"applicant_name":"Nanda","applicant_ssn":"123-45-6789","loan_amount":5000,"loan_purpose":"personal","annual_income":1000,"credit_score":700}` + "\n"
+
+	// Test Case 1: The Location header keeps the request's version
+	req, _ := http.NewRequest(http.MethodPost, "/v2/loan-applications:import?async=true", strings.NewReader(row))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Authorization", "Bearer mysecrettoken")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/v2/loan-applications/imports/1", w.Header().Get("Location"))
+	<-started
+
+	// Test Case 2: Background imports beyond the limit are refused
+	w, _ = doImport(r, "?async=true", "application/x-ndjson", row)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "imports_busy", decodeProblem(t, w).Code)
+	w, _ = doImport(r, "?dry_run=true", "application/x-ndjson", row)
+	assert.Equal(t, http.StatusOK, w.Code, "imports run in the request are not limited")
+
+	// Test Case 3: A finished import frees its slot
+	close(release)
+	var job model.ImportJob
+	deadline := time.Now().Add(2 * time.Second)
+	for job.Status != model.ImportCompleted && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		json.Unmarshal(doJSON(r, http.MethodGet, "/v2/loan-applications/imports/1", nil).Body.Bytes(), &job)
+	}
+	assert.Equal(t, model.ImportCompleted, job.Status)
+	assert.Eventually(t, func() bool {
+		w, _ = doImport(r, "?async=true", "application/x-ndjson", row)
+		return w.Code == http.StatusAccepted
+	}, 2*time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"loan-api/loadtest"
+)
+
+func TestParseRate(t *testing.T) {
+	// Test Case 1: Rates are a count per second, minute, hour or day
+	rate, err := loadtest.ParseRate("5000/d")
+	assert.NoError(t, err)
+	assert.Equal(t, loadtest.Rate{Count: 5000, Per: 24 * time.Hour}, rate)
+	assert.Equal(t, 17280*time.Millisecond, rate.Interval())
+	assert.Equal(t, "5000/d", rate.String())
+	rate, err = loadtest.ParseRate("120/m")
+	assert.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, rate.Interval())
+
+	// Test Case 2: A zero rate disables a scenario
+	rate, err = loadtest.ParseRate("0/s")
+	assert.NoError(t, err)
+	assert.Zero(t, rate.Interval())
+
+	// Test Case 3: Anything else is rejected
+	for _, invalid := range []string{"", "5000", "5000/w", "-1/s", "x/s"} {
+		_, err := loadtest.ParseRate(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestRunner(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1)%4 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	get := func(ctx context.Context, i int) error {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return &loadtest.StatusError{Code: resp.StatusCode}
+		}
+		return nil
+	}
+	slow := func(ctx context.Context, i int) error {
+		select {
+		case <-time.After(time.Second):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	runner := loadtest.Runner{Duration: 500 * time.Millisecond, MaxInFlight: 2, Timeout: 200 * time.Millisecond}
+	report := runner.Run(context.Background(), []loadtest.Scenario{
+		{Name: "server", Rate: loadtest.Rate{Count: 100, Per: time.Second}, Do: get},
+		{Name: "slow", Rate: loadtest.Rate{Count: 20, Per: time.Second}, Do: slow},
+		{Name: "disabled", Rate: loadtest.Rate{Count: 0, Per: time.Second}, Do: get},
+	})
+
+	// Test Case 1: Requests are sent on schedule and failures grouped by status
+	server1 := report.Scenarios[0]
+	assert.Equal(t, "server", server1.Name)
+	assert.Equal(t, "100/s", server1.Rate)
+	assert.InDelta(t, 50, server1.Sent, 15)
+	assert.Equal(t, server1.Sent, server1.Succeeded+server1.Failed)
+	assert.Equal(t, map[string]int{"http_503": server1.Failed}, server1.Errors)
+	assert.InDelta(t, 0.25, server1.ErrorRate, 0.1)
+	assert.Greater(t, server1.P50, 0.0)
+	assert.LessOrEqual(t, server1.P50, server1.P99)
+	assert.LessOrEqual(t, server1.P99, server1.Max)
+
+	// Test Case 2: Timeouts are counted and requests beyond the limit dropped
+	slowResult := report.Scenarios[1]
+	assert.Equal(t, slowResult.Failed, slowResult.Errors["timeout"])
+	assert.Greater(t, slowResult.Failed, 0)
+	assert.Greater(t, slowResult.Dropped, 0)
+	assert.Zero(t, slowResult.Succeeded)
+	assert.Equal(t, 1.0, slowResult.ErrorRate)
+
+	// Test Case 3: Disabled scenarios send nothing
+	assert.Zero(t, report.Scenarios[2].Sent)
+
+	// Test Case 4: Cancelling the run stops the schedule
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	runner.Duration = time.Minute
+	report = runner.Run(ctx, []loadtest.Scenario{{Name: "cancelled", Rate: loadtest.Rate{Count: 1, Per: time.Second}, Do: slow}})
+	assert.Less(t, report.Duration, time.Second)
+	assert.Equal(t, map[string]int{"cancelled": 1}, report.Scenarios[0].Errors)
+}
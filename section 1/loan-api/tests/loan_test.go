@@ -6,12 +6,17 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"loan-api/apperror"
+	"loan-api/events"
 	"loan-api/handler"
+	"loan-api/middleware"
 	"loan-api/model"
 	"loan-api/routes"
 	"loan-api/store"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 )
@@ -19,8 +24,8 @@ import (
 func setupRouter() (*gin.Engine, *store.MemoryStore) {
 	r := gin.New()
 	memStore := store.NewMemoryStore()
-	loanHandler := handler.NewLoanHandler(memStore)
-	routes.SetupRoutes(r, loanHandler)
+	loanHandler := handler.NewLoanHandler(memStore, events.NewBus())
+	routes.SetupRoutes(r, routes.Handlers{Loan: loanHandler})
 	return r, memStore
 }
 
@@ -69,11 +74,11 @@ func TestSubmitLoanApplication(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	var errResponse model.ErrorResponse
+	var errResponse apperror.Problem
 	err = json.Unmarshal(w.Body.Bytes(), &errResponse)
 	assert.NoError(t, err)
-	assert.Contains(t, errResponse.Error, "Invalid input")
-	assert.Contains(t, errResponse.Details[0], "ApplicantName is a required field")
+	assert.Contains(t, errResponse.Title, "Invalid input")
+	assert.Contains(t, errResponse.Errors[0].Message, "applicant_name is a required field")
 
 	invalidSSNApp := model.LoanApplication{
 		ApplicantName: "Jane Doe",
@@ -91,9 +96,12 @@ func TestSubmitLoanApplication(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
+	errResponse = apperror.Problem{}
 	err = json.Unmarshal(w.Body.Bytes(), &errResponse)
 	assert.NoError(t, err)
-	assert.Contains(t, errResponse.Error, "Invalid SSN format")
+	assert.Contains(t, errResponse.Title, "Invalid input")
+	assert.Equal(t, "applicant_ssn", errResponse.Errors[0].Field)
+	assert.Equal(t, "invalid_format", errResponse.Errors[0].Code)
 
 	// Test Case 4: Unauthorized request
 	req, _ = http.NewRequest(http.MethodPost, "/loan-applications", bytes.NewBuffer(jsonBody))
@@ -140,10 +148,10 @@ func TestGetLoanApplication(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
-	var errResponse model.ErrorResponse
+	var errResponse apperror.Problem
 	err = json.Unmarshal(w.Body.Bytes(), &errResponse)
 	assert.NoError(t, err)
-	assert.Equal(t, "Loan application not found", errResponse.Error)
+	assert.Equal(t, "Loan application not found", errResponse.Title)
 
 	// Test Case 3: Invalid ID format
 	req, _ = http.NewRequest(http.MethodGet, "/loan-applications/abc", nil)
@@ -154,7 +162,8 @@ func TestGetLoanApplication(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	err = json.Unmarshal(w.Body.Bytes(), &errResponse)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid application ID", errResponse.Error)
+	assert.Equal(t, "invalid_id", errResponse.Code)
+	assert.Equal(t, "ID must be an integer", errResponse.Detail)
 
 	// Test Case 4: Unauthorized request
 	req, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/loan-applications/%d", app1.ID), nil)
@@ -272,10 +281,10 @@ func TestUpdateLoanApplicationStatus(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	var errResponse model.ErrorResponse
+	var errResponse apperror.Problem
 	err = json.Unmarshal(w.Body.Bytes(), &errResponse)
 	assert.NoError(t, err)
-	assert.Contains(t, errResponse.Error, "Invalid status")
+	assert.Contains(t, errResponse.Title, "Invalid status")
 
 	// Test Case 3: Update non-existent application
 	statusUpdate = map[string]string{"status": "rejected"}
@@ -289,5 +298,90 @@ func TestUpdateLoanApplicationStatus(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	err = json.Unmarshal(w.Body.Bytes(), &errResponse)
 	assert.NoError(t, err)
-	assert.Equal(t, "Loan application not found", errResponse.Error)
+	assert.Equal(t, "Loan application not found", errResponse.Title)
+}
+
+func TestApplicantAccess(t *testing.T) {
+	router := gin.New()
+	memStore := store.NewMemoryStore()
+	loanHandler := handler.NewLoanHandler(memStore, events.NewBus())
+	loanHandler.UploadDir = t.TempDir()
+	routes.SetupRoutes(router, routes.Handlers{Loan: loanHandler})
+	middleware.RegisterToken("access-dewi", model.Principal{Subject: "dewi", Role: model.RoleApplicant})
+	own := memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Dewi", ApplicantSSN: "123-45-6789", LoanAmount: 20000, SubmittedBy: "dewi"})
+	other := memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Eka", ApplicantSSN: "987-65-4321", LoanAmount: 30000, SubmittedBy: "eka"})
+	upload := func(id int) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("document", "pay_stub.pdf")
+		part.Write([]byte("%PDF-1.4"))
+		form.Close()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/v1/loan-applications/%d/documents", id), &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer access-dewi")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Test Case 1: Applicants only list their own applications
+	w := doAs(router, "access-dewi", http.MethodGet, "/v1/loan-applications", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var apps []model.LoanApplication
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &apps))
+	if assert.Len(t, apps, 1) {
+		assert.Equal(t, own.ID, apps[0].ID)
+	}
+
+	// Test Case 2: Other applications are not found, now or in the past
+	w = doAs(router, "access-dewi", http.MethodGet, fmt.Sprintf("/v1/loan-applications/%d", own.ID), "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doAs(router, "access-dewi", http.MethodGet, fmt.Sprintf("/v1/loan-applications/%d", other.ID), "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doAs(router, "access-dewi", http.MethodGet, fmt.Sprintf("/v1/loan-applications/%d?as_of=%s", other.ID, time.Now().Format(time.RFC3339Nano)), "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Test Case 3: Applicants upload to their own applications only
+	w = upload(own.ID)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = upload(other.ID)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	other, _ = memStore.GetLoanApplication(other.ID)
+	assert.Empty(t, other.Documents)
+
+	// Test Case 4: Applicants cannot change statuses, not even their own
+	w = doAs(router, "access-dewi", http.MethodPut, fmt.Sprintf("/v1/loan-applications/%d/status", own.ID), `{"status": "approved"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	own, _ = memStore.GetLoanApplication(own.ID)
+	assert.Equal(t, model.StatusPending, own.Status)
+}
+
+func TestDocumentSizeLimit(t *testing.T) {
+	router := gin.New()
+	memStore := store.NewMemoryStore()
+	loanHandler := handler.NewLoanHandler(memStore, events.NewBus())
+	loanHandler.UploadDir = t.TempDir()
+	loanHandler.MaxDocumentBytes = 1 << 10
+	routes.SetupRoutes(router, routes.Handlers{Loan: loanHandler})
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Nanda", ApplicantSSN: "123-45-6789"})
+
+	// Test Case 1: Documents within the limit are stored
+	w := uploadDocument(router, "1", "payslip.pdf", "pay_stub")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Test Case 2: Larger uploads are refused before anything is saved
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("document", "scan.pdf")
+	part.Write(bytes.Repeat([]byte("x"), 2<<10))
+	form.Close()
+	req, _ := http.NewRequest(http.MethodPost, "/loan-applications/1/documents", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer mysecrettoken")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, "document_too_large", decodeProblem(t, w).Code)
+	entries, _ := os.ReadDir(loanHandler.UploadDir)
+	assert.Len(t, entries, 1)
 }
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"loan-api/handler"
+	"loan-api/metrics"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/sla"
+	"loan-api/store"
+)
+
+func TestHTTPMetrics(t *testing.T) {
+	r := gin.New()
+	memStore := store.NewMemoryStore()
+	loanHandler := handler.NewLoanHandler(memStore, nil)
+	loanHandler.UploadDir = t.TempDir()
+	routes.SetupRoutes(r, routes.Handlers{Loan: loanHandler})
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Nanda"})
+
+	requests := metrics.RequestsTotal.WithLabelValues(http.MethodGet, "/loan-applications/:id", "200")
+	missing := metrics.RequestsTotal.WithLabelValues(http.MethodGet, "unmatched", "404")
+	invalidToken := metrics.AuthFailures.WithLabelValues(metrics.AuthInvalidToken)
+	missingToken := metrics.AuthFailures.WithLabelValues(metrics.AuthMissingToken)
+	uploads := metrics.UploadBytes.WithLabelValues(metrics.UploadDocument)
+	before := []float64{testutil.ToFloat64(requests), testutil.ToFloat64(missing), testutil.ToFloat64(invalidToken), testutil.ToFloat64(missingToken), testutil.ToFloat64(uploads)}
+
+	// Test Case 1: Requests are labelled by route template, not path
+	doJSON(r, http.MethodGet, "/loan-applications/1", nil)
+	doJSON(r, http.MethodGet, "/loan-applications/1", nil)
+	doJSON(r, http.MethodGet, "/no-such-route/42", nil)
+	assert.Equal(t, before[0]+2, testutil.ToFloat64(requests))
+	assert.Equal(t, before[1]+1, testutil.ToFloat64(missing))
+	assert.Zero(t, testutil.ToFloat64(metrics.RequestsInFlight.WithLabelValues(http.MethodGet, "/loan-applications/:id")))
+
+	// Test Case 2: Authentication failures by reason
+	doAs(r, "not-a-token", http.MethodGet, "/loan-applications", "")
+	doAs(r, "", http.MethodGet, "/loan-applications", "")
+	assert.Equal(t, before[2]+1, testutil.ToFloat64(invalidToken))
+	assert.Equal(t, before[3]+1, testutil.ToFloat64(missingToken))
+
+	// Test Case 3: Uploaded bytes
+	uploadDocument(r, "1", "payslip.pdf", "")
+	assert.Equal(t, before[4]+float64(len("%PDF-1.4")), testutil.ToFloat64(uploads))
+}
+
+func TestStoreMetricsCollector(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Nanda"})
+	approved := memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Budi"})
+	memStore.UpdateLoanApplicationStatus(approved.ID, model.StatusApproved)
+	memStore.ForTenant("acme").SaveLoanApplication(model.LoanApplication{ApplicantName: "Eka"})
+
+	calendar, _ := sla.NewCalendar(time.UTC, nil)
+	sweeper := sla.NewSweeper(memStore, nil, calendar)
+	sweeper.Now = func() time.Time { return time.Now().AddDate(0, 0, 30) }
+	sweeper.Sweep()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics.NewCollector(memStore, sweeper))
+
+	// Test Case 1: Applications by tenant and status, including empty statuses
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	applications := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "loan_api_applications" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			applications[labels["tenant"]+"/"+labels["status"]] = metric.GetGauge().GetValue()
+		}
+	}
+	assert.Len(t, applications, 2*len(model.Statuses))
+	assert.Equal(t, 1.0, applications["default/pending"])
+	assert.Equal(t, 1.0, applications["default/approved"])
+	assert.Equal(t, 0.0, applications["default/rejected"])
+	assert.Equal(t, 1.0, applications["acme/pending"])
+
+	// Test Case 2: SLA stats of each tenant from the last sweep
+	expected := `
+		# HELP loan_api_sla_applications Applications at risk or in breach of their SLA at the last sweep
+		# TYPE loan_api_sla_applications gauge
+		loan_api_sla_applications{state="at_risk",tenant="acme"} 0
+		loan_api_sla_applications{state="breached",tenant="acme"} 1
+		loan_api_sla_applications{state="at_risk",tenant="default"} 0
+		loan_api_sla_applications{state="breached",tenant="default"} 1
+	`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "loan_api_sla_applications"))
+}
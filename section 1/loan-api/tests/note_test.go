@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/handler"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/store"
+)
+
+func setupNoteRouter() (*gin.Engine, *store.MemoryStore) {
+	r := gin.New()
+	memStore := store.NewMemoryStore()
+	loanHandler := handler.NewLoanHandler(memStore, nil)
+	routes.SetupRoutes(r, routes.Handlers{
+		Loan: loanHandler,
+		Note: handler.NewNoteHandler(loanHandler, store.NewNoteStore()),
+	})
+
+	middleware.RegisterToken("note-officer", model.Principal{Subject: "budi", Role: model.RoleOfficer})
+	middleware.RegisterToken("note-officer-sari", model.Principal{Subject: "sari", Role: model.RoleOfficer})
+	middleware.RegisterToken("note-applicant", model.Principal{Subject: "dewi", Role: model.RoleApplicant})
+	middleware.RegisterToken("note-other-applicant", model.Principal{Subject: "eka", Role: model.RoleApplicant})
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Dewi", SubmittedBy: "dewi"})
+	return r, memStore
+}
+
+func decodeNotes(t *testing.T, body []byte) []model.Note {
+	var notes []model.Note
+	assert.NoError(t, json.Unmarshal(body, &notes))
+	return notes
+}
+
+func TestLoanApplicationNotes(t *testing.T) {
+	router, _ := setupNoteRouter()
+
+	// Test Case 1: Staff notes default to internal and record mentions
+	w := doAs(router, "note-officer", http.MethodPost, "/loan-applications/1/notes", `{"body":"Income looks low, @sari can you check? cc @sari"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var internal model.Note
+	json.Unmarshal(w.Body.Bytes(), &internal)
+	assert.Equal(t, model.NoteInternal, internal.Visibility)
+	assert.Equal(t, "budi", internal.Author)
+	assert.Equal(t, []string{"sari"}, internal.Mentions)
+
+	// Test Case 2: Applicant-visible notes and replies
+	w = doAs(router, "note-officer", http.MethodPost, "/loan-applications/1/notes", `{"body":"Please upload a payslip","visibility":"applicant"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var visible model.Note
+	json.Unmarshal(w.Body.Bytes(), &visible)
+
+	w = doAs(router, "note-applicant", http.MethodPost, "/loan-applications/1/notes", fmt.Sprintf(`{"body":"Uploaded","parent_id":%d}`, visible.ID))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var reply model.Note
+	json.Unmarshal(w.Body.Bytes(), &reply)
+	assert.Equal(t, model.NoteApplicant, reply.Visibility)
+	assert.Equal(t, visible.ID, *reply.ParentID)
+
+	w = doAs(router, "note-officer", http.MethodPost, "/loan-applications/1/notes", fmt.Sprintf(`{"body":"Checked","parent_id":%d}`, internal.ID))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = doAs(router, "note-officer", http.MethodPost, "/loan-applications/1/notes", fmt.Sprintf(`{"body":"Leak","visibility":"applicant","parent_id":%d}`, internal.ID))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Test Case 3: Staff see every thread
+	w = doAs(router, "note-officer", http.MethodGet, "/loan-applications/1/notes", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	threads := decodeNotes(t, w.Body.Bytes())
+	assert.Len(t, threads, 2)
+	assert.Len(t, threads[0].Replies, 1)
+	assert.Len(t, threads[1].Replies, 1)
+
+	// Test Case 4: Applicants never see internal notes
+	w = doAs(router, "note-applicant", http.MethodGet, "/loan-applications/1/notes", "")
+	threads = decodeNotes(t, w.Body.Bytes())
+	assert.Len(t, threads, 1)
+	assert.Equal(t, "Please upload a payslip", threads[0].Body)
+	assert.NotContains(t, w.Body.String(), "Income looks low")
+
+	w = doAs(router, "note-applicant", http.MethodPost, "/loan-applications/1/notes", `{"body":"Secret","visibility":"internal"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = doAs(router, "note-applicant", http.MethodPost, "/loan-applications/1/notes", fmt.Sprintf(`{"body":"Hi","parent_id":%d}`, internal.ID))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doAs(router, "note-other-applicant", http.MethodGet, "/loan-applications/1/notes", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Test Case 5: Validation
+	w = doAs(router, "note-officer", http.MethodPost, "/loan-applications/1/notes", `{"visibility":"public"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAs(router, "note-officer", http.MethodPost, "/loan-applications/99/notes", `{"body":"Hello"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestEditLoanApplicationNote(t *testing.T) {
+	router, _ := setupNoteRouter()
+	doAs(router, "note-officer", http.MethodPost, "/loan-applications/1/notes", `{"body":"Draft: income unverified"}`)
+
+	// Test Case 1: Only the author can edit
+	w := doAs(router, "note-officer-sari", http.MethodPatch, "/loan-applications/1/notes/1", `{"body":"Changed"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Test Case 2: Edits keep the previous version and update mentions
+	w = doAs(router, "note-officer", http.MethodPatch, "/loan-applications/1/notes/1", `{"body":"Income verified by @sari","visibility":"applicant"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var note model.Note
+	json.Unmarshal(w.Body.Bytes(), &note)
+	assert.Equal(t, model.NoteApplicant, note.Visibility)
+	assert.NotNil(t, note.UpdatedAt)
+	assert.Len(t, note.History, 1)
+	assert.Equal(t, "Draft: income unverified", note.History[0].Body)
+	assert.Equal(t, model.NoteInternal, note.History[0].Visibility)
+	assert.Equal(t, "budi", note.History[0].EditedBy)
+
+	w = doAs(router, "note-officer-sari", http.MethodGet, "/me/mentions", "")
+	assert.Len(t, decodeNotes(t, w.Body.Bytes()), 1)
+
+	// Test Case 3: Applicants see the note but not its history
+	w = doAs(router, "note-applicant", http.MethodGet, "/loan-applications/1/notes", "")
+	threads := decodeNotes(t, w.Body.Bytes())
+	assert.Len(t, threads, 1)
+	assert.Empty(t, threads[0].History)
+	assert.NotContains(t, w.Body.String(), "unverified")
+
+	// Test Case 4: Unknown notes
+	w = doAs(router, "note-officer", http.MethodPatch, "/loan-applications/1/notes/42", `{"body":"Hello"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doAs(router, "note-applicant", http.MethodGet, "/me/mentions", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestParseMentions(t *testing.T) {
+	assert.Equal(t, []string{"budi", "sari.w"}, model.ParseMentions("@budi and @sari.w. Email ops@example.com, @budi"))
+	assert.Nil(t, model.ParseMentions("no mentions here"))
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/apperror"
+)
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) apperror.Problem {
+	assert.Equal(t, apperror.ContentType, w.Header().Get("Content-Type"))
+	var problem apperror.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	return problem
+}
+
+func TestProblemResponses(t *testing.T) {
+	router, _ := setupRouter()
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	// Test Case 1: Problem document members and request ID propagation
+	req, _ := http.NewRequest(http.MethodGet, "/loan-applications/999?verbose=1", nil)
+	req.Header.Set("Authorization", "Bearer mysecrettoken")
+	req.Header.Set("X-Request-ID", "req-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "req-123", w.Header().Get("X-Request-ID"))
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "/problems/application_not_found", problem.Type)
+	assert.Equal(t, "application_not_found", problem.Code)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "/loan-applications/999?verbose=1", problem.Instance)
+	assert.Equal(t, "req-123", problem.RequestID)
+
+	// Test Case 2: Unauthorized requests get a generated request ID
+	req, _ = http.NewRequest(http.MethodGet, "/loan-applications", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	problem = decodeProblem(t, w)
+	assert.Equal(t, "unauthorized", problem.Code)
+	assert.NotEmpty(t, problem.RequestID)
+	assert.Equal(t, w.Header().Get("X-Request-ID"), problem.RequestID)
+
+	// Test Case 3: Unknown routes and methods
+	req, _ = http.NewRequest(http.MethodGet, "/does-not-exist", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "route_not_found", decodeProblem(t, w).Code)
+
+	req, _ = http.NewRequest(http.MethodDelete, "/loan-applications", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "method_not_allowed", decodeProblem(t, w).Code)
+
+	// Test Case 4: Panics are recovered as internal errors without leaking the cause
+	req, _ = http.NewRequest(http.MethodGet, "/panic", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	problem = decodeProblem(t, w)
+	assert.Equal(t, "internal_error", problem.Code)
+	assert.NotContains(t, w.Body.String(), "boom")
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/handler"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/report"
+	"loan-api/routes"
+	"loan-api/store"
+)
+
+func decided(submitted time.Time, status string, amount float64, purpose string, after time.Duration) model.LoanApplication {
+	processed := submitted.Add(after)
+	return model.LoanApplication{Status: status, LoanAmount: amount, LoanPurpose: purpose, SubmittedAt: submitted, ProcessedAt: &processed}
+}
+
+func TestPipelineReport(t *testing.T) {
+	monday := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	// Test Case 1: Periods start on the day, ISO week or month
+	sunday := time.Date(2024, 3, 10, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, monday.Truncate(24*time.Hour), report.PeriodStart(sunday, model.GroupByWeek))
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), report.PeriodStart(sunday, model.GroupByMonth))
+	assert.Equal(t, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), report.PeriodStart(sunday, model.GroupByDay))
+
+	// Test Case 2: Percentiles interpolate between ranks
+	assert.Equal(t, 2.5, report.Percentile([]float64{1, 2, 3, 4}, 50))
+	assert.InDelta(t, 9.1, report.Percentile([]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 90), 0.0001)
+
+	// Test Case 3: Rates, decision times, amounts and grouping
+	pipeline := report.NewPipeline(model.GroupByWeek)
+	pipeline.Add(decided(monday, "approved", 10000, "Education", 10*time.Hour))
+	pipeline.Add(decided(monday, "approved", 20000, "education", 20*time.Hour))
+	pipeline.Add(decided(monday, "rejected", 5000, "Car Purchase", 30*time.Hour))
+	pipeline.Add(decided(monday, "rejected", 5000, "Car Purchase", 40*time.Hour))
+	pipeline.Add(model.LoanApplication{Status: "pending", LoanAmount: 1000, LoanPurpose: "Medical", SubmittedAt: monday.AddDate(0, 0, 7)})
+
+	result := pipeline.Report()
+	assert.Equal(t, 5, result.Summary.Total)
+	assert.Equal(t, 2, result.Summary.ByStatus["approved"])
+	assert.Equal(t, 0.5, result.Summary.ApprovalRate)
+	assert.Equal(t, 0.5, result.Summary.RejectionRate)
+	assert.Equal(t, 25.0, *result.Summary.MedianDecisionHours)
+	assert.Equal(t, 37.0, *result.Summary.P90DecisionHours)
+	assert.Equal(t, map[string]float64{"USD": 41000}, result.Summary.TotalRequested)
+	assert.Equal(t, map[string]float64{"USD": 30000}, result.Summary.TotalApproved)
+	assert.Equal(t, model.PurposeStats{Count: 2, Amount: map[string]float64{"USD": 30000}}, result.Summary.ByPurpose["education"])
+
+	assert.Len(t, result.Periods, 2)
+	assert.Equal(t, "2024-03-04", result.Periods[0].Start)
+	assert.Equal(t, 4, result.Periods[0].Total)
+	assert.Equal(t, "2024-03-11", result.Periods[1].Start)
+	assert.Nil(t, result.Periods[1].MedianDecisionHours)
+}
+
+func TestPipelineReportEndpoint(t *testing.T) {
+	r := gin.New()
+	memStore := store.NewMemoryStore()
+	routes.SetupRoutes(r, routes.Handlers{
+		Loan:   handler.NewLoanHandler(memStore, nil),
+		Report: handler.NewReportHandler(memStore),
+	})
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Nanda", LoanAmount: 50000, LoanPurpose: "Home Renovation"})
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Budi", LoanAmount: 20000, LoanPurpose: "Education"})
+	memStore.UpdateLoanApplicationStatus(2, "approved")
+
+	// Test Case 1: Filter by purpose
+	w := doJSON(r, http.MethodGet, "/reports/pipeline?group_by=day&loan_purpose=Education", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var result model.PipelineReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, "day", result.GroupBy)
+	assert.Equal(t, "education", result.LoanPurpose)
+	assert.Equal(t, 1, result.Summary.Total)
+	assert.Equal(t, 1.0, result.Summary.ApprovalRate)
+	assert.Len(t, result.Periods, 1)
+
+	// Test Case 2: Invalid grouping
+	w = doJSON(r, http.MethodGet, "/reports/pipeline?group_by=year", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Test Case 3: Applicants cannot read reports
+	middleware.RegisterToken("report-applicant", model.Principal{Subject: "dewi", Role: model.RoleApplicant})
+	req, _ := http.NewRequest(http.MethodGet, "/reports/pipeline", nil)
+	req.Header.Set("Authorization", "Bearer report-applicant")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Test Case 4: v2 totals are decimal strings per currency
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Eka", LoanAmount: 150000000, LoanPurpose: "Education", Country: model.CountryID})
+	memStore.UpdateLoanApplicationStatus(3, "approved")
+	w = doJSON(r, http.MethodGet, "/v2/reports/pipeline?loan_purpose=Education", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var v2 struct {
+		Summary map[string]json.RawMessage `json:"summary"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &v2))
+	assert.JSONEq(t, `[{"amount": "150000000.00", "currency": "IDR"}, {"amount": "20000.00", "currency": "USD"}]`, string(v2.Summary["total_approved"]))
+	assert.JSONEq(t, `[{"amount": "150000000.00", "currency": "IDR"}, {"amount": "20000.00", "currency": "USD"}]`, string(v2.Summary["total_requested"]))
+	assert.JSONEq(t, `{"education": {"count": 2, "amount": [{"amount": "150000000.00", "currency": "IDR"}, {"amount": "20000.00", "currency": "USD"}]}}`, string(v2.Summary["by_purpose"]))
+	w = doJSON(r, http.MethodGet, "/v1/reports/pipeline?loan_purpose=Education", nil)
+	assert.Contains(t, w.Body.String(), `"total_approved":{"IDR":150000000,"USD":20000}`)
+}
@@ -0,0 +1,285 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/events"
+	"loan-api/handler"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/retention"
+	"loan-api/routes"
+	"loan-api/store"
+	"loan-api/webhook"
+)
+
+type retentionFixture struct {
+	router    *gin.Engine
+	memStore  *store.MemoryStore
+	notes     *store.NoteStore
+	audit     *store.AuditStore
+	stream    *events.Stream
+	purger    *retention.Purger
+	uploadDir string
+}
+
+func setupRetention(t *testing.T) retentionFixture {
+	f := retentionFixture{
+		router:    gin.New(),
+		memStore:  store.NewMemoryStore(),
+		notes:     store.NewNoteStore(),
+		audit:     store.NewAuditStore(),
+		stream:    events.NewStream(100),
+		uploadDir: t.TempDir(),
+	}
+	bus := events.NewBus()
+	bus.Subscribe(f.stream.HandleEvent)
+	f.purger = retention.NewPurger(f.memStore, f.notes, f.audit, bus)
+
+	loanHandler := handler.NewLoanHandler(f.memStore, bus)
+	loanHandler.UploadDir = f.uploadDir
+	routes.SetupRoutes(f.router, routes.Handlers{
+		Loan:      loanHandler,
+		Note:      handler.NewNoteHandler(loanHandler, f.notes),
+		Retention: handler.NewRetentionHandler(loanHandler, f.purger),
+	})
+
+	middleware.RegisterToken("retention-officer", model.Principal{Subject: "budi", Role: model.RoleOfficer})
+	f.memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Dewi", ApplicantSSN: "123-45-6789", LoanAmount: 50000, SubmittedBy: "dewi"})
+	f.memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Dewi", ApplicantSSN: "123-45-6789", LoanAmount: 20000})
+	f.memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Eka", ApplicantSSN: "987-65-4321", LoanAmount: 30000, SubmittedBy: "eka"})
+	return f
+}
+
+func uploadedFiles(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestSubjectErasure(t *testing.T) {
+	f := setupRetention(t)
+	uploadDocument(f.router, "1", "payslip.pdf", "pay_stub")
+	uploadDocument(f.router, "3", "payslip.pdf", "pay_stub")
+	doJSON(f.router, http.MethodPost, "/loan-applications/1/notes", map[string]string{"body": "Dewi called about her payslip"})
+	assert.Len(t, uploadedFiles(t, f.uploadDir), 2)
+
+	// Test Case 1: Only admins can erase and a subject or SSN is required
+	w := doAs(f.router, "retention-officer", http.MethodPost, "/erasure-requests", `{"subject":"dewi"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = doJSON(f.router, http.MethodPost, "/erasure-requests", map[string]string{"reason": "GDPR"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "required", decodeProblem(t, w).Errors[0].Code)
+
+	// Test Case 2: Legal holds block erasure
+	w = doJSON(f.router, http.MethodPut, "/loan-applications/2/legal-hold", map[string]string{"reason": "Litigation 2024-17"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var held model.LoanApplication
+	json.Unmarshal(w.Body.Bytes(), &held)
+	assert.Equal(t, "admin", held.LegalHold.PlacedBy)
+
+	w = doJSON(f.router, http.MethodPost, "/erasure-requests", map[string]string{"subject": "dewi", "applicant_ssn": "123-45-6789", "reason": "GDPR Art. 17"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var result model.ErasureResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	assert.Equal(t, []int{1}, result.Erased)
+	assert.Equal(t, []int{2}, result.Held)
+
+	// Test Case 3: Personal data, documents and notes are removed, aggregates kept
+	app, _ := f.memStore.GetLoanApplication(1)
+	assert.Equal(t, model.ErasedValue, app.ApplicantName)
+	assert.Empty(t, app.ApplicantSSN)
+	assert.Empty(t, app.SubmittedBy)
+	assert.Empty(t, app.Documents)
+	assert.NotNil(t, app.AnonymizedAt)
+	assert.Equal(t, 50000.0, app.LoanAmount)
+	assert.Len(t, uploadedFiles(t, f.uploadDir), 1)
+	assert.Empty(t, f.notes.ListNotes(1))
+
+	entries := f.audit.List(model.AuditApplicationAnonymized)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "1", entries[0].Details["application_id"])
+	assert.Equal(t, "GDPR Art. 17", entries[0].Details["reason"])
+
+	// Test Case 4: The event log no longer carries the applicant's data
+	backlog, _, _, cancel := f.stream.Subscribe(0)
+	cancel()
+	for _, event := range backlog {
+		if event.ApplicationID == 1 {
+			assert.NotEqual(t, "Dewi", event.Application.ApplicantName)
+			assert.Empty(t, event.Data["document"])
+		}
+	}
+	assert.Equal(t, model.EventApplicationAnonymized, backlog[len(backlog)-1].Type)
+
+	// Test Case 5: Lifting the hold allows erasure
+	w = doJSON(f.router, http.MethodDelete, "/loan-applications/2/legal-hold", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(f.router, http.MethodPost, "/erasure-requests", map[string]string{"applicant_ssn": "123-45-6789"})
+	json.Unmarshal(w.Body.Bytes(), &result)
+	assert.Equal(t, []int{2}, result.Erased)
+	assert.Empty(t, result.Held)
+	assert.Len(t, f.audit.List(model.AuditLegalHoldLifted), 1)
+}
+
+func TestRetentionPurge(t *testing.T) {
+	f := setupRetention(t)
+	uploadDocument(f.router, "1", "payslip.pdf", "")
+	f.memStore.UpdateLoanApplicationStatus(1, model.StatusRejected)
+	f.memStore.UpdateLoanApplicationStatus(2, model.StatusRejected)
+	f.memStore.UpdateLoanApplicationStatus(3, model.StatusApproved)
+	f.purger.Rules = []model.RetentionRule{
+		{Status: model.StatusRejected, AfterDays: 90, Action: model.RetentionAnonymize},
+		{Status: model.StatusRejected, AfterDays: 365, Action: model.RetentionDelete},
+		{Status: model.StatusApproved, AfterDays: 3650, Action: model.RetentionAnonymize},
+	}
+	f.memStore.SetLegalHold(2, &model.LegalHold{Reason: "Dispute"})
+	now := time.Now()
+
+	// Test Case 1: Nothing is due yet
+	f.purger.Now = func() time.Time { return now.AddDate(0, 0, 30) }
+	stats := f.purger.Purge()
+	assert.Zero(t, stats.AnonymizedTotal+stats.DeletedTotal)
+
+	// Test Case 2: Rejected applications are anonymized once, held ones skipped
+	f.purger.Now = func() time.Time { return now.AddDate(0, 0, 100) }
+	f.purger.Purge()
+	stats = f.purger.Purge()
+	assert.Equal(t, int64(1), stats.AnonymizedTotal)
+	assert.Equal(t, 1, stats.Held)
+	app, _ := f.memStore.GetLoanApplication(1)
+	assert.NotNil(t, app.AnonymizedAt)
+	assert.Empty(t, uploadedFiles(t, f.uploadDir))
+	app, _ = f.memStore.GetLoanApplication(3)
+	assert.Equal(t, "Eka", app.ApplicantName)
+
+	// Test Case 3: The longest expired rule wins
+	f.purger.Now = func() time.Time { return now.AddDate(0, 0, 400) }
+	stats = f.purger.Purge()
+	assert.Equal(t, int64(1), stats.DeletedTotal)
+	_, found := f.memStore.GetLoanApplication(1)
+	assert.False(t, found)
+	_, found = f.memStore.GetLoanApplication(2)
+	assert.True(t, found)
+	entries := f.audit.List(model.AuditApplicationDeleted)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, retention.System.Subject, entries[0].Actor)
+}
+
+func TestErasureRedactsWebhookDeliveries(t *testing.T) {
+	f := setupRetention(t)
+	webhookStore := store.NewWebhookStore()
+	webhookStore.SaveSubscription(model.WebhookSubscription{URL: "http://127.0.0.1:1/hook", Active: true})
+	dispatcher := webhook.NewDispatcher(webhookStore)
+	dispatcher.MaxAttempts = 1
+	f.purger.Events.Subscribe(dispatcher.HandleEvent)
+
+	uploadDocument(f.router, "3", "ktp-eka.pdf", "")
+	assert.Contains(t, webhookStore.ListDeliveries(1)[0].Payload, "Eka")
+
+	// Test Case 1: Recorded payloads lose the applicant's data
+	w := doJSON(f.router, http.MethodPost, "/erasure-requests", map[string]string{"subject": "eka"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	deliveries := webhookStore.ListDeliveries(1)
+	assert.Len(t, deliveries, 2)
+	for _, delivery := range deliveries {
+		assert.NotContains(t, delivery.Payload, "Eka")
+		assert.NotContains(t, delivery.Payload, "ktp-eka.pdf")
+	}
+}
+
+func TestErasureRedactsWebhookRetries(t *testing.T) {
+	f := setupRetention(t)
+	attempts := make(chan string, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		attempts <- string(body)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	webhookStore := store.NewWebhookStore()
+	webhookStore.SaveSubscription(model.WebhookSubscription{URL: receiver.URL, Events: []string{model.EventDocumentUploaded}, Active: true})
+	dispatcher := webhook.NewDispatcher(webhookStore)
+	dispatcher.BaseBackoff = 50 * time.Millisecond
+	dispatcher.MaxAttempts = 2
+	dispatcher.AllowPrivateTargets = true
+	f.purger.Events.Subscribe(dispatcher.HandleEvent)
+
+	// Test Case 1: A retry after an erasure sends the redacted payload
+	uploadDocument(f.router, "3", "ktp-eka.pdf", "")
+	assert.Contains(t, <-attempts, "Eka")
+	w := doJSON(f.router, http.MethodPost, "/erasure-requests", map[string]string{"subject": "eka"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	retry := <-attempts
+	assert.NotContains(t, retry, "Eka")
+	assert.NotContains(t, retry, "ktp-eka.pdf")
+}
+
+func TestErasureBySSN(t *testing.T) {
+	f := setupRetention(t)
+	f.memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Dewi", ApplicantSSN: "123456789", LoanAmount: 10000})
+
+	// Test Case 1: The SSN matches however it was formatted
+	w := doJSON(f.router, http.MethodPost, "/erasure-requests", map[string]string{"applicant_ssn": "123 45 6789"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var result model.ErasureResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	assert.Equal(t, []int{1, 2, 4}, result.Erased)
+	assert.Empty(t, result.Held)
+	assert.Empty(t, result.Failed)
+
+	// Test Case 2: Subject and SSN matches are erased once
+	f = setupRetention(t)
+	w = doJSON(f.router, http.MethodPost, "/erasure-requests", map[string]string{"subject": "dewi", "applicant_ssn": "123-45-6789"})
+	json.Unmarshal(w.Body.Bytes(), &result)
+	assert.Equal(t, []int{1, 2}, result.Erased)
+	app, _ := f.memStore.GetLoanApplication(3)
+	assert.Equal(t, "Eka", app.ApplicantName)
+}
+
+func TestRetentionFieldsFromClients(t *testing.T) {
+	f := setupRetention(t)
+	middleware.RegisterToken("retention-applicant", model.Principal{Subject: "dewi", Role: model.RoleApplicant})
+	retentionFields := `"legal_hold": {"reason": "Litigation", "placed_by": "dewi"}, "anonymized_at": "2026-03-01T00:00:00Z"`
+	body := `{"applicant_name": "Dewi", "applicant_ssn": "123-45-6789", "loan_amount": 20000, "loan_purpose": "Medical",
+		"annual_income": 75000, "credit_score": 720, ` + retentionFields + `}`
+
+	// Test Case 1: Submissions cannot place a legal hold or skip masking
+	w := doAs(f.router, "retention-applicant", http.MethodPost, "/loan-applications", body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var app model.LoanApplication
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &app))
+	assert.Nil(t, app.LegalHold)
+	assert.Nil(t, app.AnonymizedAt)
+	assert.Equal(t, "XXX-XX-6789", app.ApplicantSSN)
+	w = doJSON(f.router, http.MethodPost, "/erasure-requests", map[string]string{"subject": "dewi"})
+	var result model.ErasureResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	assert.Equal(t, []int{1, app.ID}, result.Erased)
+	assert.Empty(t, result.Held)
+
+	// Test Case 2: Imported rows cannot set them either
+	router, memStore := setupImportRouter()
+	row := `{"applicant_name": "Eka", "applicant_ssn": "321-54-6789", "loan_amount": 30000, "loan_purpose": "Education", ` +
+		`"annual_income": 60000, "credit_score": 700, ` + retentionFields + `}`
+	w, job := doImport(router, "", "application/x-ndjson", row+"\n")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, job.Created)
+	imported, _ := memStore.GetLoanApplication(job.Rows[0].ApplicationID)
+	assert.Nil(t, imported.LegalHold)
+	assert.Nil(t, imported.AnonymizedAt)
+	assert.Equal(t, "321-54-6789", imported.ApplicantSSN)
+}
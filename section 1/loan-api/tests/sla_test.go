@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/events"
+	"loan-api/handler"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/sla"
+	"loan-api/store"
+)
+
+func TestBusinessDayCalendar(t *testing.T) {
+	calendar, err := sla.NewCalendar(time.UTC, []string{"2024-03-11"})
+	assert.NoError(t, err)
+
+	friday := time.Date(2024, 3, 8, 15, 0, 0, 0, time.UTC)
+	saturday := time.Date(2024, 3, 9, 10, 0, 0, 0, time.UTC)
+
+	// Test Case 1: Weekends and holidays are skipped
+	assert.False(t, calendar.IsBusinessDay(saturday))
+	assert.False(t, calendar.IsBusinessDay(time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2024, 3, 12, 15, 0, 0, 0, time.UTC), calendar.AddBusinessDays(friday, 1))
+	assert.Equal(t, time.Date(2024, 3, 14, 15, 0, 0, 0, time.UTC), calendar.AddBusinessDays(friday, 3))
+
+	// Test Case 2: The clock starts on the next business day
+	assert.Equal(t, time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC), calendar.AddBusinessDays(saturday, 1))
+
+	// Test Case 3: Invalid holidays are rejected
+	_, err = sla.NewCalendar(time.UTC, []string{"11/03/2024"})
+	assert.Error(t, err)
+}
+
+func TestSLASweeper(t *testing.T) {
+	memStore := store.NewMemoryStore()
+	bus := events.NewBus()
+	var published []model.Event
+	bus.Subscribe(func(event model.Event) { published = append(published, event) })
+
+	calendar, _ := sla.NewCalendar(time.UTC, nil)
+	sweeper := sla.NewSweeper(memStore, bus, calendar)
+	sweeper.Deadlines = map[string]int{model.StatusPending: 1}
+	sweeper.Warning = 2 * time.Hour
+
+	pending := memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Nanda"})
+	decidedApp := memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Budi"})
+	memStore.UpdateLoanApplicationStatus(decidedApp.ID, model.StatusApproved)
+	due := calendar.AddBusinessDays(pending.SubmittedAt, 1)
+
+	// Test Case 1: On track, no events
+	sweeper.Now = func() time.Time { return due.Add(-3 * time.Hour) }
+	stats := sweeper.Sweep()
+	app, _ := memStore.GetLoanApplication(pending.ID)
+	assert.Equal(t, model.SLAOnTrack, app.SLA.State)
+	assert.True(t, due.Equal(app.SLA.DueAt))
+	assert.Empty(t, published)
+	app, _ = memStore.GetLoanApplication(decidedApp.ID)
+	assert.Nil(t, app.SLA)
+
+	// Test Case 2: At risk inside the warning window, reported once
+	sweeper.Now = func() time.Time { return due.Add(-time.Hour) }
+	sweeper.Sweep()
+	stats = sweeper.Sweep()
+	assert.Equal(t, 1, stats.AtRisk)
+	assert.Len(t, published, 1)
+	assert.Equal(t, model.EventSLAAtRisk, published[0].Type)
+
+	// Test Case 3: Breached after the deadline
+	sweeper.Now = func() time.Time { return due.Add(time.Minute) }
+	stats = sweeper.Sweep()
+	assert.Equal(t, 1, stats.Breached)
+	assert.Equal(t, int64(1), stats.BreachedTotal)
+	assert.Len(t, published, 2)
+	assert.Equal(t, model.EventSLABreached, published[1].Type)
+	assert.Equal(t, model.SLABreached, published[1].Application.SLA.State)
+
+	// Test Case 4: Breached applications can be listed
+	r := gin.New()
+	routes.SetupRoutes(r, routes.Handlers{Loan: handler.NewLoanHandler(memStore, bus)})
+	w := doJSON(r, http.MethodGet, "/loan-applications?sla=breached", nil)
+	var apps []model.LoanApplication
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &apps))
+	assert.Len(t, apps, 1)
+	assert.Equal(t, pending.ID, apps[0].ID)
+	w = doJSON(r, http.MethodGet, "/loan-applications?sla=late", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Test Case 5: A status change clears the SLA and restarts the clock
+	memStore.UpdateLoanApplicationStatus(pending.ID, model.StatusUnderReview)
+	app, _ = memStore.GetLoanApplication(pending.ID)
+	assert.Nil(t, app.SLA)
+	stats = sweeper.Sweep()
+	assert.Equal(t, 0, stats.Breached)
+
+	// Test Case 6: Each tenant only sees its own stats
+	memStore.ForTenant("acme").SaveLoanApplication(model.LoanApplication{ApplicantName: "Eka"})
+	sweeper.Now = func() time.Time { return time.Now().AddDate(0, 0, 30) }
+	stats = sweeper.Sweep()
+	assert.Equal(t, 1, stats.Breached)
+	assert.Equal(t, 1, sweeper.Stats("acme").Breached)
+	assert.Equal(t, 0, sweeper.Stats("").Breached)
+	assert.Equal(t, int64(1), sweeper.Stats("").BreachedTotal)
+
+	reportHandler := handler.NewReportHandler(memStore)
+	reportHandler.SLA = sweeper
+	reports := gin.New()
+	routes.SetupRoutes(reports, routes.Handlers{Loan: handler.NewLoanHandler(memStore, bus), Report: reportHandler})
+	middleware.RegisterToken("sla-acme", model.Principal{Subject: "acme-admin", Role: model.RoleAdmin, Tenant: "acme"})
+	w = doAs(reports, "sla-acme", http.MethodGet, "/reports/sla", "")
+	var tenantStats sla.Stats
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &tenantStats))
+	assert.Equal(t, 1, tenantStats.Breached)
+	assert.Equal(t, int64(1), tenantStats.BreachedTotal)
+}
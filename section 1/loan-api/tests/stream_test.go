@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"loan-api/events"
+	"loan-api/handler"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/store"
+)
+
+func setupStreamServer() *httptest.Server {
+	r := gin.New()
+	bus := events.NewBus()
+	stream := events.NewStream(100)
+	bus.Subscribe(stream.HandleEvent)
+
+	routes.SetupRoutes(r, routes.Handlers{
+		Loan:   handler.NewLoanHandler(store.NewMemoryStore(), bus),
+		Stream: handler.NewStreamHandler(stream),
+	})
+	return httptest.NewServer(r)
+}
+
+func apiRequest(t *testing.T, server *httptest.Server, method, path, token string, body interface{}) *http.Response {
+	jsonBody, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, server.URL+path, bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	return resp
+}
+
+func readSSEEvent(t *testing.T, reader *bufio.Reader) (string, model.Event) {
+	var id string
+	var event model.Event
+	for {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return id, event
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+		case line == "" && id != "":
+			return id, event
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	server := setupStreamServer()
+	defer server.Close()
+
+	middleware.RegisterToken("alicetoken", model.Principal{Subject: "alice", Role: model.RoleApplicant})
+
+	newApp := model.LoanApplication{
+		ApplicantName: "Alice",
+		ApplicantSSN:  "123-45-6789",
+		LoanAmount:    50000.0,
+		LoanPurpose:   "Home Renovation",
+		AnnualIncome:  75000.0,
+		CreditScore:   720,
+	}
+	// Application 1 belongs to alice, application 2 to the admin.
+	assert.Equal(t, http.StatusCreated, apiRequest(t, server, http.MethodPost, "/loan-applications", "alicetoken", newApp).StatusCode)
+	assert.Equal(t, http.StatusCreated, apiRequest(t, server, http.MethodPost, "/loan-applications", "mysecrettoken", newApp).StatusCode)
+
+	// Test Case 1: Applicants only receive events for their own applications
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events/stream", nil)
+	req.Header.Set("Authorization", "Bearer alicetoken")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+
+	id, event := readSSEEvent(t, reader)
+	assert.Equal(t, "1", id)
+	assert.Equal(t, model.EventApplicationSubmitted, event.Type)
+	assert.Equal(t, "XXX-XX-6789", event.Application.ApplicantSSN)
+
+	apiRequest(t, server, http.MethodPut, "/loan-applications/2/status", "mysecrettoken", map[string]string{"status": "approved"})
+	apiRequest(t, server, http.MethodPut, "/loan-applications/1/status", "mysecrettoken", map[string]string{"status": "under_review"})
+
+	id, event = readSSEEvent(t, reader)
+	assert.Equal(t, "4", id)
+	assert.Equal(t, model.EventApplicationStatusChanged, event.Type)
+	assert.Equal(t, 1, event.ApplicationID)
+	assert.Equal(t, "pending", event.Data["previous_status"])
+	assert.NotContains(t, event.Data, "changed_by")
+
+	// Test Case 2: Resume from Last-Event-ID over SSE
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel2()
+	req, _ = http.NewRequestWithContext(ctx2, http.MethodGet, server.URL+"/events/stream", nil)
+	req.Header.Set("Authorization", "Bearer mysecrettoken")
+	req.Header.Set("Last-Event-ID", "2")
+	resp2, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp2.Body.Close()
+	id, _ = readSSEEvent(t, bufio.NewReader(resp2.Body))
+	assert.Equal(t, "3", id)
+
+	// Test Case 3: WebSocket receives the same events
+	header := http.Header{}
+	header.Set("Authorization", "Bearer mysecrettoken")
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/events/ws?last_event_id=3&types=" + model.EventApplicationStatusChanged
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	assert.NoError(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	assert.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, int64(4), event.ID)
+
+	// Test Case 4: Invalid Last-Event-ID
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/events/stream", nil)
+	req.Header.Set("Authorization", "Bearer mysecrettoken")
+	req.Header.Set("Last-Event-ID", "abc")
+	resp3, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp3.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp3.StatusCode)
+
+	// Test Case 5: v2 streams carry the v2 application shape
+	ctx4, cancel4 := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel4()
+	req, _ = http.NewRequestWithContext(ctx4, http.MethodGet, server.URL+"/v2/events/stream", nil)
+	req.Header.Set("Authorization", "Bearer alicetoken")
+	resp4, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp4.Body.Close()
+	var v2Event struct {
+		Application map[string]any `json:"application"`
+	}
+	v2Reader := bufio.NewReader(resp4.Body)
+	for {
+		line, err := v2Reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			break
+		}
+		if data, found := strings.CutPrefix(strings.TrimRight(line, "\n"), "data: "); found {
+			assert.NoError(t, json.Unmarshal([]byte(data), &v2Event))
+			break
+		}
+	}
+	assert.Equal(t, map[string]any{"amount": "50000.00", "currency": "USD"}, v2Event.Application["loan_amount"])
+
+	v2Event.Application = nil
+	wsURL = "ws" + strings.TrimPrefix(server.URL, "http") + "/v2/events/ws?types=" + model.EventApplicationSubmitted
+	conn2, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	assert.NoError(t, err)
+	defer conn2.Close()
+	conn2.SetReadDeadline(time.Now().Add(5 * time.Second))
+	assert.NoError(t, conn2.ReadJSON(&v2Event))
+	assert.Equal(t, map[string]any{"amount": "50000.00", "currency": "USD"}, v2Event.Application["loan_amount"])
+}
+
+func TestEventOrder(t *testing.T) {
+	bus := events.NewBus()
+	stream := events.NewStream(3)
+	bus.Subscribe(stream.HandleEvent)
+	var delivered []int64
+	bus.Subscribe(func(event model.Event) {
+		delivered = append(delivered, event.ID)
+		// Handlers may publish, as auto-assignment does on submission.
+		if event.Type == model.EventApplicationSubmitted {
+			bus.Publish(model.EventApplicationAssigned, event.Application, nil)
+		}
+	})
+
+	// Test Case 1: Concurrent and nested events are delivered in ID order
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bus.Publish(model.EventApplicationSubmitted, model.LoanApplication{ID: i + 1}, nil)
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, delivered, 100)
+	for i, id := range delivered {
+		assert.Equal(t, int64(i+1), id)
+	}
+
+	// Test Case 2: Resuming from an event dropped from the log reports a gap
+	backlog, gap, _, cancel := stream.Subscribe(96)
+	cancel()
+	assert.Len(t, backlog, 3)
+	if assert.NotNil(t, gap) {
+		assert.Equal(t, events.Gap{Type: events.GapEventType, LastEventID: 96, OldestEventID: 98}, *gap)
+	}
+	backlog, gap, _, cancel = stream.Subscribe(97)
+	cancel()
+	assert.Len(t, backlog, 3)
+	assert.Nil(t, gap)
+}
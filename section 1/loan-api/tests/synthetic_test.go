@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"loan-api/model"
+	"loan-api/synthetic"
+	"loan-api/validator"
+)
+
+func TestSyntheticApplications(t *testing.T) {
+	router, memStore := setupRouter()
+	gen := synthetic.NewGenerator(42)
+
+	// Test Case 1: Generated applications pass the API's validation
+	countries := map[string]int{}
+	for range 200 {
+		app := gen.Application()
+		w := doJSON(router, http.MethodPost, "/v1/loan-applications", app)
+		if !assert.Equal(t, http.StatusCreated, w.Code, w.Body.String()) {
+			break
+		}
+		countries[app.Country]++
+		if app.Country == model.CountryID {
+			assert.True(t, validator.IsNIK(app.ApplicantSSN))
+		} else {
+			assert.True(t, validator.IsUSSSN(app.ApplicantSSN))
+		}
+	}
+	assert.Len(t, memStore.ListLoanApplications(), 200)
+	assert.Greater(t, countries[model.CountryID], 20)
+	assert.Greater(t, countries[model.CountryUS], 100)
+
+	// Test Case 2: The same seed generates the same applications
+	a, b := synthetic.NewGenerator(7), synthetic.NewGenerator(7)
+	for range 10 {
+		assert.Equal(t, a.Application(), b.Application())
+	}
+
+	// Test Case 3: Higher incomes come with higher credit scores and amounts
+	gen = synthetic.NewGenerator(1)
+	gen.IDShare = 0
+	var low, high []model.LoanApplication
+	for range 2000 {
+		app := gen.Application()
+		switch {
+		case app.AnnualIncome < 40000:
+			low = append(low, app)
+		case app.AnnualIncome > 100000:
+			high = append(high, app)
+		}
+	}
+	mean := func(apps []model.LoanApplication, field func(model.LoanApplication) float64) float64 {
+		sum := 0.0
+		for _, app := range apps {
+			sum += field(app)
+		}
+		return sum / float64(len(apps))
+	}
+	score := func(app model.LoanApplication) float64 { return float64(app.CreditScore) }
+	amount := func(app model.LoanApplication) float64 { return app.LoanAmount }
+	assert.Greater(t, mean(high, score), mean(low, score)+30)
+	assert.Greater(t, mean(high, amount), 2*mean(low, amount))
+
+	// Test Case 4: Indonesian incomes and amounts are in rupiah
+	gen = synthetic.NewGenerator(1)
+	gen.IDShare = 1
+	limits := model.LoanAmountLimits(model.CountryID)
+	var incomes, amounts []float64
+	for range 200 {
+		app := gen.Application()
+		assert.Equal(t, model.CountryID, app.Country)
+		assert.GreaterOrEqual(t, app.LoanAmount, limits.Min)
+		assert.LessOrEqual(t, app.LoanAmount, limits.Max)
+		incomes = append(incomes, app.AnnualIncome)
+		amounts = append(amounts, app.LoanAmount)
+	}
+	slices.Sort(incomes)
+	assert.Greater(t, incomes[len(incomes)/2], 30000000.0)
+	slices.Sort(amounts)
+	assert.Greater(t, len(slices.Compact(amounts)), 100, "amounts must follow income rather than sit at a limit")
+}
+
+func TestLoanAmountLimits(t *testing.T) {
+	router, _ := setupRouter()
+	application := func(country, ssn string, amount float64) map[string]interface{} {
+		return map[string]interface{}{
+			"applicant_name": "Nanda Putri", "applicant_ssn": ssn, "country": country, "loan_amount": amount,
+			"loan_purpose": "Home Renovation", "annual_income": 75000, "credit_score": 720,
+		}
+	}
+
+	// Test Case 1: Limits depend on the country's currency
+	w := doJSON(router, http.MethodPost, "/v1/loan-applications", application("ID", "3201014501900001", 250000000))
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = doJSON(router, http.MethodPost, "/v1/loan-applications", application("US", "123-45-6789", 250000000))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []model.FieldError{{Field: "loan_amount", Code: "too_large", Message: "loan_amount must be 1,000,000 or less"}}, decodeProblem(t, w).Errors)
+
+	// Test Case 2: Amounts below the country's minimum are too small
+	w = doJSON(router, http.MethodPost, "/v1/loan-applications", application("ID", "3201014501900001", 50000))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "too_small", decodeProblem(t, w).Errors[0].Code)
+}
+
+func TestSyntheticDocuments(t *testing.T) {
+	gen := synthetic.NewGenerator(42)
+	app := model.LoanApplication{ApplicantName: "Nanda (Jr.)", LoanPurpose: "Home Renovation", AnnualIncome: 75000}
+
+	// Test Case 1: Documents match the purpose's checklist and are PDFs
+	doc := gen.Document(app)
+	assert.Equal(t, "contractor_quote", doc.Type)
+	assert.Regexp(t, `^contractor_quote_\d+\.pdf$`, doc.Filename)
+	assert.Equal(t, "application/pdf", http.DetectContentType(doc.Content))
+	assert.True(t, bytes.HasSuffix(doc.Content, []byte("%%EOF\n")))
+	assert.Contains(t, string(doc.Content), `(Name: Nanda \(Jr.\)) Tj`)
+
+	// Test Case 2: The cross-reference table points at the objects
+	pdf := synthetic.PDF("hello")
+	start := bytes.Index(pdf, []byte("3 0 obj"))
+	assert.Contains(t, string(pdf), "xref\n0 6\n")
+	assert.Contains(t, string(pdf), fmt.Sprintf("\n%010d 00000 n \n", start))
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/events"
+	"loan-api/handler"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/store"
+)
+
+const tenantApplication = `{"applicant_name":"Nanda","applicant_ssn":"123-45-6789","loan_amount":%s,"loan_purpose":"Home Renovation","annual_income":75000,"credit_score":720}`
+
+func setupTenantRouter(t *testing.T) (*gin.Engine, *[]model.Event) {
+	r := gin.New()
+	memStore := store.NewMemoryStore()
+	bus := events.NewBus()
+	var published []model.Event
+	bus.Subscribe(func(event model.Event) { published = append(published, event) })
+	loanHandler := handler.NewLoanHandler(memStore, bus)
+	loanHandler.UploadDir = t.TempDir()
+	loanHandler.Tenants = map[string]model.TenantSettings{
+		"acme": {
+			MinLoanAmount: 5000,
+			MaxLoanAmount: 100000,
+			Statuses:      []string{model.StatusPending, model.StatusApproved, model.StatusRejected},
+			Checklists:    map[string][]string{model.PurposeHomeRenovation: {"contractor_quote"}},
+		},
+	}
+	routes.SetupRoutes(r, routes.Handlers{
+		Loan: loanHandler,
+		Note: handler.NewNoteHandler(loanHandler, store.NewNoteStore()),
+	})
+
+	middleware.RegisterToken("tenant-acme-admin", model.Principal{Subject: "ana", Role: model.RoleAdmin, Tenant: "acme"})
+	middleware.RegisterToken("tenant-globex-admin", model.Principal{Subject: "gita", Role: model.RoleAdmin, Tenant: "globex"})
+	return r, &published
+}
+
+func createTenantApplication(t *testing.T, router *gin.Engine, token, amount string) model.LoanApplication {
+	w := doAs(router, token, http.MethodPost, "/loan-applications", fmt.Sprintf(tenantApplication, amount))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var app model.LoanApplication
+	json.Unmarshal(w.Body.Bytes(), &app)
+	return app
+}
+
+func TestTenantIsolation(t *testing.T) {
+	router, published := setupTenantRouter(t)
+
+	// Test Case 1: Every tenant has its own ID sequence
+	acme := createTenantApplication(t, router, "tenant-acme-admin", "20000")
+	globex := createTenantApplication(t, router, "tenant-globex-admin", "20000")
+	assert.Equal(t, 1, acme.ID)
+	assert.Equal(t, "acme", acme.Tenant)
+	assert.Equal(t, 1, globex.ID)
+	assert.Equal(t, "globex", globex.Tenant)
+	createTenantApplication(t, router, "tenant-globex-admin", "30000")
+
+	// Test Case 2: Listings only hold the caller's tenant
+	w := doAs(router, "tenant-acme-admin", http.MethodGet, "/loan-applications", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "globex")
+	w = doJSON(router, http.MethodGet, "/loan-applications/1", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Test Case 3: Other tenants' applications are reported as missing
+	w = doAs(router, "tenant-acme-admin", http.MethodGet, "/loan-applications/2", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doAs(router, "tenant-acme-admin", http.MethodPost, "/loan-applications/1/notes", `{"body":"Looks fine"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = doAs(router, "tenant-globex-admin", http.MethodGet, "/loan-applications/1/notes", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]", w.Body.String())
+
+	// Test Case 4: Events carry the tenant
+	for _, event := range *published {
+		assert.Equal(t, event.Application.Tenant, event.Tenant)
+	}
+	assert.Equal(t, "acme", (*published)[len(*published)-1].Tenant)
+}
+
+func TestTenantSettings(t *testing.T) {
+	router, _ := setupTenantRouter(t)
+
+	// Test Case 1: Tenant amount limits narrow the defaults
+	w := doAs(router, "tenant-acme-admin", http.MethodPost, "/loan-applications", fmt.Sprintf(tenantApplication, "2000"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "too_small", decodeProblem(t, w).Errors[0].Code)
+	w = doAs(router, "tenant-acme-admin", http.MethodPost, "/loan-applications", fmt.Sprintf(tenantApplication, "200000"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "too_large", decodeProblem(t, w).Errors[0].Code)
+	createTenantApplication(t, router, "tenant-globex-admin", "200000")
+
+	// Test Case 2: Tenants without under_review cannot use it
+	createTenantApplication(t, router, "tenant-acme-admin", "20000")
+	w = doAs(router, "tenant-acme-admin", http.MethodPut, "/loan-applications/1/status", `{"status":"under_review"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAs(router, "tenant-globex-admin", http.MethodPut, "/loan-applications/1/status", `{"status":"under_review"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Test Case 3: Tenant checklists replace the default checklists
+	w = doAs(router, "tenant-acme-admin", http.MethodGet, "/loan-applications/1/checklist", "")
+	var checklist model.Checklist
+	json.Unmarshal(w.Body.Bytes(), &checklist)
+	assert.Len(t, checklist.Items, 1)
+	assert.Equal(t, "contractor_quote", checklist.Items[0].DocumentType)
+}
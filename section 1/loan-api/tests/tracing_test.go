@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"loan-api/handler"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/store"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	r := gin.New()
+	memStore := store.NewMemoryStore()
+	loanHandler := handler.NewLoanHandler(memStore, nil)
+	loanHandler.UploadDir = t.TempDir()
+	routes.SetupRoutes(r, routes.Handlers{Loan: loanHandler})
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Nanda"})
+
+	// Test Case 1: The incoming trace context is continued
+	req, _ := http.NewRequest(http.MethodGet, "/loan-applications/1", nil)
+	req.Header.Set("Authorization", "Bearer mysecrettoken")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	callSpan, serverSpan := spans[0], spans[1]
+	assert.Equal(t, "/loan-applications/:id", serverSpan.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", serverSpan.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", serverSpan.Parent().SpanID().String())
+
+	// Test Case 2: Calls into the store are children of the request span
+	assert.Equal(t, "store.call GetLoanApplication", callSpan.Name())
+	assert.Equal(t, serverSpan.SpanContext().SpanID(), callSpan.Parent().SpanID())
+	attributes := map[string]string{}
+	for _, attr := range callSpan.Attributes() {
+		attributes[string(attr.Key)] = attr.Value.Emit()
+	}
+	assert.Equal(t, "1", attributes["loan.application_id"])
+	assert.Equal(t, model.DefaultTenant, attributes["loan.tenant"])
+	assert.Equal(t, "GetLoanApplication", attributes["loan.store_operation"])
+
+	// Test Case 3: Requests without a trace context start a new trace
+	doJSON(r, http.MethodPost, "/loan-applications", map[string]interface{}{
+		"applicant_name": "Budi", "applicant_ssn": "123-45-6789", "loan_amount": 20000,
+		"loan_purpose": "Home Renovation", "annual_income": 75000, "credit_score": 720,
+	})
+	spans = recorder.Ended()[2:]
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "store.call SubmitLoanApplication", spans[0].Name())
+	assert.False(t, spans[1].Parent().IsValid())
+	assert.Equal(t, spans[1].SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+}
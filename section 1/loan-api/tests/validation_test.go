@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"loan-api/apperror"
+	"loan-api/model"
+	"loan-api/validator"
+)
+
+func submitWithLanguage(t *testing.T, body string, acceptLanguage string) (*httptest.ResponseRecorder, apperror.Problem) {
+	router, _ := setupRouter()
+	req, _ := http.NewRequest(http.MethodPost, "/loan-applications", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer mysecrettoken")
+	if acceptLanguage != "" {
+		req.Header.Set("Accept-Language", acceptLanguage)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var errResponse apperror.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResponse))
+	return w, errResponse
+}
+
+func TestLocalizedValidationErrors(t *testing.T) {
+	missingName := `{"applicant_ssn":"123-45-6789","loan_amount":500,"loan_purpose":"Education","annual_income":1000,"credit_score":700}`
+
+	// Test Case 1: English by default, keyed by JSON field name
+	w, errResponse := submitWithLanguage(t, missingName, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+	assert.Len(t, errResponse.Errors, 2)
+	assert.Equal(t, model.FieldError{Field: "applicant_name", Code: "required", Message: "applicant_name is a required field"}, errResponse.Errors[0])
+	assert.Equal(t, "loan_amount", errResponse.Errors[1].Field)
+	assert.Equal(t, "too_small", errResponse.Errors[1].Code)
+
+	// Test Case 2: Indonesian is picked from Accept-Language
+	w, errResponse = submitWithLanguage(t, missingName, "id-ID,id;q=0.9,en;q=0.8")
+	assert.Equal(t, "id", w.Header().Get("Content-Language"))
+	assert.Equal(t, "required", errResponse.Errors[0].Code)
+	assert.Equal(t, "applicant_name wajib diisi", errResponse.Errors[0].Message)
+
+	// Test Case 3: Unsupported languages fall back to English
+	w, _ = submitWithLanguage(t, missingName, "fr-FR")
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+
+	// Test Case 4: Custom SSN rule is translated too
+	badSSN := `{"applicant_name":"Budi","applicant_ssn":"12345678911","loan_amount":5000,"loan_purpose":"Education","annual_income":1000,"credit_score":700}`
+	_, errResponse = submitWithLanguage(t, badSSN, "id")
+	assert.Equal(t, "Invalid input", errResponse.Title)
+	assert.Equal(t, "applicant_ssn", errResponse.Errors[0].Field)
+	assert.Equal(t, "invalid_format", errResponse.Errors[0].Code)
+	assert.Equal(t, "applicant_ssn harus berupa Social Security Number AS yang valid dengan format XXX-XX-XXXX", errResponse.Errors[0].Message)
+
+	// Test Case 5: Type mismatches are reported per field
+	wrongType := `{"applicant_name":"Budi","applicant_ssn":"123-45-6789","loan_amount":"lots","loan_purpose":"Education","annual_income":1000,"credit_score":700}`
+	_, errResponse = submitWithLanguage(t, wrongType, "en")
+	assert.Equal(t, "loan_amount", errResponse.Errors[0].Field)
+	assert.Equal(t, "invalid_type", errResponse.Errors[0].Code)
+}
+
+func TestIdentityValidation(t *testing.T) {
+	// Test Case 1: US SSN allocation rules
+	assert.True(t, validator.IsUSSSN("123-45-6789"))
+	assert.False(t, validator.IsUSSSN("000-45-6789"))
+	assert.False(t, validator.IsUSSSN("666-45-6789"))
+	assert.False(t, validator.IsUSSSN("901-45-6789"))
+	assert.False(t, validator.IsUSSSN("123-00-6789"))
+	assert.False(t, validator.IsUSSSN("123-45-0000"))
+	assert.False(t, validator.IsUSSSN("123456789"))
+
+	// Test Case 2: NIK region and birth date
+	assert.True(t, validator.IsNIK("3174012501900001"))  // Jakarta, born 25-01-1990
+	assert.True(t, validator.IsNIK("3174016502900001"))  // women add 40 to the day
+	assert.True(t, validator.IsNIK("3174012902000001"))  // 29 February
+	assert.False(t, validator.IsNIK("9974012501900001")) // unknown province
+	assert.False(t, validator.IsNIK("3174013102900001")) // 31 February
+	assert.False(t, validator.IsNIK("3174012513900001")) // month 13
+	assert.False(t, validator.IsNIK("3174012501900000")) // serial 0000
+	assert.False(t, validator.IsNIK("317401250190001"))
+
+	// Test Case 3: NPWP check digit and NIK based NPWP
+	assert.True(t, validator.IsNPWP("01.855.081.4-412.000"))
+	assert.True(t, validator.IsNPWP("018550814412000"))
+	assert.True(t, validator.IsNPWP("3174012501900001"))
+	assert.False(t, validator.IsNPWP("01.855.081.5-412.000"))
+	assert.False(t, validator.IsNPWP("01.855.081"))
+
+	// Test Case 4: Rules are selected by country
+	router, _ := setupRouter()
+	submit := func(body string) (int, apperror.Problem) {
+		req, _ := http.NewRequest(http.MethodPost, "/loan-applications", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer mysecrettoken")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var errResponse apperror.Problem
+		json.Unmarshal(w.Body.Bytes(), &errResponse)
+		return w.Code, errResponse
+	}
+
+	code, _ := submit(`{"applicant_name":"Budi","applicant_ssn":"3174012501900001","country":"ID","tax_id":"01.855.081.4-412.000","loan_amount":75000000,"loan_purpose":"Business Expansion","annual_income":1000,"credit_score":700}`)
+	assert.Equal(t, http.StatusCreated, code)
+
+	code, errResponse := submit(`{"applicant_name":"Budi","applicant_ssn":"123-45-6789","country":"ID","tax_id":"123","loan_amount":75000000,"loan_purpose":"Business Expansion","annual_income":1000,"credit_score":700}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Len(t, errResponse.Errors, 2)
+	assert.Equal(t, "applicant_ssn", errResponse.Errors[0].Field)
+	assert.Equal(t, "tax_id", errResponse.Errors[1].Field)
+
+	code, errResponse = submit(`{"applicant_name":"Budi","applicant_ssn":"3174012501900001","loan_amount":5000,"loan_purpose":"Business Expansion","annual_income":1000,"credit_score":700}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "invalid_format", errResponse.Errors[0].Code)
+
+	// Test Case 5: Loan purpose enumeration
+	code, errResponse = submit(`{"applicant_name":"Budi","applicant_ssn":"123-45-6789","loan_amount":5000,"loan_purpose":"Vacation","annual_income":1000,"credit_score":700}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "loan_purpose", errResponse.Errors[0].Field)
+	assert.Equal(t, "unsupported_value", errResponse.Errors[0].Code)
+
+	code, errResponse = submit(`{"applicant_name":"Budi","applicant_ssn":"123-45-6789","country":"SG","loan_amount":5000,"loan_purpose":"education","annual_income":1000,"credit_score":700}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "country", errResponse.Errors[0].Field)
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"loan-api/model"
+	"loan-api/serializer"
+)
+
+func TestAPIVersions(t *testing.T) {
+	router, memStore := setupRouter()
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Nanda", ApplicantSSN: "123-45-6789", LoanAmount: 20000, AnnualIncome: 75000.5, Country: model.CountryUS})
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Eka", ApplicantSSN: "3171234567890123", LoanAmount: 150000000, AnnualIncome: 90000000, Country: model.CountryID})
+	memStore.SaveLoanApplication(model.LoanApplication{ApplicantName: "Budi", ApplicantSSN: "987-65-4321", LoanAmount: 5000})
+
+	// Test Case 1: v1 keeps the original shapes
+	w := doJSON(router, http.MethodGet, "/v1/loan-applications?limit=2", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	var apps []model.LoanApplication
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &apps))
+	assert.Len(t, apps, 2)
+	assert.Equal(t, 20000.0, apps[0].LoanAmount)
+
+	// Test Case 2: Unversioned routes are deprecated aliases of v1
+	w = doJSON(router, http.MethodGet, "/loan-applications/1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(t, `</v1/loan-applications/1>; rel="successor-version"`, w.Header().Get("Link"))
+	w = doJSON(router, http.MethodGet, "/v1/loan-applications/1", nil)
+	assert.JSONEq(t, doJSON(router, http.MethodGet, "/loan-applications/1", nil).Body.String(), w.Body.String())
+
+	// Test Case 3: v2 envelopes lists with the paging details
+	w = doJSON(router, http.MethodGet, "/v2/loan-applications?limit=2&page=2", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data       []map[string]any      `json:"data"`
+		Pagination serializer.Pagination `json:"pagination"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Data, 1)
+	assert.Equal(t, serializer.Pagination{Page: 2, Limit: 2, Total: 3}, list.Pagination)
+	w = doJSON(router, http.MethodGet, "/v2/loan-applications?status=rejected", nil)
+	assert.JSONEq(t, `{"data": [], "pagination": {"page": 1, "limit": 10, "total": 0}}`, w.Body.String())
+
+	// Test Case 4: v2 writes money as decimal strings in the country's currency
+	w = doJSON(router, http.MethodGet, "/v2/loan-applications/2", nil)
+	var app map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &app))
+	assert.Equal(t, map[string]any{"amount": "150000000.00", "currency": "IDR"}, app["loan_amount"])
+	assert.Equal(t, "XXXXXXXXXXXX0123", app["applicant_ssn"])
+	w = doJSON(router, http.MethodGet, "/v2/loan-applications/1", nil)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &app))
+	assert.Equal(t, map[string]any{"amount": "75000.50", "currency": "USD"}, app["annual_income"])
+	submittedAt, err := time.Parse(time.RFC3339, app["submitted_at"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, time.UTC, submittedAt.Location())
+
+	// Test Case 5: Writes and errors behave the same in every version
+	w = doJSON(router, http.MethodPut, "/v2/loan-applications/3/status", map[string]string{"status": model.StatusUnderReview})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &app))
+	assert.Equal(t, map[string]any{"amount": "5000.00", "currency": "USD"}, app["loan_amount"])
+	w = doJSON(router, http.MethodGet, "/v2/loan-applications/99", nil)
+	assert.Equal(t, "application_not_found", decodeProblem(t, w).Code)
+	w = doAs(router, "", http.MethodGet, "/v2/loan-applications", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSerializeTimestamps(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	at := time.Date(2026, 3, 1, 9, 30, 15, 500_000_000, jakarta)
+	note := model.Note{ID: 1, Body: "Checked payslip", CreatedAt: at, UpdatedAt: &at}
+
+	// Test Case 1: v1 leaves timestamps as they are
+	body, _ := json.Marshal(serializer.Serialize(serializer.V1, note))
+	assert.Contains(t, string(body), `"created_at":"2026-03-01T09:30:15.5+07:00"`)
+
+	// Test Case 2: v2 writes nested timestamps in UTC and keeps fractional seconds
+	body, _ = json.Marshal(serializer.Serialize(serializer.V2, []model.Note{note}))
+	assert.Contains(t, string(body), `"created_at":"2026-03-01T02:30:15.5Z"`)
+	assert.Contains(t, string(body), `"updated_at":"2026-03-01T02:30:15.5Z"`)
+	assert.Equal(t, at, note.CreatedAt, "the original value is not modified")
+}
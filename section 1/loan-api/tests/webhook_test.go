@@ -0,0 +1,276 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"loan-api/events"
+	"loan-api/handler"
+	"loan-api/middleware"
+	"loan-api/model"
+	"loan-api/routes"
+	"loan-api/store"
+	"loan-api/webhook"
+)
+
+func setupWebhookRouter() (*gin.Engine, *store.WebhookStore) {
+	r := gin.New()
+	webhookStore := store.NewWebhookStore()
+	dispatcher := webhook.NewDispatcher(webhookStore)
+	dispatcher.BaseBackoff = 10 * time.Millisecond
+	dispatcher.MaxAttempts = 3
+	// The receivers in these tests listen on loopback.
+	dispatcher.AllowPrivateTargets = true
+
+	bus := events.NewBus()
+	bus.Subscribe(dispatcher.HandleEvent)
+
+	loanHandler := handler.NewLoanHandler(store.NewMemoryStore(), bus)
+	routes.SetupRoutes(r, routes.Handlers{
+		Loan:    loanHandler,
+		Webhook: handler.NewWebhookHandler(webhookStore, dispatcher),
+	})
+	return r, webhookStore
+}
+
+func doJSON(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != nil {
+		jsonBody, _ := json.Marshal(body)
+		reader = bytes.NewBuffer(jsonBody)
+	}
+	req, _ := http.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer mysecrettoken")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func waitForDelivery(webhookStore *store.WebhookStore, subID int, status string) (model.WebhookDelivery, bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		deliveries := webhookStore.ListDeliveries(subID)
+		if len(deliveries) > 0 && deliveries[0].Status == status {
+			return deliveries[0], true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return model.WebhookDelivery{}, false
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	router, webhookStore := setupWebhookRouter()
+
+	var mu sync.Mutex
+	var received []*http.Request
+	var bodies [][]byte
+	failFirst := true
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, body)
+		if failFirst {
+			failFirst = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	// Test Case 1: Create a subscription filtered to submissions
+	w := doJSON(router, http.MethodPost, "/webhooks", map[string]interface{}{
+		"url":    receiver.URL,
+		"events": []string{model.EventApplicationSubmitted},
+		"secret": "topsecret",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var sub model.WebhookSubscription
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &sub))
+	assert.True(t, sub.Active)
+	assert.Equal(t, "topsecret", sub.Secret)
+
+	// Test Case 2: Secrets are not returned after creation
+	w = doJSON(router, http.MethodGet, fmt.Sprintf("/webhooks/%d", sub.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var fetched model.WebhookSubscription
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched))
+	assert.Empty(t, fetched.Secret)
+
+	// Test Case 3: Submitting an application is delivered, signed and retried
+	w = doJSON(router, http.MethodPost, "/loan-applications", model.LoanApplication{
+		ApplicantName: "nanda",
+		ApplicantSSN:  "123-45-6789",
+		LoanAmount:    50000.0,
+		LoanPurpose:   "Home Renovation",
+		AnnualIncome:  75000.0,
+		CreditScore:   720,
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	delivery, ok := waitForDelivery(webhookStore, sub.ID, model.DeliverySucceeded)
+	assert.True(t, ok)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, model.EventApplicationSubmitted, delivery.EventType)
+
+	mu.Lock()
+	last := received[len(received)-1]
+	lastBody := bodies[len(bodies)-1]
+	mu.Unlock()
+	assert.Equal(t, model.EventApplicationSubmitted, last.Header.Get(webhook.EventHeader))
+	expected := webhook.Sign("topsecret", last.Header.Get(webhook.TimestampHeader), lastBody)
+	assert.Equal(t, expected, last.Header.Get(webhook.SignatureHeader))
+
+	var event model.Event
+	assert.NoError(t, json.Unmarshal(lastBody, &event))
+	assert.Equal(t, "XXX-XX-6789", event.Application.ApplicantSSN)
+
+	// Test Case 4: Status changes are filtered out for this subscription
+	w = doJSON(router, http.MethodPut, fmt.Sprintf("/loan-applications/%d/status", event.ApplicationID), map[string]string{"status": "approved"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, webhookStore.ListDeliveries(sub.ID), 1)
+
+	// Test Case 5: Manual redelivery, which starts one round at a time
+	redeliver := fmt.Sprintf("/webhooks/%d/deliveries/%d/redeliver", sub.ID, delivery.ID)
+	codes := make(chan int, 4)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- doJSON(router, http.MethodPost, redeliver, nil).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+	accepted := 0
+	for code := range codes {
+		if code == http.StatusAccepted {
+			accepted++
+		} else {
+			assert.Equal(t, http.StatusConflict, code)
+		}
+	}
+	assert.Equal(t, 1, accepted)
+	delivery, ok = waitForDelivery(webhookStore, sub.ID, model.DeliverySucceeded)
+	assert.True(t, ok)
+	assert.Equal(t, 3, delivery.Attempts)
+
+	// Test Case 6: Unknown event filters are rejected
+	w = doJSON(router, http.MethodPost, "/webhooks", map[string]interface{}{
+		"url":    receiver.URL,
+		"events": []string{"application.exploded"},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Test Case 7: Delete the subscription
+	w = doJSON(router, http.MethodDelete, fmt.Sprintf("/webhooks/%d", sub.ID), nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doJSON(router, http.MethodGet, fmt.Sprintf("/webhooks/%d", sub.ID), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Test Case 8: Applicants cannot manage subscriptions
+	middleware.RegisterToken("webhook-applicant", model.Principal{Subject: "dewi", Role: model.RoleApplicant})
+	w = doAs(router, "webhook-applicant", http.MethodPost, "/webhooks", fmt.Sprintf(`{"url": %q}`, receiver.URL))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = doAs(router, "webhook-applicant", http.MethodGet, "/v2/webhooks", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestWebhookTargets(t *testing.T) {
+	r := gin.New()
+	webhookStore := store.NewWebhookStore()
+	dispatcher := webhook.NewDispatcher(webhookStore)
+	dispatcher.MaxAttempts = 1
+	bus := events.NewBus()
+	bus.Subscribe(dispatcher.HandleEvent)
+	routes.SetupRoutes(r, routes.Handlers{
+		Loan:    handler.NewLoanHandler(store.NewMemoryStore(), bus),
+		Webhook: handler.NewWebhookHandler(webhookStore, dispatcher),
+	})
+
+	// Test Case 1: Subscriptions cannot target the deployment's network
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hook", "http://localhost/hook", "http://[::1]/hook", "http://10.0.0.5/hook",
+		"http://192.168.1.1/hook", "http://169.254.169.254/latest/meta-data", "http://100.100.100.200/hook",
+		"http://0.0.0.0/hook", "http://[fd00:ec2::254]/hook", "http://[::ffff:127.0.0.1]/hook",
+	} {
+		w := doJSON(r, http.MethodPost, "/webhooks", map[string]string{"url": url})
+		if assert.Equal(t, http.StatusBadRequest, w.Code, url) {
+			assert.Equal(t, []model.FieldError{{Field: "url", Code: "invalid_url", Message: "url must point at a public address"}}, decodeProblem(t, w).Errors)
+		}
+	}
+	w := doJSON(r, http.MethodPost, "/webhooks", map[string]string{"url": "ftp://203.0.113.10/hook"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_url", decodeProblem(t, w).Errors[0].Code)
+
+	w = doJSON(r, http.MethodPost, "/webhooks", map[string]string{"url": "https://203.0.113.10/hook"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = doJSON(r, http.MethodPut, "/webhooks/1", map[string]string{"url": "http://127.0.0.1/hook"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Test Case 2: Deliveries do not connect to private addresses either
+	received := make(chan struct{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer receiver.Close()
+	webhookStore.DeleteSubscription(1)
+	sub := webhookStore.SaveSubscription(model.WebhookSubscription{URL: receiver.URL, Active: true})
+	bus.Publish(model.EventApplicationSubmitted, model.LoanApplication{ID: 1}, nil)
+	delivery, ok := waitForDelivery(webhookStore, sub.ID, model.DeliveryFailed)
+	assert.True(t, ok)
+	assert.Contains(t, delivery.LastError, "not a public address")
+	assert.Empty(t, received)
+}
+
+func TestWebhookDeliveryLog(t *testing.T) {
+	webhookStore := store.NewWebhookStoreWithDeliveryLog(2)
+	save := func(status string) model.WebhookDelivery {
+		delivery := webhookStore.SaveDelivery(model.WebhookDelivery{SubscriptionID: 1, Status: model.DeliveryPending})
+		if status != model.DeliveryPending {
+			delivery.Status = status
+			webhookStore.UpdateDelivery(delivery)
+		}
+		return delivery
+	}
+	ids := func() []int {
+		var result []int
+		for _, delivery := range webhookStore.ListDeliveries(1) {
+			result = append(result, delivery.ID)
+		}
+		return result
+	}
+
+	// Test Case 1: The oldest finished deliveries are dropped
+	save(model.DeliverySucceeded)
+	save(model.DeliveryFailed)
+	save(model.DeliverySucceeded)
+	assert.Equal(t, []int{3, 2}, ids())
+	_, found := webhookStore.GetDelivery(1)
+	assert.False(t, found)
+
+	// Test Case 2: Pending deliveries are kept until they finish
+	webhookStore = store.NewWebhookStoreWithDeliveryLog(2)
+	pending := save(model.DeliveryPending)
+	save(model.DeliverySucceeded)
+	save(model.DeliverySucceeded)
+	assert.Equal(t, []int{3, 1}, ids())
+	pending.Status = model.DeliverySucceeded
+	webhookStore.UpdateDelivery(pending)
+	assert.Equal(t, []int{3, 1}, ids())
+	save(model.DeliveryFailed)
+	assert.Equal(t, []int{4, 3}, ids())
+}
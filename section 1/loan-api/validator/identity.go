@@ -0,0 +1,155 @@
+package validator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"loan-api/model"
+)
+
+var (
+	usSSNPattern = regexp.MustCompile(`^(\d{3})-(\d{2})-(\d{4})$`)
+	npwpPattern  = regexp.MustCompile(`^\d{2}\.\d{3}\.\d{3}\.\d-\d{3}\.\d{3}$`)
+	digitsOnly   = regexp.MustCompile(`^\d+$`)
+)
+
+// Province codes used in the first two digits of a NIK.
+var nikProvinces = map[int]bool{
+	11: true, 12: true, 13: true, 14: true, 15: true, 16: true, 17: true, 18: true, 19: true,
+	21: true, 31: true, 32: true, 33: true, 34: true, 35: true, 36: true,
+	51: true, 52: true, 53: true, 61: true, 62: true, 63: true, 64: true, 65: true,
+	71: true, 72: true, 73: true, 74: true, 75: true, 76: true, 81: true, 82: true,
+	91: true, 92: true, 93: true, 94: true, 95: true, 96: true,
+}
+
+// IsUSSSN checks the XXX-XX-XXXX format and the SSA allocation rules: the
+// area is not 000, 666 or 900-999, the group is not 00 and the serial is not 0000.
+func IsUSSSN(ssn string) bool {
+	parts := usSSNPattern.FindStringSubmatch(ssn)
+	if parts == nil {
+		return false
+	}
+	area, _ := strconv.Atoi(parts[1])
+	if area == 0 || area == 666 || area >= 900 {
+		return false
+	}
+	return parts[2] != "00" && parts[3] != "0000"
+}
+
+// IsNIK checks an Indonesian Nomor Induk Kependudukan: 16 digits made of a
+// province/regency/district code, the birth date as DDMMYY (40 is added to
+// the day for women) and a serial number that is not 0000.
+func IsNIK(nik string) bool {
+	if len(nik) != 16 || !digitsOnly.MatchString(nik) {
+		return false
+	}
+	province, _ := strconv.Atoi(nik[0:2])
+	if !nikProvinces[province] || nik[2:4] == "00" || nik[4:6] == "00" {
+		return false
+	}
+
+	day, _ := strconv.Atoi(nik[6:8])
+	month, _ := strconv.Atoi(nik[8:10])
+	if day > 40 {
+		day -= 40
+	}
+	if day < 1 || month < 1 || month > 12 {
+		return false
+	}
+	// The year only has two digits, so check against a leap year to allow 29 February.
+	birthDate := time.Date(2000, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if birthDate.Day() != day {
+		return false
+	}
+	return nik[12:16] != "0000"
+}
+
+// IsNPWP checks an Indonesian tax ID. Both the 15 digit form (optionally
+// formatted as XX.XXX.XXX.X-XXX.XXX, with a Luhn check digit in position 9)
+// and the 16 digit form introduced in 2024, which equals the holder's NIK, are accepted.
+func IsNPWP(npwp string) bool {
+	if npwpPattern.MatchString(npwp) {
+		npwp = strings.NewReplacer(".", "", "-", "").Replace(npwp)
+	}
+	if !digitsOnly.MatchString(npwp) {
+		return false
+	}
+	switch len(npwp) {
+	case 15:
+		return luhnValid(npwp[:9])
+	case 16:
+		return IsNIK(npwp)
+	default:
+		return false
+	}
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func registerIdentityValidations(v *validator.Validate) error {
+	rules := map[string]func(string) bool{
+		"us_ssn":       IsUSSSN,
+		"nik":          IsNIK,
+		"npwp":         IsNPWP,
+		"loan_purpose": model.IsValidLoanPurpose,
+	}
+	for tag, rule := range rules {
+		err := v.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+			return rule(fl.Field().String())
+		})
+		if err != nil {
+			return err
+		}
+	}
+	v.RegisterStructValidation(validateLoanApplicationCountry, model.LoanApplication{})
+	return nil
+}
+
+// validateLoanApplicationCountry applies the identity rules and amount limits
+// of the application's country. Applications without a country are treated as
+// US.
+func validateLoanApplicationCountry(sl validator.StructLevel) {
+	app := sl.Current().Interface().(model.LoanApplication)
+
+	limits := model.LoanAmountLimits(app.Country)
+	switch {
+	case app.LoanAmount == 0:
+		// Reported by the required tag.
+	case app.LoanAmount < limits.Min:
+		sl.ReportError(app.LoanAmount, "loan_amount", "LoanAmount", "min", strconv.FormatFloat(limits.Min, 'f', -1, 64))
+	case app.LoanAmount > limits.Max:
+		sl.ReportError(app.LoanAmount, "loan_amount", "LoanAmount", "max", strconv.FormatFloat(limits.Max, 'f', -1, 64))
+	}
+
+	switch app.Country {
+	case "", model.CountryUS:
+		if app.ApplicantSSN != "" && !IsUSSSN(app.ApplicantSSN) {
+			sl.ReportError(app.ApplicantSSN, "applicant_ssn", "ApplicantSSN", "us_ssn", "")
+		}
+	case model.CountryID:
+		if app.ApplicantSSN != "" && !IsNIK(app.ApplicantSSN) {
+			sl.ReportError(app.ApplicantSSN, "applicant_ssn", "ApplicantSSN", "nik", "")
+		}
+		if app.TaxID != "" && !IsNPWP(app.TaxID) {
+			sl.ReportError(app.TaxID, "tax_id", "TaxID", "npwp", "")
+		}
+	}
+}
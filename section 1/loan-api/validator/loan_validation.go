@@ -1,35 +1,231 @@
 package validator
 
 import (
+	"encoding/json"
 	"errors"
-	"fmt"
+	"reflect"
+	"strings"
 
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
+	"golang.org/x/text/language"
+	"loan-api/model"
 )
 
-var validate = validator.New()
+const (
+	CodeRequired      = "required"
+	CodeTooSmall      = "too_small"
+	CodeTooLarge      = "too_large"
+	CodeInvalidLength = "invalid_length"
+	CodeInvalidURL    = "invalid_url"
+	CodeInvalidType   = "invalid_type"
+	CodeInvalidFormat = "invalid_format"
+	CodeUnsupported   = "unsupported_value"
+	CodeInvalid       = "invalid"
+)
+
+// Message keys for rules whose message differs from their code's.
+const (
+	KeyBelowTenantMinimum = "below_tenant_minimum"
+	KeyAboveTenantMaximum = "above_tenant_maximum"
+	KeyPrivateTarget      = "private_target"
+)
+
+// tagCodes maps validator tags to the stable codes returned to clients.
+var tagCodes = map[string]string{
+	"required":         CodeRequired,
+	"required_without": CodeRequired,
+	"min":              CodeTooSmall,
+	"gte":              CodeTooSmall,
+	"max":              CodeTooLarge,
+	"lte":              CodeTooLarge,
+	"len":              CodeInvalidLength,
+	"url":              CodeInvalidURL,
+	"datetime":         CodeInvalidFormat,
+	"oneof":            CodeUnsupported,
+	"us_ssn":           CodeInvalidFormat,
+	"nik":              CodeInvalidFormat,
+	"npwp":             CodeInvalidFormat,
+	"loan_purpose":     CodeUnsupported,
+}
+
+// customTranslations holds messages for rules that are not validator tags.
+// {0} is the JSON field name.
+var customTranslations = map[string]map[string]string{
+	"en": {
+		CodeInvalidType: "{0} has an invalid type",
+		CodeInvalid:     "{0} is not valid",
+
+		KeyBelowTenantMinimum: "{0} is below the minimum this lender accepts",
+		KeyAboveTenantMaximum: "{0} is above the maximum this lender accepts",
+		KeyPrivateTarget:      "{0} must point at a public address",
+	},
+	"id": {
+		CodeInvalidType: "{0} memiliki tipe yang tidak valid",
+		CodeInvalid:     "{0} tidak valid",
+
+		KeyBelowTenantMinimum: "{0} di bawah batas minimum pemberi pinjaman ini",
+		KeyAboveTenantMaximum: "{0} di atas batas maksimum pemberi pinjaman ini",
+		KeyPrivateTarget:      "{0} harus mengarah ke alamat publik",
+	},
+}
+
+// tagTranslations holds messages for the custom validator tags.
+var tagTranslations = map[string]map[string]string{
+	"en": {
+		"us_ssn":       "{0} must be a valid US Social Security Number in XXX-XX-XXXX format",
+		"nik":          "{0} must be a valid 16 digit NIK",
+		"npwp":         "{0} must be a valid NPWP",
+		"loan_purpose": "{0} must be one of: " + strings.Join(model.LoanPurposes, ", "),
+	},
+	"id": {
+		"us_ssn":       "{0} harus berupa Social Security Number AS yang valid dengan format XXX-XX-XXXX",
+		"nik":          "{0} harus berupa NIK 16 digit yang valid",
+		"npwp":         "{0} harus berupa NPWP yang valid",
+		"loan_purpose": "{0} harus salah satu dari: " + strings.Join(model.LoanPurposes, ", "),
+		// The default Indonesian translations have no message for this tag.
+		"required_without": "{0} wajib diisi",
+	},
+}
+
+var (
+	validate *validator.Validate
+	uni      *ut.UniversalTranslator
+	matcher  = language.NewMatcher([]language.Tag{language.English, language.Indonesian})
+)
+
+// The gin binding engine is configured here so that binding tags and the
+// custom rules share field names and translations.
+func init() {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		panic("validator: unexpected gin binding engine")
+	}
+	validate = engine
+	validate.RegisterTagNameFunc(jsonFieldName)
+
+	english := en.New()
+	uni = ut.New(english, english, id.New())
+
+	enTrans, _ := uni.GetTranslator("en")
+	idTrans, _ := uni.GetTranslator("id")
+	if err := en_translations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		panic(err)
+	}
+	if err := id_translations.RegisterDefaultTranslations(validate, idTrans); err != nil {
+		panic(err)
+	}
+	for locale, messages := range customTranslations {
+		trans, _ := uni.GetTranslator(locale)
+		for key, text := range messages {
+			if err := trans.Add(key, text, false); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	if err := registerIdentityValidations(validate); err != nil {
+		panic(err)
+	}
+	for locale, messages := range tagTranslations {
+		trans, _ := uni.GetTranslator(locale)
+		for tag, text := range messages {
+			if err := validate.RegisterTranslation(tag, trans, addTranslation(tag, text), translateTag); err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+func addTranslation(tag, text string) validator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		return trans.Add(tag, text, true)
+	}
+}
+
+func translateTag(trans ut.Translator, fe validator.FieldError) string {
+	message, err := trans.T(fe.Tag(), fe.Field())
+	if err != nil {
+		return fe.Error()
+	}
+	return message
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// Translator picks the best supported translator for an Accept-Language
+// header value, falling back to English.
+func Translator(acceptLanguage string) ut.Translator {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, index, _ := matcher.Match(tags...)
+	locale := "en"
+	if index == 1 {
+		locale = "id"
+	}
+	trans, _ := uni.GetTranslator(locale)
+	return trans
+}
+
+// NewFieldError builds a translated error for a rule checked outside the
+// binding tags. key selects the message and defaults to the code.
+func NewFieldError(trans ut.Translator, field, code, key string) model.FieldError {
+	if key == "" {
+		key = code
+	}
+	message, err := trans.T(key, field)
+	if err != nil {
+		message, _ = trans.T(CodeInvalid, field)
+	}
+	return model.FieldError{Field: field, Code: code, Message: message}
+}
+
+// ValidateLoanApplication converts a binding error into per-field errors
+// translated for the given Accept-Language header. The returned error is nil
+// when err is not a validation or type error.
+func ValidateLoanApplication(err error, acceptLanguage string) ([]model.FieldError, error) {
+	trans := Translator(acceptLanguage)
 
-func ValidateLoanApplication(err error) ([]string, error) {
 	var ve validator.ValidationErrors
 	if errors.As(err, &ve) {
-		var messages []string
+		var fields []model.FieldError
 		for _, fe := range ve {
-			field := fe.Field()
-			switch fe.Tag() {
-			case "required":
-				messages = append(messages, fmt.Sprintf("%s is a required field", field))
-			case "min":
-				messages = append(messages, fmt.Sprintf("%s must be at least %s", field, fe.Param()))
-			case "max":
-				messages = append(messages, fmt.Sprintf("%s must be at most %s", field, fe.Param()))
-			case "len":
-				messages = append(messages, fmt.Sprintf("%s must be %s characters long", field, fe.Param()))
-			default:
-				messages = append(messages, fmt.Sprintf("%s is not valid", field))
+			code, ok := tagCodes[fe.Tag()]
+			if !ok {
+				code = CodeInvalid
 			}
+			fields = append(fields, model.FieldError{
+				Field:   fe.Field(),
+				Code:    code,
+				Message: fe.Translate(trans),
+			})
 		}
-		return messages, errors.New("Invalid input")
+		return fields, errors.New("Invalid input")
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []model.FieldError{NewFieldError(trans, typeErr.Field, CodeInvalidType, "")}, errors.New("Invalid input")
 	}
 
-	return []string{"Invalid input"}, nil
+	return nil, nil
+}
+
+// ValidateStruct runs the binding tags and custom rules on a value that did
+// not come through gin binding, such as an imported row.
+func ValidateStruct(obj interface{}) error {
+	return binding.Validator.ValidateStruct(obj)
 }
@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"loan-api/model"
+	"loan-api/store"
+)
+
+const (
+	SignatureHeader = "X-Loan-Signature"
+	TimestampHeader = "X-Loan-Timestamp"
+	EventHeader     = "X-Loan-Event"
+	DeliveryHeader  = "X-Loan-Delivery"
+)
+
+var (
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrDeliveryInProgress   = errors.New("webhook delivery is still in progress")
+)
+
+// Dispatcher delivers events to the matching subscriptions of the event's
+// tenant. Each delivery is retried with exponential backoff (BaseBackoff,
+// 2*BaseBackoff, ...) until it succeeds or MaxAttempts is reached; every
+// attempt is recorded in the store. Deliveries only connect to public
+// addresses unless AllowPrivateTargets is set.
+type Dispatcher struct {
+	Store               *store.WebhookStore
+	Client              *http.Client
+	MaxAttempts         int
+	BaseBackoff         time.Duration
+	AllowPrivateTargets bool
+}
+
+func NewDispatcher(s *store.WebhookStore) *Dispatcher {
+	d := &Dispatcher{
+		Store:       s,
+		MaxAttempts: 5,
+		BaseBackoff: time.Second,
+	}
+	// Deliveries connect directly, so the dialer sees the target's address
+	// rather than a proxy's.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: d.control}).DialContext
+	d.Client = &http.Client{Timeout: 10 * time.Second, Transport: transport}
+	return d
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>".
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *Dispatcher) HandleEvent(event model.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Webhook - failed to encode event %d: %v", event.ID, err)
+		return
+	}
+
+	subscriptions := d.Store.ForTenant(event.Tenant)
+	if model.IsErasureEvent(event.Type) {
+		subscriptions.RedactDeliveries(event.ApplicationID, func(payload string) string {
+			return redactPayload(payload, event.Application)
+		})
+	}
+	for _, sub := range subscriptions.ListSubscriptions() {
+		if !sub.Matches(event.Type) {
+			continue
+		}
+		delivery := subscriptions.SaveDelivery(model.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			ApplicationID:  event.ApplicationID,
+			Payload:        string(payload),
+			Status:         model.DeliveryPending,
+		})
+		go d.deliver(subscriptions, sub, delivery)
+	}
+}
+
+// redactPayload replaces the application in a recorded event with its
+// anonymized version. Payloads that cannot be decoded are dropped.
+func redactPayload(payload string, app model.LoanApplication) string {
+	var event model.Event
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return ""
+	}
+	redacted, err := json.Marshal(event.Redacted(app))
+	if err != nil {
+		return ""
+	}
+	return string(redacted)
+}
+
+// Redeliver queues a fresh round of attempts for an existing delivery of
+// tenant.
+func (d *Dispatcher) Redeliver(tenant string, subscriptionID, deliveryID int) (model.WebhookDelivery, error) {
+	subscriptions := d.Store.ForTenant(tenant)
+	delivery, found := subscriptions.GetDelivery(deliveryID)
+	if !found || delivery.SubscriptionID != subscriptionID {
+		return delivery, ErrDeliveryNotFound
+	}
+	sub, found := subscriptions.GetSubscription(subscriptionID)
+	if !found {
+		return delivery, ErrSubscriptionNotFound
+	}
+	delivery, restarted := subscriptions.RestartDelivery(deliveryID)
+	if !restarted {
+		return delivery, ErrDeliveryInProgress
+	}
+	go d.deliver(subscriptions, sub, delivery)
+	return delivery, nil
+}
+
+func (d *Dispatcher) deliver(subscriptions *store.WebhookStore, sub model.WebhookSubscription, delivery model.WebhookDelivery) {
+	backoff := d.BaseBackoff
+	for attempt := 1; attempt <= d.MaxAttempts; attempt++ {
+		// The payload may have been redacted by an erasure since the last
+		// attempt, so every attempt sends the stored one.
+		if stored, found := subscriptions.GetDelivery(delivery.ID); found {
+			delivery.Payload = stored.Payload
+		}
+		code, err := d.send(sub, delivery)
+		now := time.Now()
+		delivery.Attempts++
+		delivery.LastAttemptAt = &now
+		delivery.ResponseCode = code
+
+		if err == nil {
+			delivery.Status = model.DeliverySucceeded
+			delivery.LastError = ""
+			subscriptions.UpdateDelivery(delivery)
+			return
+		}
+
+		delivery.LastError = err.Error()
+		if attempt == d.MaxAttempts {
+			delivery.Status = model.DeliveryFailed
+			subscriptions.UpdateDelivery(delivery)
+			log.Printf("Webhook - delivery %d to %s failed after %d attempts: %v", delivery.ID, sub.URL, attempt, err)
+			return
+		}
+		subscriptions.UpdateDelivery(delivery)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (d *Dispatcher) send(sub model.WebhookSubscription, delivery model.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.Itoa(delivery.ID))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, timestamp, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// ErrPrivateTarget is returned for webhook URLs that resolve to an address
+// inside the deployment's network.
+var ErrPrivateTarget = errors.New("webhook target is not a public address")
+
+// sharedAddressSpace is the carrier-grade NAT range, which some clouds use
+// for their metadata services.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublicAddr reports whether addr may receive webhooks. Loopback, private,
+// link-local (which holds the cloud metadata endpoints), shared, multicast
+// and unspecified addresses may not.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() && addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// CheckTarget rejects URLs that are not http or https or whose host resolves
+// to an address that is not public. Deliveries check the address again when
+// they connect, since the host may resolve differently by then.
+func (d *Dispatcher) CheckTarget(ctx context.Context, rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return fmt.Errorf("webhook target must use http or https, got %q", target.Scheme)
+	}
+	if d.AllowPrivateTargets {
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", target.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !IsPublicAddr(addr) {
+			return ErrPrivateTarget
+		}
+	}
+	return nil
+}
+
+// control refuses connections to addresses that are not public unless
+// AllowPrivateTargets is set. It runs for every connection, including the
+// ones made for redirects.
+func (d *Dispatcher) control(_, address string, _ syscall.RawConn) error {
+	if d.AllowPrivateTargets {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublicAddr(addrPort.Addr()) {
+		return ErrPrivateTarget
+	}
+	return nil
+}
@@ -26,6 +26,19 @@ func (c *CreditCache) Get(ssn string) (*model.CreditReport, bool) {
 	return report, true
 }
 
+// Stats counts the cached reports and how many of them are still fresh
+// enough to be served.
+func (c *CreditCache) Stats() (entries, fresh int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, report := range c.data {
+		if time.Since(report.RetrievedAt) <= 24*time.Hour {
+			fresh++
+		}
+	}
+	return len(c.data), fresh
+}
+
 func (c *CreditCache) Set(ssn string, report *model.CreditReport) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"loan-microservice/tracing"
+)
+
+// Config is the runtime configuration of the underwriting service. Values are
+// resolved in order of increasing precedence: defaults, the JSON file given by
+// -config (or UNDERWRITING_CONFIG), UNDERWRITING_* environment variables and flags.
+type Config struct {
+	Server     ServerConfig     `json:"server"`
+	Metrics    MetricsConfig    `json:"metrics"`
+	Tracing    TracingConfig    `json:"tracing"`
+	Breaker    BreakerConfig    `json:"breaker"`
+	Simulation SimulationConfig `json:"simulation"`
+}
+
+// ServerConfig sets the port of the evaluation API. The API is off by
+// default, leaving only the simulation loop.
+type ServerConfig struct {
+	Port int `json:"port"`
+}
+
+type MetricsConfig struct {
+	Port            int      `json:"port"`
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+}
+
+// TracingConfig selects where spans go: "otlp" posts them to Endpoint (or the
+// OTEL_EXPORTER_OTLP_* location), "stdout" prints them and "none" drops them.
+type TracingConfig struct {
+	Exporter string `json:"exporter"`
+	Endpoint string `json:"endpoint"`
+}
+
+// BreakerConfig mirrors the gobreaker.Settings used for the CreditService.
+type BreakerConfig struct {
+	MaxRequests uint32   `json:"max_requests"`
+	Interval    Duration `json:"interval"`
+	Timeout     Duration `json:"timeout"`
+}
+
+type SimulationConfig struct {
+	Evaluations int      `json:"evaluations"`
+	Interval    Duration `json:"interval"`
+}
+
+// Duration is a time.Duration written as a string such as "5s" in JSON.
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"5s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func Default() Config {
+	return Config{
+		Metrics: MetricsConfig{
+			Port:            2112,
+			ShutdownTimeout: Duration{10 * time.Second},
+		},
+		Tracing: TracingConfig{Exporter: tracing.ExporterNone},
+		Breaker: BreakerConfig{
+			MaxRequests: 5,
+			Interval:    Duration{10 * time.Second},
+			Timeout:     Duration{5 * time.Second},
+		},
+		Simulation: SimulationConfig{
+			Evaluations: 10,
+			Interval:    Duration{time.Second},
+		},
+	}
+}
+
+// Load builds the configuration from the command line arguments (without the
+// program name) and the process environment, and validates the result.
+func Load(args []string) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("loan-microservice", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("UNDERWRITING_CONFIG"), "path to a JSON configuration file")
+	serverPort := fs.Int("port", 0, "evaluation API listen port, 0 to disable")
+	port := fs.Int("metrics-port", 0, "Prometheus metrics listen port")
+	evaluations := fs.Int("evaluations", 0, "number of simulated evaluations")
+	traceExporter := fs.String("trace-exporter", "", "trace exporter: "+strings.Join(tracing.Exporters, ", "))
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if *path != "" {
+		if err := cfg.loadFile(*path); err != nil {
+			return cfg, err
+		}
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return cfg, err
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *serverPort
+		case "metrics-port":
+			cfg.Metrics.Port = *port
+		case "evaluations":
+			cfg.Simulation.Evaluations = *evaluations
+		case "trace-exporter":
+			cfg.Tracing.Exporter = *traceExporter
+		}
+	})
+
+	return cfg, cfg.Validate()
+}
+
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	ints := map[string]*int{
+		"UNDERWRITING_PORT":         &c.Server.Port,
+		"UNDERWRITING_METRICS_PORT": &c.Metrics.Port,
+		"UNDERWRITING_EVALUATIONS":  &c.Simulation.Evaluations,
+	}
+	for name, target := range ints {
+		if v, ok := lookup(name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			*target = n
+		}
+	}
+	if v, ok := lookup("UNDERWRITING_BREAKER_MAX_REQUESTS"); ok {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return fmt.Errorf("UNDERWRITING_BREAKER_MAX_REQUESTS: %w", err)
+		}
+		c.Breaker.MaxRequests = uint32(n)
+	}
+	if v, ok := lookup("UNDERWRITING_TRACE_EXPORTER"); ok {
+		c.Tracing.Exporter = v
+	}
+	if v, ok := lookup("UNDERWRITING_TRACE_ENDPOINT"); ok {
+		c.Tracing.Endpoint = v
+	}
+	durations := map[string]*Duration{
+		"UNDERWRITING_BREAKER_INTERVAL": &c.Breaker.Interval,
+		"UNDERWRITING_BREAKER_TIMEOUT":  &c.Breaker.Timeout,
+		"UNDERWRITING_SHUTDOWN_TIMEOUT": &c.Metrics.ShutdownTimeout,
+	}
+	for name, target := range durations {
+		if v, ok := lookup(name); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			target.Duration = d
+		}
+	}
+	return nil
+}
+
+func (c Config) Validate() error {
+	var errs []error
+	if c.Server.Port < 0 || c.Server.Port > 65535 || (c.Server.Port != 0 && c.Server.Port == c.Metrics.Port) {
+		errs = append(errs, fmt.Errorf("server.port must be 0 or a port between 1 and 65535 other than metrics.port, got %d", c.Server.Port))
+	}
+	if c.Metrics.Port < 1 || c.Metrics.Port > 65535 {
+		errs = append(errs, fmt.Errorf("metrics.port must be between 1 and 65535, got %d", c.Metrics.Port))
+	}
+	if c.Metrics.ShutdownTimeout.Duration <= 0 {
+		errs = append(errs, errors.New("metrics.shutdown_timeout must be positive"))
+	}
+	if !slices.Contains(tracing.Exporters, c.Tracing.Exporter) {
+		errs = append(errs, fmt.Errorf("tracing.exporter must be one of %s, got %q", strings.Join(tracing.Exporters, ", "), c.Tracing.Exporter))
+	}
+	if c.Breaker.MaxRequests < 1 {
+		errs = append(errs, errors.New("breaker.max_requests must be at least 1"))
+	}
+	if c.Breaker.Interval.Duration < 0 {
+		errs = append(errs, errors.New("breaker.interval must not be negative"))
+	}
+	if c.Breaker.Timeout.Duration <= 0 {
+		errs = append(errs, errors.New("breaker.timeout must be positive"))
+	}
+	if c.Simulation.Evaluations < 0 {
+		errs = append(errs, errors.New("simulation.evaluations must not be negative"))
+	}
+	if c.Simulation.Interval.Duration <= 0 {
+		errs = append(errs, errors.New("simulation.interval must be positive"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"loan-microservice/metrics"
+	"loan-microservice/model"
+	"loan-microservice/service"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// EvaluationHandler serves POST /evaluations, which runs an application
+// through the underwriting service. Callers' trace context is honoured, so an
+// evaluation requested by loan-api joins its trace.
+type EvaluationHandler struct {
+	Underwriter service.UnderwritingService
+}
+
+func NewEvaluationHandler(underwriter service.UnderwritingService) *EvaluationHandler {
+	return &EvaluationHandler{Underwriter: underwriter}
+}
+
+type evaluationRequest struct {
+	ID           string  `json:"id"`
+	ApplicantSSN string  `json:"applicant_ssn"`
+	LoanAmount   float64 `json:"amount"`
+}
+
+func (h *EvaluationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req evaluationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Request body must be a JSON application"})
+		return
+	}
+	if req.ApplicantSSN == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "applicant_ssn is required"})
+		return
+	}
+
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	start := time.Now()
+	decision, err := h.Underwriter.EvaluateApplication(ctx, &model.LoanApplication{
+		ID:           req.ID,
+		ApplicantSSN: req.ApplicantSSN,
+		LoanAmount:   req.LoanAmount,
+	})
+	metrics.CreditLatency.Observe(time.Since(start).Seconds())
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, decision)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
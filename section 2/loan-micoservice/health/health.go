@@ -0,0 +1,140 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Statuses of a report and of each check in it.
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// Check reports on one dependency. The details are copied into the report;
+// an error marks the dependency, and with it the service, as not ready.
+type Check func(ctx context.Context) (details map[string]any, err error)
+
+type CheckResult struct {
+	Status   string         `json:"status"`
+	Error    string         `json:"error,omitempty"`
+	Details  map[string]any `json:"details,omitempty"`
+	Duration string         `json:"duration"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// OK reports whether every check passed.
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Checker runs the registered readiness checks concurrently. A check that
+// does not answer within Timeout fails.
+type Checker struct {
+	Timeout time.Duration
+
+	checks map[string]Check
+	lock   sync.RWMutex
+}
+
+func NewChecker() *Checker {
+	return &Checker{Timeout: 2 * time.Second, checks: make(map[string]Check)}
+}
+
+// Register adds a check under name, replacing any check of the same name.
+func (c *Checker) Register(name string, check Check) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.checks[name] = check
+}
+
+// Run runs every check and reports StatusOK only if all of them passed.
+func (c *Checker) Run(ctx context.Context) Report {
+	c.lock.RLock()
+	checks := make(map[string]Check, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.lock.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := run(ctx, check)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFailing
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+// run waits for check until ctx is done. A check that ignores ctx is left
+// running in the background and reported as timed out.
+func run(ctx context.Context, check Check) CheckResult {
+	start := time.Now()
+	type outcome struct {
+		details map[string]any
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		details, err := check(ctx)
+		done <- outcome{details, err}
+	}()
+
+	var o outcome
+	select {
+	case o = <-done:
+	case <-ctx.Done():
+		o.err = fmt.Errorf("timed out: %w", ctx.Err())
+	}
+	result := CheckResult{Status: StatusOK, Details: o.details, Duration: time.Since(start).String()}
+	if o.err != nil {
+		result.Status = StatusFailing
+		result.Error = o.err.Error()
+	}
+	return result
+}
+
+// Live answers liveness probes. It checks no dependencies, so a failing
+// dependency does not get the process restarted.
+func Live(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, http.StatusOK, Report{Status: StatusOK})
+}
+
+// ServeHTTP answers readiness probes with the report of every check, and
+// 503 unless all of them pass.
+func (c *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := c.Run(r.Context())
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusServiceUnavailable
+	}
+	writeReport(w, status, report)
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
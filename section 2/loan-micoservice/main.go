@@ -2,32 +2,119 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
-	"loan-microservice/cache"
+	"loan-microservice/config"
+	"loan-microservice/handler"
+	"loan-microservice/health"
 	"loan-microservice/metrics"
 	"loan-microservice/model"
 	"loan-microservice/service"
+	"loan-microservice/tracing"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.Endpoint)
+	if err != nil {
+		log.Fatalf("Unable to set up tracing: %v", err)
+	}
+
+	breakerSettings := service.DefaultBreakerSettings()
+	breakerSettings.MaxRequests = cfg.Breaker.MaxRequests
+	breakerSettings.Interval = cfg.Breaker.Interval.Duration
+	breakerSettings.Timeout = cfg.Breaker.Timeout.Duration
+
 	creditService := &service.CreditServiceImpl{}
-	underwriter := service.NewUnderwritingService(creditService)
+	underwriter := service.NewUnderwritingService(creditService, breakerSettings)
+
+	checker := health.NewChecker()
+	for name, check := range underwriter.HealthChecks() {
+		checker.Register(name, check)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", health.Live)
+	mux.Handle("/readyz", checker)
+	server := &http.Server{
+		Addr:    ":" + strconv.Itoa(cfg.Metrics.Port),
+		Handler: mux,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Metrics server failed: %v", err)
+		}
+	}()
 
-	http.Handle("/metrics", promhttp.Handler())
-	go http.ListenAndServe(":2112", nil)
+	var apiServer *http.Server
+	if cfg.Server.Port != 0 {
+		apiMux := http.NewServeMux()
+		apiMux.Handle("POST /evaluations", handler.NewEvaluationHandler(underwriter))
+		apiServer = &http.Server{
+			Addr:    ":" + strconv.Itoa(cfg.Server.Port),
+			Handler: apiMux,
+		}
+		go func() {
+			if err := apiServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("Evaluation API failed: %v", err)
+			}
+		}()
+	}
 
-	for i := 0; i < 10; i++ {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(cfg.Simulation.Interval.Duration)
+	defer ticker.Stop()
+
+evaluations:
+	for i := 0; i < cfg.Simulation.Evaluations; i++ {
 		start := time.Now()
-		_, err := underwriter.EvaluateApplication(context.TODO(), &model.LoanApplication{ApplicantSSN: "123-45-6789"})
+		_, err := underwriter.EvaluateApplication(ctx, &model.LoanApplication{ApplicantSSN: "123-45-6789"})
 		metrics.CreditLatency.Observe(time.Since(start).Seconds())
 		if err != nil {
 			log.Println("Evaluation error:", err)
 		}
-		time.Sleep(1 * time.Second)
+
+		select {
+		case <-ctx.Done():
+			break evaluations
+		case <-ticker.C:
+		}
+	}
+
+	// With the API on, the service keeps serving after the simulation until
+	// it is stopped.
+	if apiServer != nil {
+		<-ctx.Done()
+	}
+
+	stop()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Metrics.ShutdownTimeout.Duration)
+	defer cancel()
+	if apiServer != nil {
+		if err := apiServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Evaluation API shutdown incomplete: %v", err)
+		}
+	}
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Metrics server shutdown incomplete: %v", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("Unable to flush traces: %v", err)
 	}
 }
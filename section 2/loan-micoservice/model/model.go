@@ -25,6 +25,6 @@ type CreditHistory struct {
 }
 
 type UnderwritingDecision struct {
-	Approved bool
-	Reason   string
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason"`
 }
@@ -3,6 +3,8 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+
+	"loan-microservice/model"
 )
 
 type OptimizedLoanService struct {
@@ -14,7 +16,7 @@ func NewOptimizedLoanService(db *sql.DB) *OptimizedLoanService {
 }
 
 // Optimized query with pagination and proper indexing
-func (s *OptimizedLoanService) GetApplicationsByStatus(status string, limit, offset int) ([]LoanApplication, error) {
+func (s *OptimizedLoanService) GetApplicationsByStatus(status string, limit, offset int) ([]model.LoanApplication, error) {
 	query := `
 		SELECT id, applicant_ssn_hash, amount, status, application_date, last_updated, risk_score
 		FROM loan_applications 
@@ -29,9 +31,9 @@ func (s *OptimizedLoanService) GetApplicationsByStatus(status string, limit, off
 	}
 	defer rows.Close()
 
-	var applications []LoanApplication
+	var applications []model.LoanApplication
 	for rows.Next() {
-		var app LoanApplication
+		var app model.LoanApplication
 		err := rows.Scan(
 			&app.ID,
 			&app.ApplicantSSNHash,
@@ -51,7 +53,7 @@ func (s *OptimizedLoanService) GetApplicationsByStatus(status string, limit, off
 }
 
 // Optimized applicant query using hashed SSN
-func (s *OptimizedLoanService) GetApplicantApplications(ssnHash string, limit, offset int) ([]LoanApplication, error) {
+func (s *OptimizedLoanService) GetApplicantApplications(ssnHash string, limit, offset int) ([]model.LoanApplication, error) {
 	query := `
 		SELECT id, applicant_ssn_hash, amount, status, application_date, last_updated, risk_score
 		FROM loan_applications 
@@ -66,9 +68,9 @@ func (s *OptimizedLoanService) GetApplicantApplications(ssnHash string, limit, o
 	}
 	defer rows.Close()
 
-	var applications []LoanApplication
+	var applications []model.LoanApplication
 	for rows.Next() {
-		var app LoanApplication
+		var app model.LoanApplication
 		err := rows.Scan(
 			&app.ID,
 			&app.ApplicantSSNHash,
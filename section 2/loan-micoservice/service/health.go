@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"loan-microservice/health"
+
+	"github.com/sony/gobreaker"
+)
+
+// HealthChecks reports the CreditService circuit breaker, which fails the
+// service's readiness while open, and the credit report cache. An open breaker
+// with a warm cache still answers most evaluations, but the cache alone cannot
+// serve applicants it has not seen.
+func (u *underwritingServiceImpl) HealthChecks() map[string]health.Check {
+	return map[string]health.Check{
+		"circuit_breaker": func(ctx context.Context) (map[string]any, error) {
+			state := u.breaker.State()
+			counts := u.breaker.Counts()
+			details := map[string]any{
+				"state":                state.String(),
+				"requests":             counts.Requests,
+				"consecutive_failures": counts.ConsecutiveFailures,
+			}
+			if state == gobreaker.StateOpen {
+				return details, errors.New("credit service circuit breaker is open")
+			}
+			return details, nil
+		},
+		"credit_cache": func(ctx context.Context) (map[string]any, error) {
+			entries, fresh := u.cache.Stats()
+			return map[string]any{"entries": entries, "fresh": fresh}, nil
+		},
+	}
+}
@@ -4,15 +4,22 @@ import (
 	"context"
 	"errors"
 	"loan-microservice/cache"
+	"loan-microservice/health"
 	"loan-microservice/metrics"
 	"loan-microservice/model"
+	"loan-microservice/tracing"
 	"time"
 
 	"github.com/sony/gobreaker"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type UnderwritingService interface {
 	EvaluateApplication(ctx context.Context, app *model.LoanApplication) (*model.UnderwritingDecision, error)
+	// HealthChecks returns the readiness checks of the service's dependencies.
+	HealthChecks() map[string]health.Check
 }
 
 type underwritingServiceImpl struct {
@@ -21,13 +28,17 @@ type underwritingServiceImpl struct {
 	breaker *gobreaker.CircuitBreaker
 }
 
-func NewUnderwritingService(credit CreditService) UnderwritingService {
-	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+func DefaultBreakerSettings() gobreaker.Settings {
+	return gobreaker.Settings{
 		Name:        "CreditService",
 		MaxRequests: 5,
 		Interval:    10 * time.Second,
 		Timeout:     5 * time.Second,
-	})
+	}
+}
+
+func NewUnderwritingService(credit CreditService, settings gobreaker.Settings) UnderwritingService {
+	cb := gobreaker.NewCircuitBreaker(settings)
 	return &underwritingServiceImpl{
 		credit:  credit,
 		cache:   cache.NewCreditCache(),
@@ -36,16 +47,28 @@ func NewUnderwritingService(credit CreditService) UnderwritingService {
 }
 
 func (u *underwritingServiceImpl) EvaluateApplication(ctx context.Context, app *model.LoanApplication) (*model.UnderwritingDecision, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "underwriting.EvaluateApplication")
+	defer span.End()
+
 	if report, found := u.cache.Get(app.ApplicantSSN); found {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
 		return u.assessRisk(report)
 	}
+	span.SetAttributes(
+		attribute.Bool("cache.hit", false),
+		attribute.String("circuit_breaker.state", u.breaker.State().String()),
+	)
 	result, err := u.breaker.Execute(func() (interface{}, error) {
-		return u.credit.GetCreditScore(ctx, app.ApplicantSSN)
+		return u.creditScore(ctx, app.ApplicantSSN)
 	})
 	if err != nil {
+		span.RecordError(err)
+		span.SetAttributes(attribute.String("circuit_breaker.state_after", u.breaker.State().String()))
 		if report, found := u.cache.Get(app.ApplicantSSN); found {
+			span.SetAttributes(attribute.Bool("cache.stale_fallback", true))
 			return u.assessRisk(report)
 		}
+		span.SetStatus(codes.Error, "credit service unavailable and no cache")
 		return nil, errors.New("credit service unavailable and no cache")
 	}
 	report := result.(*model.CreditReport)
@@ -53,6 +76,20 @@ func (u *underwritingServiceImpl) EvaluateApplication(ctx context.Context, app *
 	return u.assessRisk(report)
 }
 
+// creditScore calls the CreditService in a child span, so failures that trip
+// the breaker show up in the trace.
+func (u *underwritingServiceImpl) creditScore(ctx context.Context, ssn string) (*model.CreditReport, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "CreditService.GetCreditScore", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	report, err := u.credit.GetCreditScore(ctx, ssn)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return report, err
+}
+
 func (u *underwritingServiceImpl) assessRisk(report *model.CreditReport) (*model.UnderwritingDecision, error) {
 	if report.Score < 600 {
 		metrics.RejectionRate.Inc()
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"loan-microservice/config"
+)
+
+func TestLoadConfig(t *testing.T) {
+	// Test Case 1: Defaults leave the evaluation API off
+	cfg, err := config.Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cfg.Server.Port)
+	assert.Equal(t, 2112, cfg.Metrics.Port)
+	assert.Equal(t, uint32(5), cfg.Breaker.MaxRequests)
+	assert.Equal(t, 5*time.Second, cfg.Breaker.Timeout.Duration)
+
+	// Test Case 2: File, then environment, then flags
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+		"server": {"port": 8082},
+		"breaker": {"max_requests": 3, "interval": "1m", "timeout": "2s"},
+		"simulation": {"evaluations": 50, "interval": "100ms"}
+	}`), 0o600))
+	t.Setenv("UNDERWRITING_EVALUATIONS", "20")
+	t.Setenv("UNDERWRITING_BREAKER_TIMEOUT", "3s")
+
+	cfg, err = config.Load([]string{"-config", path, "-metrics-port", "9300"})
+	assert.NoError(t, err)
+	assert.Equal(t, 8082, cfg.Server.Port)
+	assert.Equal(t, 9300, cfg.Metrics.Port)
+	assert.Equal(t, uint32(3), cfg.Breaker.MaxRequests)
+	assert.Equal(t, time.Minute, cfg.Breaker.Interval.Duration)
+	assert.Equal(t, 3*time.Second, cfg.Breaker.Timeout.Duration)
+	assert.Equal(t, 20, cfg.Simulation.Evaluations)
+	assert.Equal(t, 100*time.Millisecond, cfg.Simulation.Interval.Duration)
+
+	cfg, err = config.Load([]string{"-config", path, "-evaluations", "0"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cfg.Simulation.Evaluations)
+
+	// Test Case 3: Invalid environment values name their variable
+	t.Setenv("UNDERWRITING_BREAKER_MAX_REQUESTS", "-1")
+	_, err = config.Load(nil)
+	assert.ErrorContains(t, err, "UNDERWRITING_BREAKER_MAX_REQUESTS")
+	t.Setenv("UNDERWRITING_BREAKER_MAX_REQUESTS", "5")
+	t.Setenv("UNDERWRITING_SHUTDOWN_TIMEOUT", "later")
+	_, err = config.Load(nil)
+	assert.ErrorContains(t, err, "UNDERWRITING_SHUTDOWN_TIMEOUT")
+	t.Setenv("UNDERWRITING_SHUTDOWN_TIMEOUT", "10s")
+
+	// Test Case 4: Durations are strings and unknown keys are rejected
+	assert.NoError(t, os.WriteFile(path, []byte(`{"breaker": {"timeout": 5}}`), 0o600))
+	_, err = config.Load([]string{"-config", path})
+	assert.ErrorContains(t, err, "duration must be a string")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"metrics": {"prot": 1}}`), 0o600))
+	_, err = config.Load([]string{"-config", path})
+	assert.ErrorContains(t, err, "unknown field")
+
+	// Test Case 5: The API cannot share the metrics port
+	_, err = config.Load([]string{"-port", "2112"})
+	assert.ErrorContains(t, err, "server.port")
+}
+
+func TestValidateConfig(t *testing.T) {
+	// Test Case 1: The defaults are valid
+	cfg := config.Default()
+	assert.NoError(t, cfg.Validate())
+
+	// Test Case 2: Every invalid value is reported at once
+	cfg.Metrics.Port = 0
+	cfg.Tracing.Exporter = "jaeger"
+	cfg.Breaker.MaxRequests = 0
+	cfg.Breaker.Interval.Duration = -time.Second
+	cfg.Simulation.Interval.Duration = 0
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "metrics.port")
+	assert.ErrorContains(t, err, `tracing.exporter must be one of none, stdout, otlp, got "jaeger"`)
+	assert.ErrorContains(t, err, "breaker.max_requests")
+	assert.ErrorContains(t, err, "breaker.interval")
+	assert.ErrorContains(t, err, "simulation.interval")
+
+	// Test Case 3: A zero breaker interval keeps counts until the breaker opens
+	cfg = config.Default()
+	cfg.Breaker.Interval.Duration = 0
+	assert.NoError(t, cfg.Validate())
+}
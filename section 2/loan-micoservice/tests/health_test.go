@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"loan-microservice/health"
+	"loan-microservice/model"
+	"loan-microservice/service"
+)
+
+func readiness(t *testing.T, handler http.Handler) (int, health.Report) {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var report health.Report
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	return w.Code, report
+}
+
+func TestUnderwritingHealthChecks(t *testing.T) {
+	credit := &flakyCreditService{}
+	underwriting := service.NewUnderwritingService(credit, service.DefaultBreakerSettings())
+	checker := health.NewChecker()
+	for name, check := range underwriting.HealthChecks() {
+		checker.Register(name, check)
+	}
+
+	// Test Case 1: A closed breaker is ready and the cache is reported
+	underwriting.EvaluateApplication(context.Background(), &model.LoanApplication{ApplicantSSN: "123-45-6789"})
+	code, report := readiness(t, checker)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "closed", report.Checks["circuit_breaker"].Details["state"])
+	assert.Equal(t, 1.0, report.Checks["credit_cache"].Details["entries"])
+	assert.Equal(t, 1.0, report.Checks["credit_cache"].Details["fresh"])
+
+	// Test Case 2: An open breaker makes the service unready
+	credit.down = true
+	for i := range 6 {
+		underwriting.EvaluateApplication(context.Background(), &model.LoanApplication{ApplicantSSN: fmt.Sprintf("000-00-000%d", i)})
+	}
+	code, report = readiness(t, checker)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusFailing, report.Status)
+	assert.Equal(t, "open", report.Checks["circuit_breaker"].Details["state"])
+	assert.Equal(t, "credit service circuit breaker is open", report.Checks["circuit_breaker"].Error)
+	assert.Equal(t, health.StatusOK, report.Checks["credit_cache"].Status)
+
+	// Test Case 3: Liveness checks nothing
+	w := httptest.NewRecorder()
+	health.Live(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Timeout = 20 * time.Millisecond
+	checker.Register("slow", func(ctx context.Context) (map[string]any, error) {
+		<-ctx.Done()
+		return nil, nil
+	})
+	checker.Register("fast", func(ctx context.Context) (map[string]any, error) {
+		return map[string]any{"ok": true}, nil
+	})
+
+	// Test Case 1: Checks that do not answer in time fail, the others still pass
+	report := checker.Run(context.Background())
+	assert.False(t, report.OK())
+	assert.Contains(t, report.Checks["slow"].Error, "timed out")
+	assert.Equal(t, health.StatusOK, report.Checks["fast"].Status)
+	assert.Equal(t, true, report.Checks["fast"].Details["ok"])
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"loan-microservice/model"
+	"loan-microservice/service"
+	"loan-microservice/tracing"
+)
+
+// flakyCreditService fails every call while down is set.
+type flakyCreditService struct {
+	service.CreditServiceImpl
+	down bool
+}
+
+func (f *flakyCreditService) GetCreditScore(ctx context.Context, ssn string) (*model.CreditReport, error) {
+	if f.down {
+		return nil, errors.New("credit bureau timeout")
+	}
+	return f.CreditServiceImpl.GetCreditScore(ctx, ssn)
+}
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[string]string {
+	attributes := map[string]string{}
+	for _, attr := range span.Attributes() {
+		attributes[string(attr.Key)] = attr.Value.Emit()
+	}
+	return attributes
+}
+
+func TestEvaluationTracing(t *testing.T) {
+	recorder := recordSpans(t)
+	credit := &flakyCreditService{}
+	underwriting := service.NewUnderwritingService(credit, service.DefaultBreakerSettings())
+	app := &model.LoanApplication{ID: "1", ApplicantSSN: "123-45-6789", LoanAmount: 20000}
+
+	// Test Case 1: A cache miss calls the credit service in a child span of the caller's trace
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	_, err := underwriting.EvaluateApplication(trace.ContextWithRemoteSpanContext(context.Background(), parent), app)
+	assert.NoError(t, err)
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		creditSpan, evaluationSpan := spans[0], spans[1]
+		assert.Equal(t, "underwriting.EvaluateApplication", evaluationSpan.Name())
+		assert.Equal(t, parent.TraceID(), evaluationSpan.SpanContext().TraceID())
+		assert.Equal(t, parent.SpanID(), evaluationSpan.Parent().SpanID())
+		assert.Equal(t, "false", spanAttributes(evaluationSpan)["cache.hit"])
+		assert.Equal(t, "closed", spanAttributes(evaluationSpan)["circuit_breaker.state"])
+
+		assert.Equal(t, "CreditService.GetCreditScore", creditSpan.Name())
+		assert.Equal(t, trace.SpanKindClient, creditSpan.SpanKind())
+		assert.Equal(t, evaluationSpan.SpanContext().SpanID(), creditSpan.Parent().SpanID())
+	}
+
+	// Test Case 2: A cache hit records no credit service span
+	_, err = underwriting.EvaluateApplication(context.Background(), app)
+	assert.NoError(t, err)
+	spans = recorder.Ended()[2:]
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "true", spanAttributes(spans[0])["cache.hit"])
+		assert.False(t, spans[0].Parent().IsValid())
+	}
+
+	// Test Case 3: Credit service failures are recorded on both spans
+	credit.down = true
+	_, err = underwriting.EvaluateApplication(context.Background(), &model.LoanApplication{ID: "2", ApplicantSSN: "987-65-4321"})
+	assert.Error(t, err)
+	spans = recorder.Ended()[3:]
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Equal(t, "credit bureau timeout", spans[0].Status().Description)
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+		assert.Equal(t, "closed", spanAttributes(spans[1])["circuit_breaker.state_after"])
+	}
+}
+
+func TestTracingSetup(t *testing.T) {
+	recordSpans(t)
+
+	// Test Case 1: none installs nothing
+	provider := otel.GetTracerProvider()
+	shutdown, err := tracing.Setup(context.Background(), tracing.ExporterNone, "")
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+	assert.Equal(t, provider, otel.GetTracerProvider())
+
+	// Test Case 2: stdout installs a provider that can be shut down
+	shutdown, err = tracing.Setup(context.Background(), tracing.ExporterStdout, "")
+	assert.NoError(t, err)
+	assert.NotEqual(t, provider, otel.GetTracerProvider())
+	assert.NoError(t, shutdown(context.Background()))
+
+	// Test Case 3: Unknown exporters are rejected
+	_, err = tracing.Setup(context.Background(), "jaeger", "")
+	assert.EqualError(t, err, `unknown trace exporter "jaeger"`)
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"loan-doc-processor/tracing"
+)
+
+// Config is the runtime configuration of the document processor. Values are
+// resolved in order of increasing precedence: defaults, the JSON file given by
+// -config (or DOC_PROCESSOR_CONFIG), DOC_PROCESSOR_* environment variables and flags.
+type Config struct {
+	Server  ServerConfig  `json:"server"`
+	Workers WorkersConfig `json:"workers"`
+	Uploads UploadsConfig `json:"uploads"`
+	Tracing TracingConfig `json:"tracing"`
+}
+
+type ServerConfig struct {
+	Port            int      `json:"port"`
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+}
+
+type WorkersConfig struct {
+	Count     int `json:"count"`
+	QueueSize int `json:"queue_size"`
+}
+
+type UploadsConfig struct {
+	Dir string `json:"dir"`
+}
+
+// TracingConfig selects where spans go: "otlp" posts them to Endpoint (or the
+// OTEL_EXPORTER_OTLP_* location), "stdout" prints them for local runs and
+// "none" drops them.
+type TracingConfig struct {
+	Exporter string `json:"exporter"`
+	Endpoint string `json:"endpoint"`
+}
+
+// Duration is a time.Duration written as a string such as "5s" in JSON.
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"5s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			// 8080 is taken by loan-api when both run on one host.
+			Port:            8081,
+			ShutdownTimeout: Duration{30 * time.Second},
+		},
+		Workers: WorkersConfig{Count: 3, QueueSize: 100},
+		Uploads: UploadsConfig{Dir: "./uploads"},
+		Tracing: TracingConfig{Exporter: tracing.ExporterNone},
+	}
+}
+
+// Load builds the configuration from the command line arguments (without the
+// program name) and the process environment, and validates the result.
+func Load(args []string) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("loan-doc-processor", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("DOC_PROCESSOR_CONFIG"), "path to a JSON configuration file")
+	port := fs.Int("port", 0, "HTTP listen port")
+	workers := fs.Int("workers", 0, "number of document workers")
+	uploadDir := fs.String("upload-dir", "", "directory for uploaded documents")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 0, "time allowed to drain requests and jobs on shutdown")
+	traceExporter := fs.String("trace-exporter", "", "trace exporter: "+strings.Join(tracing.Exporters, ", "))
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if *path != "" {
+		if err := cfg.loadFile(*path); err != nil {
+			return cfg, err
+		}
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return cfg, err
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "workers":
+			cfg.Workers.Count = *workers
+		case "upload-dir":
+			cfg.Uploads.Dir = *uploadDir
+		case "shutdown-timeout":
+			cfg.Server.ShutdownTimeout = Duration{*shutdownTimeout}
+		case "trace-exporter":
+			cfg.Tracing.Exporter = *traceExporter
+		}
+	})
+
+	return cfg, cfg.Validate()
+}
+
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	ints := map[string]*int{
+		"DOC_PROCESSOR_PORT":       &c.Server.Port,
+		"DOC_PROCESSOR_WORKERS":    &c.Workers.Count,
+		"DOC_PROCESSOR_QUEUE_SIZE": &c.Workers.QueueSize,
+	}
+	for name, target := range ints {
+		if v, ok := lookup(name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			*target = n
+		}
+	}
+	if v, ok := lookup("DOC_PROCESSOR_SHUTDOWN_TIMEOUT"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("DOC_PROCESSOR_SHUTDOWN_TIMEOUT: %w", err)
+		}
+		c.Server.ShutdownTimeout = Duration{d}
+	}
+	if v, ok := lookup("DOC_PROCESSOR_UPLOAD_DIR"); ok {
+		c.Uploads.Dir = v
+	}
+	if v, ok := lookup("DOC_PROCESSOR_TRACE_EXPORTER"); ok {
+		c.Tracing.Exporter = v
+	}
+	if v, ok := lookup("DOC_PROCESSOR_TRACE_ENDPOINT"); ok {
+		c.Tracing.Endpoint = v
+	}
+	return nil
+}
+
+func (c Config) Validate() error {
+	var errs []error
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port must be between 1 and 65535, got %d", c.Server.Port))
+	}
+	if c.Server.ShutdownTimeout.Duration <= 0 {
+		errs = append(errs, errors.New("server.shutdown_timeout must be positive"))
+	}
+	if c.Workers.Count < 1 {
+		errs = append(errs, errors.New("workers.count must be at least 1"))
+	}
+	if c.Workers.QueueSize < 0 {
+		errs = append(errs, errors.New("workers.queue_size must not be negative"))
+	}
+	if c.Uploads.Dir == "" {
+		errs = append(errs, errors.New("uploads.dir is required"))
+	}
+	if !slices.Contains(tracing.Exporters, c.Tracing.Exporter) {
+		errs = append(errs, fmt.Errorf("tracing.exporter must be one of %s, got %q", strings.Join(tracing.Exporters, ", "), c.Tracing.Exporter))
+	}
+	return errors.Join(errs...)
+}
@@ -7,11 +7,21 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 	"loan-doc-processor/model"
-	"loan-doc-processor/queue"
 )
 
-func UploadHandler(c *gin.Context) {
+type DocumentHandler struct {
+	UploadDir string
+	Jobs      chan<- model.DocumentJob
+}
+
+func NewDocumentHandler(uploadDir string, jobs chan<- model.DocumentJob) *DocumentHandler {
+	return &DocumentHandler{UploadDir: uploadDir, Jobs: jobs}
+}
+
+func (h *DocumentHandler) UploadHandler(c *gin.Context) {
 	id := c.Param("id")
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -20,7 +30,7 @@ func UploadHandler(c *gin.Context) {
 	}
 
 	docType := c.PostForm("document_type")
-	dst := filepath.Join("./uploads", fmt.Sprintf("%d_%s", time.Now().UnixNano(), file.Filename))
+	dst := filepath.Join(h.UploadDir, fmt.Sprintf("%d_%s", time.Now().UnixNano(), file.Filename))
 
 	if err := c.SaveUploadedFile(file, dst); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to save file"})
@@ -35,8 +45,10 @@ func UploadHandler(c *gin.Context) {
 		Callback: func(result model.ProcessingResult) {
 			fmt.Printf("[Callback] Job finished: %+v\n", result)
 		},
+		TraceContext: map[string]string{},
 	}
+	otel.GetTextMapPropagator().Inject(c.Request.Context(), propagation.MapCarrier(job.TraceContext))
 
-	queue.JobChannel <- job
+	h.Jobs <- job
 	c.JSON(http.StatusOK, gin.H{"status": "queued", "file": file.Filename})
 }
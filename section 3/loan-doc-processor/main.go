@@ -1,19 +1,98 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"loan-doc-processor/config"
 	"loan-doc-processor/handler"
+	"loan-doc-processor/health"
 	"loan-doc-processor/processor"
 	"loan-doc-processor/queue"
+	"loan-doc-processor/tracing"
 )
 
 func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if err := os.MkdirAll(cfg.Uploads.Dir, 0o750); err != nil {
+		log.Fatalf("Unable to create upload directory: %v", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.Endpoint)
+	if err != nil {
+		log.Fatalf("Unable to set up tracing: %v", err)
+	}
+
 	r := gin.Default()
+	r.Use(otelgin.Middleware(tracing.ServiceName))
+
+	jobs := queue.NewJobQueue(cfg.Workers.QueueSize)
 
 	// Start async workers
-	go processor.NewProcessor(3, queue.JobChannel).Start()
+	docProcessor := processor.NewProcessor(cfg.Workers.Count, jobs)
+	docProcessor.Start()
+
+	checker := health.NewChecker()
+	checker.Register("workers", docProcessor.WorkersCheck)
+	checker.Register("queue", docProcessor.QueueCheck)
+	checker.Register("uploads", health.Writable(cfg.Uploads.Dir))
+	r.GET("/healthz", gin.WrapF(health.Live))
+	r.GET("/readyz", gin.WrapH(checker))
+
+	documentHandler := handler.NewDocumentHandler(cfg.Uploads.Dir, jobs)
+	r.POST("/loan-applications/:id/documents", documentHandler.UploadHandler)
+
+	server := &http.Server{
+		Addr:    ":" + strconv.Itoa(cfg.Server.Port),
+		Handler: r,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Printf("Document processor listening on %s with %d workers", server.Addr, cfg.Workers.Count)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed to start: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Printf("Shutting down, draining requests and jobs for up to %s", cfg.Server.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
+	defer cancel()
 
-	r.POST("/loan-applications/:id/documents", handler.UploadHandler)
+	// Stop accepting uploads before draining the queue so no job is enqueued
+	// after the workers have exited.
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown incomplete: %v", err)
+	}
 
-	r.Run(":8080")
+	stopped := make(chan struct{})
+	go func() {
+		docProcessor.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		log.Println("All document jobs finished")
+	case <-shutdownCtx.Done():
+		log.Printf("Shutdown timeout reached with %d jobs still queued", len(jobs))
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("Unable to flush traces: %v", err)
+	}
 }
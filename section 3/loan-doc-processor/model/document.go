@@ -6,6 +6,9 @@ type DocumentJob struct {
 	FilePath      string
 	Priority      int
 	Callback      func(result ProcessingResult)
+	// TraceContext carries the W3C trace context (traceparent, tracestate) of
+	// the upload request, so the worker's span can link back to it.
+	TraceContext map[string]string
 }
 
 type ProcessingResult struct {
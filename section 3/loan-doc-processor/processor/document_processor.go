@@ -1,15 +1,28 @@
 package processor
 
 import (
+	"context"
 	"fmt"
 	"loan-doc-processor/model"
+	"loan-doc-processor/tracing"
 	"loan-doc-processor/utils"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type DocumentProcessor struct {
 	WorkerCount int
 	Queue       chan model.DocumentJob
 	Quit        chan bool
+	wg          sync.WaitGroup
+	alive       atomic.Int32
+	busy        atomic.Int32
 }
 
 func NewProcessor(workerCount int, queue chan model.DocumentJob) *DocumentProcessor {
@@ -22,14 +35,17 @@ func NewProcessor(workerCount int, queue chan model.DocumentJob) *DocumentProces
 
 func (p *DocumentProcessor) Start() {
 	for i := 0; i < p.WorkerCount; i++ {
+		p.wg.Add(1)
+		p.alive.Add(1)
 		go func(workerID int) {
+			defer p.wg.Done()
+			defer p.alive.Add(-1)
 			for {
 				select {
 				case job := <-p.Queue:
-					fmt.Printf("[Worker %d] Processing %s\n", workerID, job.FilePath)
-					result := ProcessDocument(job)
-					job.Callback(result)
+					p.handle(workerID, job)
 				case <-p.Quit:
+					p.drain(workerID)
 					fmt.Printf("[Worker %d] Shutting down...\n", workerID)
 					return
 				}
@@ -38,8 +54,52 @@ func (p *DocumentProcessor) Start() {
 	}
 }
 
+// Stop signals the workers to finish and blocks until every in-flight and
+// already queued job has been processed. Producers must stop enqueueing first.
 func (p *DocumentProcessor) Stop() {
 	close(p.Quit)
+	p.wg.Wait()
+}
+
+func (p *DocumentProcessor) drain(workerID int) {
+	for {
+		select {
+		case job := <-p.Queue:
+			p.handle(workerID, job)
+		default:
+			return
+		}
+	}
+}
+
+// handle processes a job in a span of its own trace, linked to the upload
+// request that queued it. Jobs may run long after that request has finished,
+// so the span is not made its child.
+func (p *DocumentProcessor) handle(workerID int, job model.DocumentJob) {
+	upload := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(job.TraceContext))
+	_, span := tracing.Tracer().Start(context.Background(), "document.process",
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithLinks(trace.LinkFromContext(upload)),
+		trace.WithAttributes(
+			attribute.String("loan.application_id", job.ApplicationID),
+			attribute.String("document.type", job.DocumentType),
+			attribute.Int("worker.id", workerID),
+		),
+	)
+	defer span.End()
+
+	p.busy.Add(1)
+	defer p.busy.Add(-1)
+
+	fmt.Printf("[Worker %d] Processing %s\n", workerID, job.FilePath)
+	result := ProcessDocument(job)
+	span.SetAttributes(attribute.String("document.status", result.Status))
+	if result.Error != nil {
+		span.RecordError(result.Error)
+		span.SetStatus(codes.Error, result.Error.Error())
+	}
+	job.Callback(result)
 }
 
 func ProcessDocument(job model.DocumentJob) model.ProcessingResult {
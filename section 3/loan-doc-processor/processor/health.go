@@ -0,0 +1,34 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// WorkersCheck fails unless every worker is still running. A worker that
+// panicked or exited early would otherwise leave jobs queued forever.
+func (p *DocumentProcessor) WorkersCheck(ctx context.Context) (map[string]any, error) {
+	alive := int(p.alive.Load())
+	details := map[string]any{"count": p.WorkerCount, "alive": alive, "busy": int(p.busy.Load())}
+	if alive < p.WorkerCount {
+		return details, fmt.Errorf("%d of %d workers are running", alive, p.WorkerCount)
+	}
+	return details, nil
+}
+
+// QueueCheck fails once the queue is full, because uploads would then block
+// until a worker frees a slot. An unbuffered queue is full while every worker
+// is busy.
+func (p *DocumentProcessor) QueueCheck(ctx context.Context) (map[string]any, error) {
+	depth, capacity := len(p.Queue), cap(p.Queue)
+	details := map[string]any{"depth": depth, "capacity": capacity}
+	full := depth >= capacity
+	if capacity == 0 {
+		full = p.busy.Load() >= p.alive.Load()
+	}
+	if full {
+		return details, errors.New("job queue is full")
+	}
+	return details, nil
+}
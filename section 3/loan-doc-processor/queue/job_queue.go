@@ -2,4 +2,6 @@ package queue
 
 import "loan-doc-processor/model"
 
-var JobChannel = make(chan model.DocumentJob, 100)
+func NewJobQueue(size int) chan model.DocumentJob {
+	return make(chan model.DocumentJob, size)
+}
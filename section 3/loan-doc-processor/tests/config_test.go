@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"loan-doc-processor/config"
+)
+
+func TestLoadConfig(t *testing.T) {
+	// Test Case 1: Defaults
+	cfg, err := config.Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 8081, cfg.Server.Port)
+	assert.Equal(t, 3, cfg.Workers.Count)
+	assert.Equal(t, 30*time.Second, cfg.Server.ShutdownTimeout.Duration)
+
+	// Test Case 2: File, then environment, then flags
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+		"server": {"port": 9000, "shutdown_timeout": "1m"},
+		"workers": {"count": 8, "queue_size": 10},
+		"uploads": {"dir": "/var/lib/docs"}
+	}`), 0o600))
+	t.Setenv("DOC_PROCESSOR_WORKERS", "4")
+	t.Setenv("DOC_PROCESSOR_UPLOAD_DIR", "/srv/docs")
+
+	cfg, err = config.Load([]string{"-config", path, "-upload-dir", "/tmp/docs"})
+	assert.NoError(t, err)
+	assert.Equal(t, 9000, cfg.Server.Port)
+	assert.Equal(t, time.Minute, cfg.Server.ShutdownTimeout.Duration)
+	assert.Equal(t, 4, cfg.Workers.Count)
+	assert.Equal(t, 10, cfg.Workers.QueueSize)
+	assert.Equal(t, "/tmp/docs", cfg.Uploads.Dir)
+
+	cfg, err = config.Load([]string{"-config", path, "-workers", "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cfg.Workers.Count)
+	assert.Equal(t, "/srv/docs", cfg.Uploads.Dir)
+
+	// Test Case 3: Invalid environment values name their variable
+	t.Setenv("DOC_PROCESSOR_QUEUE_SIZE", "many")
+	_, err = config.Load(nil)
+	assert.ErrorContains(t, err, "DOC_PROCESSOR_QUEUE_SIZE")
+	t.Setenv("DOC_PROCESSOR_QUEUE_SIZE", "100")
+	t.Setenv("DOC_PROCESSOR_SHUTDOWN_TIMEOUT", "soon")
+	_, err = config.Load(nil)
+	assert.ErrorContains(t, err, "DOC_PROCESSOR_SHUTDOWN_TIMEOUT")
+	t.Setenv("DOC_PROCESSOR_SHUTDOWN_TIMEOUT", "30s")
+
+	// Test Case 4: Durations are strings and unknown keys are rejected
+	assert.NoError(t, os.WriteFile(path, []byte(`{"server": {"shutdown_timeout": 30}}`), 0o600))
+	_, err = config.Load([]string{"-config", path})
+	assert.ErrorContains(t, err, "duration must be a string")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"workers": {"cnt": 1}}`), 0o600))
+	_, err = config.Load([]string{"-config", path})
+	assert.ErrorContains(t, err, "unknown field")
+
+	// Test Case 5: Every invalid value is reported at once
+	_, err = config.Load([]string{"-port", "70000", "-workers", "0", "-upload-dir", "", "-shutdown-timeout", "0s", "-trace-exporter", "jaeger"})
+	assert.ErrorContains(t, err, "server.port")
+	assert.ErrorContains(t, err, "workers.count")
+	assert.ErrorContains(t, err, "uploads.dir")
+	assert.ErrorContains(t, err, "server.shutdown_timeout")
+	assert.ErrorContains(t, err, `tracing.exporter must be one of none, stdout, otlp, got "jaeger"`)
+}
+
+func TestValidateConfig(t *testing.T) {
+	// Test Case 1: The defaults are valid
+	cfg := config.Default()
+	assert.NoError(t, cfg.Validate())
+
+	// Test Case 2: A negative queue is invalid, an unbuffered one is not
+	cfg.Workers.QueueSize = -1
+	assert.ErrorContains(t, cfg.Validate(), "workers.queue_size")
+	cfg.Workers.QueueSize = 0
+	assert.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"loan-doc-processor/health"
+	"loan-doc-processor/model"
+	"loan-doc-processor/processor"
+)
+
+func readiness(t *testing.T, handler http.Handler) (int, health.Report) {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var report health.Report
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	return w.Code, report
+}
+
+func TestProcessorHealthChecks(t *testing.T) {
+	jobs := make(chan model.DocumentJob, 1)
+	docProcessor := processor.NewProcessor(2, jobs)
+	checker := health.NewChecker()
+	checker.Register("workers", docProcessor.WorkersCheck)
+	checker.Register("queue", docProcessor.QueueCheck)
+	checker.Register("uploads", health.Writable(t.TempDir()))
+
+	// Test Case 1: Workers that are not running fail the check
+	code, report := readiness(t, checker)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "0 of 2 workers are running", report.Checks["workers"].Error)
+
+	// Test Case 2: A full queue fails the check
+	jobs <- model.DocumentJob{FilePath: "missing.pdf", Callback: func(model.ProcessingResult) {}}
+	_, report = readiness(t, checker)
+	assert.Equal(t, "job queue is full", report.Checks["queue"].Error)
+	assert.Equal(t, 1.0, report.Checks["queue"].Details["depth"])
+
+	// Test Case 3: Running workers drain the queue and the service is ready
+	docProcessor.Start()
+	defer docProcessor.Stop()
+	assert.Eventually(t, func() bool { return len(jobs) == 0 }, time.Second, 5*time.Millisecond)
+	code, report = readiness(t, checker)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2.0, report.Checks["workers"].Details["alive"])
+	assert.Equal(t, health.StatusOK, report.Checks["uploads"].Status)
+
+	// Test Case 4: An upload directory that cannot be written fails
+	checker.Register("uploads", health.Writable(filepath.Join(t.TempDir(), "missing")))
+	code, report = readiness(t, checker)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, report.Checks["uploads"].Error, "not writable")
+
+	// Test Case 5: Liveness checks nothing
+	w := httptest.NewRecorder()
+	health.Live(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Timeout = 20 * time.Millisecond
+	checker.Register("slow", func(ctx context.Context) (map[string]any, error) {
+		<-ctx.Done()
+		return nil, nil
+	})
+
+	// Test Case 1: Checks that do not answer in time fail
+	report := checker.Run(context.Background())
+	assert.False(t, report.OK())
+	assert.Contains(t, report.Checks["slow"].Error, "timed out")
+}
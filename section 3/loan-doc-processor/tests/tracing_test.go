@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"loan-doc-processor/handler"
+	"loan-doc-processor/model"
+	"loan-doc-processor/processor"
+	"loan-doc-processor/tracing"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func TestDocumentTracing(t *testing.T) {
+	recorder := recordSpans(t)
+	jobs := make(chan model.DocumentJob, 1)
+	docProcessor := processor.NewProcessor(1, jobs)
+	docProcessor.Start()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(otelgin.Middleware(tracing.ServiceName))
+	r.POST("/loan-applications/:id/documents", handler.NewDocumentHandler(t.TempDir(), jobs).UploadHandler)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("document_type", "pay_stub")
+	part, _ := form.CreateFormFile("file", "payslip.pdf")
+	part.Write([]byte("not a pdf"))
+	form.Close()
+	req, _ := http.NewRequest(http.MethodPost, "/loan-applications/42/documents", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	docProcessor.Stop()
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	uploadSpan, processSpan := spans[0], spans[1]
+
+	// Test Case 1: The upload joins the caller's trace
+	assert.Equal(t, "/loan-applications/:id/documents", uploadSpan.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", uploadSpan.SpanContext().TraceID().String())
+
+	// Test Case 2: Processing runs in its own trace, linked to the upload
+	assert.Equal(t, "document.process", processSpan.Name())
+	assert.Equal(t, trace.SpanKindConsumer, processSpan.SpanKind())
+	assert.False(t, processSpan.Parent().IsValid())
+	assert.NotEqual(t, uploadSpan.SpanContext().TraceID(), processSpan.SpanContext().TraceID())
+	if assert.Len(t, processSpan.Links(), 1) {
+		assert.Equal(t, uploadSpan.SpanContext().SpanID(), processSpan.Links()[0].SpanContext.SpanID())
+	}
+
+	// Test Case 3: The job is described and its failure recorded
+	attributes := map[string]string{}
+	for _, attr := range processSpan.Attributes() {
+		attributes[string(attr.Key)] = attr.Value.Emit()
+	}
+	assert.Equal(t, "42", attributes["loan.application_id"])
+	assert.Equal(t, "pay_stub", attributes["document.type"])
+	assert.Equal(t, "failed", attributes["document.status"])
+	assert.Equal(t, codes.Error, processSpan.Status().Code)
+}
+
+func TestTracingSetup(t *testing.T) {
+	recordSpans(t)
+
+	// Test Case 1: none installs nothing
+	provider := otel.GetTracerProvider()
+	shutdown, err := tracing.Setup(context.Background(), tracing.ExporterNone, "")
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+	assert.Equal(t, provider, otel.GetTracerProvider())
+
+	// Test Case 2: stdout installs a provider that can be shut down
+	shutdown, err = tracing.Setup(context.Background(), tracing.ExporterStdout, "")
+	assert.NoError(t, err)
+	assert.NotEqual(t, provider, otel.GetTracerProvider())
+	assert.NoError(t, shutdown(context.Background()))
+
+	// Test Case 3: Unknown exporters are rejected
+	_, err = tracing.Setup(context.Background(), "jaeger", "")
+	assert.EqualError(t, err, `unknown trace exporter "jaeger"`)
+}